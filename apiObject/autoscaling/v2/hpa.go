@@ -0,0 +1,120 @@
+// Package v2 is the autoscaling/v2 version of the HorizontalPodAutoscaler,
+// which lists the metrics it scales on instead of having a field for each.
+package v2
+
+import (
+	"fmt"
+	"minik8s/apiObject"
+)
+
+// GroupVersion is the apiVersion of the objects of this version
+const GroupVersion = "autoscaling/v2"
+
+const (
+	// ResourceMetricSourceType is a metric of the resources of the pods
+	ResourceMetricSourceType = "Resource"
+	// UtilizationMetricType targets the average usage of the pods, as a
+	// percentage of what they request.
+	UtilizationMetricType = "Utilization"
+
+	ResourceCPU    = "cpu"
+	ResourceMemory = "memory"
+)
+
+type MetricTarget struct {
+	Type               string  `yaml:"type" schema:"enum=Utilization"`
+	AverageUtilization float64 `yaml:"averageUtilization"`
+}
+
+type ResourceMetricSource struct {
+	Name   string       `yaml:"name" schema:"required,enum=cpu|memory"`
+	Target MetricTarget `yaml:"target"`
+}
+
+type MetricSpec struct {
+	Type     string               `yaml:"type" schema:"enum=Resource"`
+	Resource ResourceMetricSource `yaml:"resource"`
+}
+
+type HPASpec struct {
+	MinReplicas    int                      `yaml:"minReplicas"`
+	MaxReplicas    int                      `yaml:"maxReplicas"`
+	ScaleTargetRef apiObject.ScaleTargetRef `yaml:"scaleTargetRef"`
+	Metrics        []MetricSpec             `yaml:"metrics"`
+	ScaleInterval  int                      `yaml:"scaleInterval,omitempty"`
+}
+
+type HorizontalPodAutoscaler struct {
+	apiObject.Base `yaml:",inline"`
+	Spec           HPASpec `yaml:"spec"`
+}
+
+// ConvertTo sets hub, an apiObject.HorizontalPodAutoscaler. There is one
+// utilization target per resource at most, the hub has no other metrics.
+func (hpa *HorizontalPodAutoscaler) ConvertTo(hub apiObject.Object) error {
+	dst := hub.(*apiObject.HorizontalPodAutoscaler)
+	dst.Base = hpa.Base
+	dst.Spec = apiObject.HPASpec{
+		MinReplicas:    hpa.Spec.MinReplicas,
+		MaxReplicas:    hpa.Spec.MaxReplicas,
+		ScaleTargetRef: hpa.Spec.ScaleTargetRef,
+		ScaleInterval:  hpa.Spec.ScaleInterval,
+	}
+	seen := make(map[string]bool)
+	for i, metric := range hpa.Spec.Metrics {
+		if metric.Type != "" && metric.Type != ResourceMetricSourceType {
+			return fmt.Errorf("spec.metrics[%d]: only %s metrics are supported", i, ResourceMetricSourceType)
+		}
+		if target := metric.Resource.Target.Type; target != "" && target != UtilizationMetricType {
+			return fmt.Errorf("spec.metrics[%d]: only %s targets are supported", i, UtilizationMetricType)
+		}
+		name := metric.Resource.Name
+		if seen[name] {
+			return fmt.Errorf("spec.metrics[%d]: duplicate %s metric", i, name)
+		}
+		seen[name] = true
+		switch name {
+		case ResourceCPU:
+			dst.Spec.Metrics.CPUUtilizationPercentage = metric.Resource.Target.AverageUtilization
+		case ResourceMemory:
+			dst.Spec.Metrics.MemUtilizationPercentage = metric.Resource.Target.AverageUtilization
+		default:
+			return fmt.Errorf("spec.metrics[%d]: unknown resource %q, it must be %s or %s", i, name, ResourceCPU, ResourceMemory)
+		}
+	}
+	return nil
+}
+
+// ConvertFrom sets the autoscaler from hub, an apiObject.HorizontalPodAutoscaler,
+// a percentage of 0 meaning there is no target for the resource.
+func (hpa *HorizontalPodAutoscaler) ConvertFrom(hub apiObject.Object) error {
+	src := hub.(*apiObject.HorizontalPodAutoscaler)
+	hpa.Base = src.Base
+	hpa.ApiVersion = GroupVersion
+	hpa.Kind = "HorizontalPodAutoscaler"
+	hpa.Spec = HPASpec{
+		MinReplicas:    src.Spec.MinReplicas,
+		MaxReplicas:    src.Spec.MaxReplicas,
+		ScaleTargetRef: src.Spec.ScaleTargetRef,
+		ScaleInterval:  src.Spec.ScaleInterval,
+	}
+	for _, resource := range []struct {
+		name       string
+		percentage float64
+	}{
+		{ResourceCPU, src.Spec.Metrics.CPUUtilizationPercentage},
+		{ResourceMemory, src.Spec.Metrics.MemUtilizationPercentage},
+	} {
+		if resource.percentage == 0 {
+			continue
+		}
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, MetricSpec{
+			Type: ResourceMetricSourceType,
+			Resource: ResourceMetricSource{
+				Name:   resource.name,
+				Target: MetricTarget{Type: UtilizationMetricType, AverageUtilization: resource.percentage},
+			},
+		})
+	}
+	return nil
+}
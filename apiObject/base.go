@@ -1,11 +1,30 @@
 package apiObject
 
+import "time"
+
+// LastAppliedConfigAnnotation holds the configuration kubectl apply last
+// applied to an object, as JSON. The next apply removes the fields that were
+// in it but are no longer in the new configuration.
+const LastAppliedConfigAnnotation = "io.minik8s.last-applied-configuration"
+
 type Metadata struct {
 	Name        string      `yaml:"name"`
 	Namespace   string      `yaml:"namespace"`
 	UID         string      `yaml:"uid"`
 	Labels      Labels      `yaml:"labels" `
 	Annotations Annotations `yaml:"annotations"`
+	// ResourceVersion is the etcd mod revision of the stored object. It is
+	// filled in by the api-server on read and checked on update, so a client
+	// that writes back a stale copy gets a 409 Conflict instead of silently
+	// overwriting someone else's change.
+	ResourceVersion string `yaml:"resourceVersion,omitempty"`
+	// OwnerReferences name the objects this one depends on, the garbage
+	// collector deletes it once none of them exists any more.
+	OwnerReferences []OwnerReference `yaml:"ownerReferences,omitempty"`
+	// DeletionTimestamp is set when the object is being deleted but waits for
+	// its Finalizers, e.g. for its dependents to be deleted first.
+	DeletionTimestamp *time.Time `yaml:"deletionTimestamp,omitempty"`
+	Finalizers        []string   `yaml:"finalizers,omitempty"`
 }
 
 type Base struct {
@@ -13,3 +32,33 @@ type Base struct {
 	Kind       string   `yaml:"kind"`
 	Metadata   Metadata `yaml:"metadata"`
 }
+
+// Object is implemented by every api object that embeds Base.
+type Object interface {
+	Meta() *Metadata
+	GetApiVersion() string
+	SetApiVersion(apiVersion string)
+}
+
+func (base *Base) Meta() *Metadata {
+	return &base.Metadata
+}
+
+func (base *Base) GetApiVersion() string {
+	return base.ApiVersion
+}
+
+func (base *Base) SetApiVersion(apiVersion string) {
+	base.ApiVersion = apiVersion
+}
+
+// OwnerReference returns a reference to the object, for its dependents
+func (base *Base) OwnerReference() OwnerReference {
+	return OwnerReference{
+		ApiVersion:         base.ApiVersion,
+		Kind:               base.Kind,
+		Name:               base.Metadata.Name,
+		UID:                base.Metadata.UID,
+		BlockOwnerDeletion: true,
+	}
+}
@@ -0,0 +1,55 @@
+package apiObject
+
+import "time"
+
+// CertificateSigningRequest asks the cluster CA for a client certificate,
+// e.g. by a kubelet joining the cluster. The api-server signs it once the
+// admin approves it.
+type CertificateSigningRequest struct {
+	Base   `yaml:",inline"`
+	Spec   CertificateSigningRequestSpec   `yaml:"spec"`
+	Status CertificateSigningRequestStatus `yaml:"status"`
+}
+
+type CertificateSigningRequestSpec struct {
+	// Request is the PEM encoded x509 certificate request
+	Request string `yaml:"request"`
+	// Username and Groups are who created the request, set by the api-server
+	Username string   `yaml:"username"`
+	Groups   []string `yaml:"groups"`
+	// CommonName and Organization are the user and groups the certificate is
+	// requested for, set by the api-server from Request.
+	CommonName   string   `yaml:"commonName"`
+	Organization []string `yaml:"organization"`
+}
+
+const (
+	CertificateApproved = "Approved"
+	CertificateDenied   = "Denied"
+)
+
+type CertificateSigningRequestCondition struct {
+	// Type is Approved or Denied
+	Type           string    `yaml:"type"`
+	Reason         string    `yaml:"reason"`
+	Message        string    `yaml:"message"`
+	LastUpdateTime time.Time `yaml:"lastUpdateTime"`
+}
+
+type CertificateSigningRequestStatus struct {
+	Conditions []CertificateSigningRequestCondition `yaml:"conditions"`
+	// Certificate is the PEM encoded certificate, once the request is approved
+	Certificate string `yaml:"certificate"`
+}
+
+func (csr *CertificateSigningRequest) Name() string {
+	return csr.Metadata.Name
+}
+
+// Condition is the type of the last condition, empty while pending
+func (csr *CertificateSigningRequest) Condition() string {
+	if n := len(csr.Status.Conditions); n > 0 {
+		return csr.Status.Conditions[n-1].Type
+	}
+	return ""
+}
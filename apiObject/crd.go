@@ -0,0 +1,81 @@
+package apiObject
+
+import (
+	"encoding/json"
+)
+
+const (
+	NamespacedScope = "Namespaced"
+	ClusterScope    = "Cluster"
+)
+
+// CustomResourceDefinition registers a new kind at runtime. Its objects are
+// served under /apis/group/version/plural/ and validated against Schema.
+type CustomResourceDefinition struct {
+	Base `yaml:",inline"`
+	Spec CRDSpec `yaml:"spec"`
+}
+
+type CRDSpec struct {
+	Group   string   `yaml:"group"`
+	Version string   `yaml:"version"`
+	Scope   string   `yaml:"scope"`
+	Names   CRDNames `yaml:"names"`
+	// Schema is the JSON schema of the object without apiVersion, kind and
+	// metadata, e.g. {type: object, properties: {spec: {...}}}
+	Schema map[string]interface{} `yaml:"schema,omitempty"`
+}
+
+type CRDNames struct {
+	Kind     string `yaml:"kind"`
+	Plural   string `yaml:"plural"`
+	Singular string `yaml:"singular,omitempty"`
+}
+
+func (crd *CustomResourceDefinition) Name() string {
+	return crd.Metadata.Name
+}
+
+func (crd *CustomResourceDefinition) Namespaced() bool {
+	return crd.Spec.Scope != ClusterScope
+}
+
+// GroupVersion returns the apiVersion of the custom objects, e.g. example.com/v1
+func (crd *CustomResourceDefinition) GroupVersion() string {
+	return crd.Spec.Group + "/" + crd.Spec.Version
+}
+
+// CustomObject is an object of a kind registered by a CustomResourceDefinition.
+// Besides apiVersion, kind and metadata it keeps its fields as they are.
+type CustomObject struct {
+	Base   `yaml:",inline"`
+	Fields map[string]interface{} `yaml:",inline"`
+}
+
+var baseFields = []string{"ApiVersion", "Kind", "Metadata"}
+
+func (obj CustomObject) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]interface{}, len(obj.Fields)+len(baseFields))
+	for key, value := range obj.Fields {
+		fields[key] = value
+	}
+	fields["ApiVersion"] = obj.ApiVersion
+	fields["Kind"] = obj.Kind
+	fields["Metadata"] = obj.Metadata
+	return json.Marshal(fields)
+}
+
+func (obj *CustomObject) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &obj.Base); err != nil {
+		return err
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, key := range baseFields {
+		delete(fields, key)
+	}
+	obj.Fields = fields
+	return nil
+}
@@ -0,0 +1,167 @@
+package apiObject
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"minik8s/apiObject/types"
+	"strconv"
+	"strings"
+)
+
+const (
+	RecreateDeploymentStrategyType      = "Recreate"
+	RollingUpdateDeploymentStrategyType = "RollingUpdate"
+
+	// DefaultMaxSurge and DefaultMaxUnavailable bound a rolling update that
+	// does not say otherwise.
+	DefaultMaxSurge             = "25%"
+	DefaultMaxUnavailable       = "25%"
+	DefaultRevisionHistoryLimit = 10
+)
+
+const (
+	// DeploymentLabel is set on the replicaSets of a deployment, to its name
+	DeploymentLabel = "io.minik8s.deployment"
+	// PodTemplateHashLabel is set on the replicaSets of a deployment, to the
+	// hash of the pod template they run.
+	PodTemplateHashLabel = "io.minik8s.pod-template-hash"
+	// RevisionAnnotation numbers the replicaSets of a deployment in the order
+	// their templates were rolled out.
+	RevisionAnnotation = "io.minik8s.revision"
+)
+
+// RollingUpdateDeployment bounds the pods of a rolling update. Both are a
+// number of pods, e.g. "1", or a percentage of the replicas, e.g. "25%".
+type RollingUpdateDeployment struct {
+	// MaxSurge is how many pods there may be above the replicas, rounded up
+	MaxSurge string `yaml:"maxSurge,omitempty"`
+	// MaxUnavailable is how many of the replicas may be unavailable, rounded
+	// down.
+	MaxUnavailable string `yaml:"maxUnavailable,omitempty"`
+}
+
+type DeploymentStrategy struct {
+	// Type is RollingUpdate, the default, or Recreate, which deletes all the
+	// old pods before creating the new ones.
+	Type          string                  `yaml:"type,omitempty"`
+	RollingUpdate RollingUpdateDeployment `yaml:"rollingUpdate,omitempty"`
+}
+
+type DeploymentSpec struct {
+	Replicas int                `yaml:"replicas"`
+	Selector LabelSelector      `yaml:"selector"`
+	Template PodTemplateSpec    `yaml:"template"`
+	Strategy DeploymentStrategy `yaml:"strategy,omitempty"`
+	// RevisionHistoryLimit is how many old replicaSets are kept to roll back
+	// to, 10 if not set.
+	RevisionHistoryLimit *int `yaml:"revisionHistoryLimit,omitempty"`
+	// Paused stops the rollout of a new template until it is resumed
+	Paused bool `yaml:"paused,omitempty"`
+}
+
+// DeploymentStatus is set by the deployment controller
+type DeploymentStatus struct {
+	// TemplateHash is the hash of the template the status is about
+	TemplateHash string `yaml:"templateHash,omitempty"`
+	Revision     int    `yaml:"revision,omitempty"`
+	// Replicas counts the pods of all the replicaSets, UpdatedReplicas those
+	// of the replicaSet of the current template.
+	Replicas          int `yaml:"replicas,omitempty"`
+	UpdatedReplicas   int `yaml:"updatedReplicas,omitempty"`
+	AvailableReplicas int `yaml:"availableReplicas,omitempty"`
+}
+
+// Deployment rolls out the changes of its pod template to a new replicaSet,
+// scaling the replicaSets of the former templates down as the new one scales
+// up.
+type Deployment struct {
+	Base   `yaml:",inline"`
+	Spec   DeploymentSpec   `yaml:"spec"`
+	Status DeploymentStatus `yaml:"status,omitempty"`
+}
+
+func (d *Deployment) Name() string {
+	return d.Metadata.Name
+}
+
+func (d *Deployment) Namespace() string {
+	return d.Metadata.Namespace
+}
+
+func (d *Deployment) UID() types.UID {
+	return d.Metadata.UID
+}
+
+func (d *Deployment) Replicas() int {
+	return d.Spec.Replicas
+}
+
+func (d *Deployment) FullName() string {
+	return d.Metadata.Name + "_" + d.Metadata.Namespace
+}
+
+func (d *Deployment) RevisionHistoryLimit() int {
+	if d.Spec.RevisionHistoryLimit == nil {
+		return DefaultRevisionHistoryLimit
+	}
+	return *d.Spec.RevisionHistoryLimit
+}
+
+// TemplateHash identifies the pod template, the replicaSet of a template is
+// named after it.
+func (d *Deployment) TemplateHash() string {
+	return TemplateHash(d.Spec.Template)
+}
+
+func TemplateHash(template PodTemplateSpec) string {
+	templateJson, _ := json.Marshal(template)
+	hash := fnv.New32a()
+	_, _ = hash.Write(templateJson)
+	return strconv.FormatUint(uint64(hash.Sum32()), 36)
+}
+
+// MaxSurgeAndUnavailable resolves the bounds of a rolling update against the
+// replicas. At least one of them is positive, or the rollout could not
+// proceed.
+func (d *Deployment) MaxSurgeAndUnavailable() (maxSurge, maxUnavailable int, err error) {
+	surge, unavailable := d.Spec.Strategy.RollingUpdate.MaxSurge, d.Spec.Strategy.RollingUpdate.MaxUnavailable
+	if surge == "" {
+		surge = DefaultMaxSurge
+	}
+	if unavailable == "" {
+		unavailable = DefaultMaxUnavailable
+	}
+	if maxSurge, err = scaledValue(surge, d.Replicas(), true); err != nil {
+		return 0, 0, fmt.Errorf("invalid maxSurge: %s", err.Error())
+	}
+	if maxUnavailable, err = scaledValue(unavailable, d.Replicas(), false); err != nil {
+		return 0, 0, fmt.Errorf("invalid maxUnavailable: %s", err.Error())
+	}
+	if maxUnavailable > d.Replicas() {
+		maxUnavailable = d.Replicas()
+	}
+	if maxSurge == 0 && maxUnavailable == 0 {
+		maxUnavailable = 1
+	}
+	return maxSurge, maxUnavailable, nil
+}
+
+// scaledValue reads a number, or a percentage of total
+func scaledValue(value string, total int, roundUp bool) (int, error) {
+	if !strings.HasSuffix(value, "%") {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("%s is neither a number nor a percentage", value)
+		}
+		return n, nil
+	}
+	percent, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+	if err != nil || percent < 0 {
+		return 0, fmt.Errorf("%s is neither a number nor a percentage", value)
+	}
+	if roundUp {
+		return (percent*total + 99) / 100, nil
+	}
+	return percent * total / 100, nil
+}
@@ -2,7 +2,7 @@ package apiObject
 
 type DnsService struct {
 	Name string `yaml:"name,omitempty"`
-	Port string `yaml:"port,omitempty"`
+	Port string `yaml:"port,omitempty" schema:"format=port"`
 }
 
 type DnsPath struct {
@@ -16,6 +16,8 @@ type DnsSpec struct {
 }
 
 type DnsStatus struct {
+	// Ip is that of the nginx serving the dns, which its host resolves to
+	Ip string `yaml:"ip,omitempty"`
 }
 
 type Dns struct {
@@ -0,0 +1,89 @@
+package apiObject
+
+import (
+	"hash/fnv"
+	"minik8s/apiObject/types"
+	"strconv"
+	"time"
+)
+
+const (
+	// EventTypeNormal is an event that goes as expected, e.g. a pod scheduled
+	EventTypeNormal = "Normal"
+	// EventTypeWarning is an event that needs looking into, e.g. an image
+	// that cannot be pulled.
+	EventTypeWarning = "Warning"
+)
+
+// ObjectReference names the object an event is about
+type ObjectReference struct {
+	Kind      string    `yaml:"kind"`
+	Namespace string    `yaml:"namespace,omitempty"`
+	Name      string    `yaml:"name"`
+	UID       types.UID `yaml:"uid,omitempty"`
+}
+
+func (ref ObjectReference) String() string {
+	if ref.Namespace == "" {
+		return ref.Kind + " " + ref.Name
+	}
+	return ref.Kind + " " + ref.Namespace + "/" + ref.Name
+}
+
+// EventSource is the component that reported an event, and the host it runs
+// on.
+type EventSource struct {
+	Component string `yaml:"component"`
+	Host      string `yaml:"host,omitempty"`
+}
+
+// Event tells something that happened to an object, e.g. that a pod could not
+// be scheduled. The same event reported again is counted by the api-server
+// rather than stored once more.
+type Event struct {
+	Base           `yaml:",inline"`
+	InvolvedObject ObjectReference `yaml:"involvedObject"`
+	// Type is Normal or Warning
+	Type string `yaml:"type"`
+	// Reason is a short CamelCase word for what happened, e.g. FailedScheduling
+	Reason  string      `yaml:"reason"`
+	Message string      `yaml:"message"`
+	Source  EventSource `yaml:"source"`
+	// Count is how many times the event happened, from FirstTimestamp to
+	// LastTimestamp.
+	Count          int       `yaml:"count"`
+	FirstTimestamp time.Time `yaml:"firstTimestamp"`
+	LastTimestamp  time.Time `yaml:"lastTimestamp"`
+}
+
+func (e *Event) Name() string {
+	return e.Metadata.Name
+}
+
+func (e *Event) Namespace() string {
+	return e.Metadata.Namespace
+}
+
+// Key is what the same event reported again has in common, the object, the
+// source and what happened. The event is named after it.
+func (e *Event) Key() string {
+	hash := fnv.New32a()
+	for _, field := range []string{
+		e.InvolvedObject.Kind, e.InvolvedObject.Namespace, e.InvolvedObject.Name, e.InvolvedObject.UID,
+		e.Source.Component, e.Source.Host, e.Type, e.Reason, e.Message,
+	} {
+		_, _ = hash.Write([]byte(field + "\x00"))
+	}
+	return e.InvolvedObject.Name + "." + strconv.FormatUint(uint64(hash.Sum32()), 36)
+}
+
+// ReferenceTo returns a reference to obj, an object of the given kind
+func ReferenceTo(kind string, obj Object) ObjectReference {
+	metadata := obj.Meta()
+	return ObjectReference{
+		Kind:      kind,
+		Namespace: metadata.Namespace,
+		Name:      metadata.Name,
+		UID:       metadata.UID,
+	}
+}
@@ -1,5 +1,8 @@
 package apiObject
 
+// FunctionLabel is set on the pods that serve a function, to its name
+const FunctionLabel = "io.minik8s.function"
+
 type Function struct {
 	Name string
 	Path string
@@ -0,0 +1,25 @@
+package apiObject
+
+import "time"
+
+// Lease is held by the leader of the replicas of a component, e.g. the
+// scheduler. The leader renews it, and another replica takes it over once it
+// has not been renewed for the lease duration.
+type Lease struct {
+	Base `yaml:",inline"`
+	Spec LeaseSpec `yaml:"spec"`
+}
+
+type LeaseSpec struct {
+	// HolderIdentity is the replica that holds the lease
+	HolderIdentity       string    `yaml:"holderIdentity"`
+	LeaseDurationSeconds int       `yaml:"leaseDurationSeconds"`
+	AcquireTime          time.Time `yaml:"acquireTime"`
+	RenewTime            time.Time `yaml:"renewTime"`
+	// LeaseTransitions is how many times the lease changed hands
+	LeaseTransitions int `yaml:"leaseTransitions"`
+}
+
+func (l *Lease) Name() string {
+	return l.Metadata.Name
+}
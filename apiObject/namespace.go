@@ -0,0 +1,33 @@
+package apiObject
+
+// DefaultNamespace is where objects that do not name a namespace go
+const DefaultNamespace = "default"
+
+const (
+	NamespaceActive      = "Active"
+	NamespaceTerminating = "Terminating"
+)
+
+// Namespace scopes the names of the namespaced objects, which can only be
+// created in an Active namespace. Deleting a namespace deletes everything in
+// it, until then it is Terminating.
+type Namespace struct {
+	Base   `yaml:",inline"`
+	Status NamespaceStatus `yaml:"status,omitempty"`
+}
+
+type NamespaceStatus struct {
+	Phase string `yaml:"phase"`
+}
+
+func (ns *Namespace) Name() string {
+	return ns.Metadata.Name
+}
+
+func (ns *Namespace) UID() string {
+	return ns.Metadata.UID
+}
+
+func (ns *Namespace) Phase() string {
+	return ns.Status.Phase
+}
@@ -0,0 +1,54 @@
+package apiObject
+
+// The propagation policies of a deletion, i.e. what happens to the dependents
+// of the deleted object.
+const (
+	// DeletePropagationBackground deletes the owner at once, and the garbage
+	// collector deletes the dependents afterwards. It is the default.
+	DeletePropagationBackground = "Background"
+	// DeletePropagationForeground keeps the owner until the garbage collector
+	// has deleted the dependents that block its deletion.
+	DeletePropagationForeground = "Foreground"
+	// DeletePropagationOrphan keeps the dependents, the garbage collector only
+	// removes their references to the owner.
+	DeletePropagationOrphan = "Orphan"
+)
+
+// The finalizers the garbage collector takes care of
+const (
+	FinalizerForegroundDeletion = "foregroundDeletion"
+	FinalizerOrphanDependents   = "orphan"
+)
+
+// OwnerReference links a dependent to its owner, which must be in the same
+// namespace or not namespaced.
+type OwnerReference struct {
+	ApiVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Name       string `yaml:"name"`
+	UID        string `yaml:"uid"`
+	// BlockOwnerDeletion makes a foreground deletion of the owner wait for
+	// this dependent to be deleted.
+	BlockOwnerDeletion bool `yaml:"blockOwnerDeletion,omitempty"`
+}
+
+// IsOwnedBy tells whether uid is one of the owners of the object
+func (meta *Metadata) IsOwnedBy(uid string) bool {
+	for _, ref := range meta.OwnerReferences {
+		if ref.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// HasFinalizer tells whether finalizer still has to run before the object is
+// deleted.
+func (meta *Metadata) HasFinalizer(finalizer string) bool {
+	for _, f := range meta.Finalizers {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
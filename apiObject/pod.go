@@ -1,7 +1,9 @@
 package apiObject
 
 const (
-	PullPolicyAlways = "Always"
+	PullPolicyAlways       = "Always"
+	PullPolicyIfNotPresent = "IfNotPresent"
+	PullPolicyNever        = "Never"
 )
 
 type Labels map[string]string
@@ -25,9 +27,9 @@ func (annotations Annotations) DeepCopy() Annotations {
 
 type ContainerPort struct {
 	Name          string `yaml:"name"`
-	HostPort      string `yaml:"hostPort"`
-	ContainerPort string `yaml:"containerPort"`
-	Protocol      string `yaml:"protocol"`
+	HostPort      string `yaml:"hostPort" schema:"format=port"`
+	ContainerPort string `yaml:"containerPort" schema:"required,format=port"`
+	Protocol      string `yaml:"protocol" schema:"enum=TCP|UDP|SCTP"`
 	HostIP        string `yaml:"hostIP"`
 }
 
@@ -62,7 +64,7 @@ type Probe struct {
 }
 
 type EnvVar struct {
-	Name  string `yaml:"name"`
+	Name  string `yaml:"name" schema:"required"`
 	Value string `yaml:"value"`
 }
 
@@ -79,7 +81,7 @@ type ContainerResources struct {
 type ContainerLivenessProbeConfig struct {
 	HttpGet struct {
 		Path   string `yaml:"path"`
-		Port   int    `yaml:"port"`
+		Port   int    `yaml:"port" schema:"minimum=1,maximum=65535"`
 		Host   string `yaml:"host"`
 		Scheme string `yaml:"scheme"`
 	} `yaml:"httpGet"`
@@ -100,15 +102,15 @@ type ContainerLifecycleConfig struct {
 }
 
 type VolumeMount struct {
-	Name      string `yaml:"name"`
-	MountPath string `yaml:"mountPath"`
+	Name      string `yaml:"name" schema:"required"`
+	MountPath string `yaml:"mountPath" schema:"required"`
 	ReadOnly  bool   `yaml:"readOnly"`
 }
 
 type Container struct {
-	Name            string                       `yaml:"name"`
-	Image           string                       `yaml:"image"`
-	ImagePullPolicy string                       `yaml:"imagePullPolicy"`
+	Name            string                       `yaml:"name" schema:"required,format=dns1123-label"`
+	Image           string                       `yaml:"image" schema:"required"`
+	ImagePullPolicy string                       `yaml:"imagePullPolicy" schema:"enum=Always|IfNotPresent|Never"`
 	Command         []string                     `yaml:"cmd,flow"`
 	Args            []string                     `yaml:"args,flow"`
 	Env             []EnvVar                     `yaml:"env"`
@@ -139,14 +141,14 @@ func (vs *VolumeSource) IsHostPath() bool {
 }
 
 type Volume struct {
-	Name         string `yaml:"name"`
+	Name         string `yaml:"name" schema:"required"`
 	VolumeSource `yaml:",inline"`
 }
 
 type PodSpec struct {
-	RestartPolicy string      `yaml:"restartPolicy"`
+	RestartPolicy string      `yaml:"restartPolicy" schema:"enum=Always|OnFailure|Never"`
 	NodeSelector  Labels      `yaml:"nodeSelector,omitempty"`
-	Containers    []Container `yaml:"containers"`
+	Containers    []Container `yaml:"containers" schema:"required"`
 	Volumes       []Volume    `yaml:"volumes"`
 	ClusterIp     string      `yaml:"clusterIp,omitempty"`
 }
@@ -0,0 +1,85 @@
+package apiObject
+
+import "strings"
+
+// PolicyRule allows verbs, i.e. get, list, watch, create, update and delete,
+// on resources, i.e. what follows /api/v1/ in their urls like pods or
+// replicaSets, or the plural of a custom kind. NonResourceURLs allow the
+// other urls, e.g. /reset, a trailing * matching any suffix. "*" matches
+// everything.
+type PolicyRule struct {
+	Verbs           []string `yaml:"verbs"`
+	Resources       []string `yaml:"resources,omitempty"`
+	ResourceNames   []string `yaml:"resourceNames,omitempty"`
+	NonResourceURLs []string `yaml:"nonResourceURLs,omitempty"`
+}
+
+// Role grants its rules in its own namespace
+type Role struct {
+	Base  `yaml:",inline"`
+	Rules []PolicyRule `yaml:"rules"`
+}
+
+// ClusterRole grants its rules in every namespace if bound by a
+// ClusterRoleBinding, or in the namespace of a RoleBinding.
+type ClusterRole struct {
+	Base  `yaml:",inline"`
+	Rules []PolicyRule `yaml:"rules"`
+}
+
+const (
+	SubjectUser  = "User"
+	SubjectGroup = "Group"
+)
+
+// Subject is a user or a group a role is bound to
+type Subject struct {
+	Kind string `yaml:"kind"`
+	Name string `yaml:"name"`
+}
+
+// RoleRef names a Role in the namespace of the binding, or a ClusterRole
+type RoleRef struct {
+	Kind string `yaml:"kind"`
+	Name string `yaml:"name"`
+}
+
+// RoleBinding grants a Role or ClusterRole to subjects in its namespace
+type RoleBinding struct {
+	Base     `yaml:",inline"`
+	Subjects []Subject `yaml:"subjects"`
+	RoleRef  RoleRef   `yaml:"roleRef"`
+}
+
+// ClusterRoleBinding grants a ClusterRole to subjects in every namespace
+type ClusterRoleBinding struct {
+	Base     `yaml:",inline"`
+	Subjects []Subject `yaml:"subjects"`
+	RoleRef  RoleRef   `yaml:"roleRef"`
+}
+
+func matches(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == value {
+			return true
+		}
+		if strings.HasSuffix(pattern, "*") && strings.HasPrefix(value, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsResource tells whether the rule allows verb on the object name of
+// resource, name being empty for a list or a create.
+func (rule *PolicyRule) AllowsResource(verb, resource, name string) bool {
+	if !matches(rule.Verbs, verb) || !matches(rule.Resources, resource) {
+		return false
+	}
+	return len(rule.ResourceNames) == 0 || (name != "" && matches(rule.ResourceNames, name))
+}
+
+// AllowsNonResourceURL tells whether the rule allows verb on path
+func (rule *PolicyRule) AllowsNonResourceURL(verb, path string) bool {
+	return matches(rule.Verbs, verb) && matches(rule.NonResourceURLs, path)
+}
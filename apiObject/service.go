@@ -2,8 +2,8 @@ package apiObject
 
 type ServicePort struct {
 	Name       string `yaml:"name"`
-	Port       int32  `yaml:"port,omitempty"`
-	TargetPort int32  `yaml:"targetPort,omitempty"`
+	Port       int32  `yaml:"port,omitempty" schema:"required,minimum=1,maximum=65535"`
+	TargetPort int32  `yaml:"targetPort,omitempty" schema:"minimum=1,maximum=65535"`
 }
 
 type ServiceSpec struct {
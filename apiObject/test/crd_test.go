@@ -0,0 +1,48 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"minik8s/apiObject"
+	"testing"
+)
+
+func TestCustomObject(t *testing.T) {
+	content, err := ioutil.ReadFile("../examples/crd/featureflag-example.yaml")
+	assert.Nil(t, err)
+
+	obj := apiObject.CustomObject{}
+	assert.Nil(t, yaml.Unmarshal(content, &obj))
+	assert.Equal(t, "FeatureFlag", obj.Kind)
+	assert.Equal(t, "new-scheduler", obj.Metadata.Name)
+	assert.Contains(t, obj.Fields, "spec")
+	assert.NotContains(t, obj.Fields, "metadata")
+
+	raw, err := json.Marshal(obj)
+	assert.Nil(t, err)
+	decoded := apiObject.CustomObject{}
+	assert.Nil(t, json.Unmarshal(raw, &decoded))
+	assert.Equal(t, obj.Base, decoded.Base)
+	spec := decoded.Fields["spec"].(map[string]interface{})
+	assert.Equal(t, true, spec["enabled"])
+	assert.Equal(t, float64(20), spec["percent"])
+}
+
+func TestCustomResourceDefinition(t *testing.T) {
+	content, err := ioutil.ReadFile("../examples/crd/crd-example.yaml")
+	assert.Nil(t, err)
+
+	crd := apiObject.CustomResourceDefinition{}
+	assert.Nil(t, yaml.Unmarshal(content, &crd))
+	assert.Equal(t, "example.com/v1", crd.GroupVersion())
+	assert.True(t, crd.Namespaced())
+
+	// the schema must survive the trip to the api-server
+	raw, err := json.Marshal(crd)
+	assert.Nil(t, err)
+	decoded := apiObject.CustomResourceDefinition{}
+	assert.Nil(t, json.Unmarshal(raw, &decoded))
+	assert.Equal(t, "object", decoded.Spec.Schema["type"])
+}
@@ -0,0 +1,30 @@
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"minik8s/apiObject"
+	"minik8s/util/selectorutil"
+	"testing"
+)
+
+func TestEventKey(t *testing.T) {
+	event := apiObject.Event{
+		InvolvedObject: apiObject.ObjectReference{Kind: "Pod", Namespace: "default", Name: "nginx", UID: "1"},
+		Type:           apiObject.EventTypeWarning,
+		Reason:         "FailedScheduling",
+		Message:        "no suitable node",
+		Source:         apiObject.EventSource{Component: "scheduler"},
+	}
+	again := event
+	again.Count = 3
+	assert.Equal(t, event.Key(), again.Key())
+
+	other := event
+	other.Message = "0/2 nodes match the node selector"
+	assert.NotEqual(t, event.Key(), other.Key())
+
+	fields := selectorutil.FieldsOf(&event)
+	assert.Equal(t, "nginx", fields["involvedObject.name"])
+	assert.Equal(t, "Pod", fields["involvedObject.kind"])
+	assert.Equal(t, "FailedScheduling", fields["reason"])
+}
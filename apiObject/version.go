@@ -0,0 +1,15 @@
+package apiObject
+
+// Convertible is an object of a version of its kind other than the hub
+// version, the type of the kind in this package that the components work
+// with. The api-server serves it besides the hub version and may store it,
+// converting it to and from the hub on the way. A conversion must not lose
+// anything, an object converted from the hub and back is the same.
+type Convertible interface {
+	Object
+	// ConvertTo sets hub, a new object of the hub version, from the object
+	ConvertTo(hub Object) error
+	// ConvertFrom sets the object from hub, along with its apiVersion and
+	// kind.
+	ConvertFrom(hub Object) error
+}
@@ -0,0 +1,76 @@
+package apiObject
+
+import "time"
+
+const (
+	// FailurePolicyFail rejects the request if the webhook cannot be called,
+	// it is the default.
+	FailurePolicyFail   = "Fail"
+	FailurePolicyIgnore = "Ignore"
+
+	defaultWebhookTimeoutSeconds = 10
+)
+
+// MutatingWebhookConfiguration lists webhooks that may change the objects
+// written to the api-server, before they are validated.
+type MutatingWebhookConfiguration struct {
+	Base     `yaml:",inline"`
+	Webhooks []Webhook `yaml:"webhooks"`
+}
+
+// ValidatingWebhookConfiguration lists webhooks that may reject the objects
+// written to the api-server.
+type ValidatingWebhookConfiguration struct {
+	Base     `yaml:",inline"`
+	Webhooks []Webhook `yaml:"webhooks"`
+}
+
+// Webhook is POSTed an entity.AdmissionReview for every write that matches
+// one of its rules, and answers it with the same review carrying a response.
+type Webhook struct {
+	Name           string        `yaml:"name"`
+	URL            string        `yaml:"url"`
+	Rules          []WebhookRule `yaml:"rules"`
+	FailurePolicy  string        `yaml:"failurePolicy,omitempty"`
+	TimeoutSeconds int           `yaml:"timeoutSeconds,omitempty"`
+}
+
+// WebhookRule matches writes by operation, i.e. CREATE or UPDATE, and kind.
+// "*" matches everything.
+type WebhookRule struct {
+	Operations []string `yaml:"operations"`
+	Kinds      []string `yaml:"kinds"`
+}
+
+func matchesAny(values []string, value string) bool {
+	for _, v := range values {
+		if v == "*" || v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (rule *WebhookRule) Matches(operation, kind string) bool {
+	return matchesAny(rule.Operations, operation) && matchesAny(rule.Kinds, kind)
+}
+
+func (webhook *Webhook) Matches(operation, kind string) bool {
+	for _, rule := range webhook.Rules {
+		if rule.Matches(operation, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+func (webhook *Webhook) Timeout() time.Duration {
+	if webhook.TimeoutSeconds <= 0 {
+		return defaultWebhookTimeoutSeconds * time.Second
+	}
+	return time.Duration(webhook.TimeoutSeconds) * time.Second
+}
+
+func (webhook *Webhook) IgnoresFailure() bool {
+	return webhook.FailurePolicy == FailurePolicyIgnore
+}
@@ -1,10 +1,22 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"minik8s/apiserver/src/apiserver"
+	"minik8s/listwatch"
+	"minik8s/util/configutil"
+	"os"
 )
 
 func main() {
+	listwatch.SetIdentity("apiserver")
+	configutil.AddFlags(flag.CommandLine)
+	flag.Parse()
+	if err := configutil.Load(flag.CommandLine); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(-1)
+	}
 	apiServer := apiserver.New()
 	apiServer.Run()
 }
@@ -1,14 +1,26 @@
 package apiserver
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"github.com/gin-gonic/gin"
+	"io/ioutil"
 	"log"
+	"minik8s/apiserver/src/audit"
+	"minik8s/apiserver/src/auth"
 	"minik8s/apiserver/src/etcd"
+	"minik8s/apiserver/src/handlers"
 	"minik8s/apiserver/src/ipgen"
+	"minik8s/apiserver/src/registry"
 	"minik8s/apiserver/src/url"
 	"minik8s/listwatch"
+	"minik8s/util/certutil"
+	"minik8s/util/configutil"
+	"minik8s/util/httputil"
 	"minik8s/util/logger"
 	"minik8s/util/topicutil"
+	"minik8s/util/wait"
+	"net/http"
 )
 
 type ApiServer interface {
@@ -21,17 +33,84 @@ func New() ApiServer {
 	}
 }
 
+// NewWithEmbeddedEtcd returns an api-server that runs etcd in its own
+// process, with the data in etcdDataDir, instead of in a container.
+func NewWithEmbeddedEtcd(etcdDataDir string) ApiServer {
+	return &apiServer{
+		httpServer:  gin.Default(),
+		etcdDataDir: etcdDataDir,
+	}
+}
+
 type apiServer struct {
-	httpServer *gin.Engine
+	httpServer  *gin.Engine
+	etcdDataDir string
+}
+
+func (api *apiServer) bindKinds() {
+	handlers.RegisterKinds()
+	for _, kind := range registry.Kinds() {
+		if kind.Group != "" {
+			// custom kinds come and go, HandleCustomResource serves them
+			continue
+		}
+		if _, exists := postTable[kind.Prefix]; !exists {
+			api.httpServer.POST(kind.Prefix, registry.HandleCreate(kind))
+		}
+		api.httpServer.GET(kind.ItemURL(), registry.HandleGet(kind))
+		api.httpServer.PUT(kind.ItemURL(), registry.HandleUpdate(kind))
+		api.httpServer.PATCH(kind.ItemURL(), registry.HandlePatch(kind))
+		api.httpServer.DELETE(kind.ItemURL(), registry.HandleDelete(kind))
+		if _, exists := getTable[kind.Prefix]; !exists {
+			api.httpServer.GET(kind.Prefix, registry.HandleList(kind))
+		}
+	}
+	api.httpServer.Any(url.CustomResourceURLWithSpecifiedPath, handlers.HandleCustomResource)
+	api.httpServer.NoRoute(func(c *gin.Context) {
+		registry.WriteError(c, httputil.NewNotFound("path", "", c.Request.URL.Path))
+	})
+}
+
+// secure creates the credentials and the certificates on the first start,
+// then authenticates every request by its bearer token or client
+// certificate, records it in the audit log as the audit policy selects, and
+// authorizes it against the RBAC objects.
+func (api *apiServer) secure() error {
+	if err := auth.Bootstrap(); err != nil {
+		return err
+	}
+	if err := certutil.Bootstrap([]string{url.Hostname}, false); err != nil {
+		return err
+	}
+	tokenAuthenticator, err := auth.NewTokenAuthenticator(auth.TokenFile())
+	if err != nil {
+		return err
+	}
+	policy, err := audit.LoadPolicy(audit.PolicyFile())
+	if err != nil {
+		return err
+	}
+	auditLog, err := audit.NewFileWriter(policy.LogFile, int64(policy.MaxSize)*audit.Megabyte, policy.MaxBackups)
+	if err != nil {
+		return err
+	}
+	api.httpServer.Use(
+		auth.Authenticate(&auth.CertAuthenticator{}, tokenAuthenticator),
+		audit.Audit(policy, auditLog),
+		auth.Authorize(&auth.RBACAuthorizer{}),
+	)
+	return nil
 }
 
 func (api *apiServer) bindHandlers() {
+	api.bindKinds()
+
 	for URL, handler := range postTable {
 		api.httpServer.POST(URL, handler)
 	}
 
 	for URL, handler := range getTable {
-		api.httpServer.GET(URL, handler)
+		api.httpServer.GET(URL, handlers.Watchable(URL, handler))
 	}
 
 	for URL, handler := range deleteTable {
@@ -41,6 +120,10 @@ func (api *apiServer) bindHandlers() {
 	for URL, handler := range putTable {
 		api.httpServer.PUT(URL, handler)
 	}
+
+	for URL, handler := range patchTable {
+		api.httpServer.PATCH(URL, handler)
+	}
 }
 
 func (api *apiServer) watch() {
@@ -49,6 +132,8 @@ func (api *apiServer) watch() {
 	go listwatch.Watch(topicutil.ReplicaSetStatusTopic(), syncReplicaSetStatus)
 	go listwatch.Watch(topicutil.HPAStatusTopic(), syncHPAStatus)
 	go listwatch.Watch(topicutil.GpuJobStatusTopic(), syncGpuJobStatus)
+	eventPurgePeriod := configutil.Current().SyncPeriods.EventPurge
+	go wait.Period(eventPurgePeriod, eventPurgePeriod, handlers.PurgeEvents)
 }
 
 func ipInit(url, ip string, mask int) error {
@@ -57,18 +142,49 @@ func ipInit(url, ip string, mask int) error {
 }
 
 func (api *apiServer) Run() {
-	etcd.Start()
+	if api.etcdDataDir == "" {
+		etcd.Start()
+	} else if _, err := etcd.StartEmbedded(api.etcdDataDir); err != nil {
+		logger.Log("api-server-etcd")(err.Error())
+		return
+	}
 	//_ = etcd.DeleteAllKeys()
 
-	if err := ipInit(url.PodIpURL, url.PodIpBase, url.Mask); err != nil {
+	podIpBase, podMask := configutil.Current().PodIPRange()
+	if err := ipInit(url.PodIpURL, podIpBase, podMask); err != nil {
 		logger.Log("api-server-pod-ip")(err.Error())
 		return
 	}
-	if err := ipInit(url.ServiceIpURL, url.ServiceIpBase, url.Mask); err != nil {
+	serviceIpBase, serviceMask := configutil.Current().ServiceIPRange()
+	if err := ipInit(url.ServiceIpURL, serviceIpBase, serviceMask); err != nil {
 		logger.Log("api-server-service-ip")(err.Error())
 		return
 	}
+	if err := api.secure(); err != nil {
+		logger.Log("api-server-auth")(err.Error())
+		return
+	}
 	api.bindHandlers()
 	api.watch()
-	log.Fatal(api.httpServer.Run(":8080"))
+	log.Fatal(api.serve())
+}
+
+// serve serves HTTPS with the certificate issued by the cluster CA, and
+// verifies the client certificates against the same CA.
+func (api *apiServer) serve() error {
+	caPem, err := ioutil.ReadFile(certutil.CertFile(certutil.CA))
+	if err != nil {
+		return err
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(caPem)
+	server := &http.Server{
+		Addr:    ":" + configutil.Current().ApiServerPort(),
+		Handler: api.httpServer,
+		TLSConfig: &tls.Config{
+			ClientCAs:  clientCAs,
+			ClientAuth: tls.VerifyClientCertIfGiven,
+		},
+	}
+	return server.ListenAndServeTLS(certutil.CertFile(certutil.ApiServer), certutil.KeyFile(certutil.ApiServer))
 }
@@ -8,18 +8,14 @@ import (
 
 type Handler = gin.HandlerFunc
 
+// The kinds in the registry get their create, get, update and delete routes
+// from bindKinds, the tables below only hold the rest. A list URL in getTable
+// takes precedence over the registry, kubectl lists the statuses there, and
+// so does a list URL in postTable.
+
 var postTable = map[string]Handler{
-	// kubectl apply -f xxx.yaml
-	url.NodeURL:       handlers.HandleApplyNode,
-	url.PodURL:        handlers.HandleApplyPod,
-	url.ReplicaSetURL: handlers.HandleApplyReplicaSet,
-	url.HPAURL:        handlers.HandleApplyHPA,
-	url.ServiceURL:    handlers.HandleApplyService,
-	url.DNSURL:        handlers.HandleApplyDNS,
-	url.GpuURL:        handlers.HandleApplyGpuJob,
-
-	// kubectl wf apply -f
-	url.WorkflowURL: handlers.HandleApplyWorkflow,
+	// kubectl apply -f pod.yaml, pods are scheduled before they are stored
+	url.PodURL: handlers.HandleApplyPod,
 
 	// update pod after it's scheduled
 	url.PodURLWithSpecifiedNode: handlers.HandleSchedulePod,
@@ -35,6 +31,18 @@ var postTable = map[string]Handler{
 
 	// post workflow result
 	url.WorkflowResultURLWithSpecifiedName: handlers.HandlePutWorkflowResult,
+
+	// the components report events, which are counted if reported again
+	url.EventURL: handlers.HandleRecordEvent,
+
+	// kubectl backup restore -f file
+	url.BackupURL: handlers.HandleRestore,
+
+	// kubectl migrate
+	url.MigrateURL: handlers.HandleMigrate,
+
+	// a joining kubelet requests its certificate, along with who it is
+	url.CSRURL: handlers.HandleCreateCSR,
 }
 
 var getTable = map[string]Handler{
@@ -59,19 +67,7 @@ var getTable = map[string]Handler{
 
 	// get apiObject.xxx
 	url.PodURLWithSpecifiedNodeAndName: handlers.HandleGetPodApiObject,
-	url.ReplicaSetURLWithSpecifiedName: handlers.HandleGetReplicaSetApiObject,
-	url.HPAURLWithSpecifiedName:        handlers.HandleGetHPAApiObject,
 	url.PodURLWithSpecifiedNode:        handlers.HandleGetPodsApiObject,
-	url.GpuURLWithSpecifiedName:        handlers.HandleGetGpuApiObject,
-
-	// kubectl get service service_name
-	url.ServiceURLWithSpecifiedName: handlers.HandleGetService,
-	// kubectl get services
-	url.ServiceURL: handlers.HandleGetServices,
-
-	// kubectl get dns dns_name
-	url.DNSURLWithSpecifiedName: handlers.HandleGetDNS,
-	url.DNSURL:                  handlers.HandleGetDNSes,
 
 	// kubectl get func func_name
 	url.FuncURLWithSpecifiedName: handlers.HandleGetFunction,
@@ -85,34 +81,42 @@ var getTable = map[string]Handler{
 	// kubectl get gpus
 	url.GpuURL: handlers.HandleGetGpuJobs,
 
-	// get pod function pods
-	url.FuncPodsURLWithSpecifiedName: handlers.HandleGetFuncPods,
-
 	// get workflow result
 	url.WorkflowResultURLWithSpecifiedName: handlers.HandleGetWorkflowResult,
 
 	// get all workflow results
 	url.WorkflowURL: handlers.HandleGetWorkflowResults,
+
+	// the ownership graph of the garbage collector
+	url.ObjectMetaURL: handlers.HandleGetObjectMetas,
+
+	// kubectl backup create -o file
+	url.BackupURL: handlers.HandleBackup,
 }
 
 var putTable = map[string]Handler{
 	// Set Node Status
-	url.NodeStatusURLWithSpecifiedName: handlers.HandleSetNodeStatus,
-	url.ReplicaSetURLWithSpecifiedName: handlers.HandleSetReplicaSet,
+	url.NodeStatusURLWithSpecifiedName:      handlers.HandleSetNodeStatus,
+	url.ReplicaSetScaleURLWithSpecifiedName: handlers.HandleScaleReplicaSet,
+
+	// orphan or adopt a pod
+	url.PodURLWithSpecifiedNodeAndName: handlers.HandleUpdatePodOwnerReferences,
 
 	// kubectl func update func_name
 	url.FuncURLWithSpecifiedName: handlers.HandleUpdateFunc,
+
+	// kubectl certs approve|deny csr_name
+	url.CSRApprovalURLWithSpecifiedName: handlers.HandleApproveCSR,
+}
+
+var patchTable = map[string]Handler{
+	// kubectl apply -f pod.yaml & kubectl patch pod pod_name
+	url.PodURLWithSpecifiedName: handlers.HandlePatchPod,
 }
 
 var deleteTable = map[string]Handler{
 	// kubectl delete apiObjectType apiObjectName
-	url.PodURLWithSpecifiedName:        handlers.HandleDeletePod,
-	url.NodeURLWithSpecifiedName:       handlers.HandleDeleteNode,
-	url.ReplicaSetURLWithSpecifiedName: handlers.HandleDeleteReplicaSet,
-	url.HPAURLWithSpecifiedName:        handlers.HandleDeleteHPA,
-	url.ServiceURLWithSpecifiedName:    handlers.HandleDeleteService,
-	url.DNSURLWithSpecifiedName:        handlers.HandleDeleteDNS,
-	url.GpuURLWithSpecifiedName:        handlers.HandleDeleteGpuJob,
+	url.PodURLWithSpecifiedName: handlers.HandleDeletePod,
 
 	// kubectl reset
 	url.ResetURL: handlers.HandleReset,
@@ -120,9 +124,9 @@ var deleteTable = map[string]Handler{
 	// delete pods of a node
 	url.PodURLWithSpecifiedNode: handlers.HandleDeleteNodePods,
 
+	// delete a pod by the url the garbage collector knows it by
+	url.PodURLWithSpecifiedNodeAndName: handlers.HandleDeletePod,
+
 	// kubectl func rm func_name
 	url.FuncURLWithSpecifiedName: handlers.HandleRemoveFunc,
-
-	// kubectl wf rm workflow name:
-	url.WorkflowURLWithSpecifiedName: handlers.HandleRemoveWorkflow,
 }
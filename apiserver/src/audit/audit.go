@@ -0,0 +1,177 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"io"
+	"io/ioutil"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/auth"
+	"minik8s/apiserver/src/registry"
+	"minik8s/util/logger"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+var log = logger.Log("Audit")
+
+// Record is a line of the audit log
+type Record struct {
+	Timestamp time.Time `json:"timestamp"`
+	Level     Level     `json:"level"`
+	User      string    `json:"user"`
+	Groups    []string  `json:"groups,omitempty"`
+	SourceIP  string    `json:"sourceIP"`
+	Verb      string    `json:"verb"`
+	URL       string    `json:"url"`
+	Kind      string    `json:"kind,omitempty"`
+	// ObjectKey is where the object is stored, e.g.
+	// /api/v1/replicaSets/default/nginx
+	ObjectKey    string      `json:"objectKey,omitempty"`
+	Code         int         `json:"code"`
+	RequestBody  interface{} `json:"requestBody,omitempty"`
+	ResponseBody interface{} `json:"responseBody,omitempty"`
+}
+
+// Audit writes a record of every request the policy selects to out, once the
+// request is served. It must come after auth.Authenticate, which tells who
+// sent the request, and before auth.Authorize, so the forbidden requests are
+// recorded too.
+func Audit(policy *Policy, out io.Writer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, attrs := auth.UserFrom(c), auth.AttributesFrom(c)
+		kindName, kind := kindOf(c, attrs)
+		info := &requestInfo{user: user.Name, groups: user.Groups, verb: attrs.Verb, kind: kindName, namespace: attrs.Namespace}
+		level := policy.levelOf(info)
+		if level == LevelNone {
+			return
+		}
+
+		record := &Record{
+			Timestamp: time.Now(),
+			Level:     level,
+			User:      user.Name,
+			Groups:    user.Groups,
+			SourceIP:  c.ClientIP(),
+			Verb:      attrs.Verb,
+			URL:       c.Request.URL.String(),
+			Kind:      info.kind,
+		}
+
+		// The name of an object to create is only in the body
+		var requestBody []byte
+		if c.Request.Body != nil && (c.Request.Method == http.MethodPost || !level.Less(LevelRequest)) {
+			requestBody, _ = ioutil.ReadAll(c.Request.Body)
+			_ = c.Request.Body.Close()
+			c.Request.Body = ioutil.NopCloser(bytes.NewReader(requestBody))
+		}
+		if attrs.ResourceRequest {
+			record.ObjectKey = objectKeyOf(kind, attrs, requestBody)
+		}
+		if !level.Less(LevelRequest) {
+			record.RequestBody = bodyOf(requestBody)
+		}
+
+		var response *bodyWriter
+		if level == LevelRequestResponse && attrs.Verb != auth.VerbWatch {
+			response = &bodyWriter{ResponseWriter: c.Writer}
+			c.Writer = response
+		}
+
+		c.Next()
+
+		record.Code = c.Writer.Status()
+		if response != nil {
+			record.ResponseBody = bodyOf(response.body.Bytes())
+		}
+		line, err := json.Marshal(record)
+		if err != nil {
+			log("marshal record: %s", err.Error())
+			return
+		}
+		if _, err = out.Write(append(line, '\n')); err != nil {
+			log("write record: %s", err.Error())
+		}
+	}
+}
+
+// tableKinds are the kinds of the resources only the handler tables serve
+var tableKinds = map[string]string{
+	"pods": "Pod",
+	"func": "Function",
+}
+
+// kindOf finds the kind of the objects the request is on, if any. The kinds
+// in the registry are returned as well.
+func kindOf(c *gin.Context, attrs *auth.Attributes) (string, *registry.Kind) {
+	if !attrs.ResourceRequest {
+		return "", nil
+	}
+	if group := c.Param("group"); group != "" {
+		if kind := registry.LookupResource(group, attrs.Resource); kind != nil {
+			return kind.Kind, kind
+		}
+		return "", nil
+	}
+	prefix := "/api/v1/" + attrs.Resource + "/"
+	for _, kind := range registry.Kinds() {
+		if kind.Group == "" && kind.Prefix == prefix {
+			return kind.Kind, kind
+		}
+	}
+	return tableKinds[attrs.Resource], nil
+}
+
+// objectKeyOf returns the key of the object the request is on, which for a
+// create is named in the body.
+func objectKeyOf(kind *registry.Kind, attrs *auth.Attributes, body []byte) string {
+	namespace, name := attrs.Namespace, attrs.Name
+	if name == "" && len(body) > 0 {
+		obj := struct {
+			Metadata apiObject.Metadata
+		}{}
+		if json.Unmarshal(body, &obj) == nil {
+			namespace, name = obj.Metadata.Namespace, obj.Metadata.Name
+		}
+	}
+	if name == "" {
+		return ""
+	}
+	if kind == nil {
+		return path.Join("/api/v1", attrs.Resource, namespace, name)
+	}
+	if kind.Namespaced && namespace == "" {
+		namespace = apiObject.DefaultNamespace
+	}
+	return kind.Key(namespace, name)
+}
+
+// bodyOf keeps a json body as it is, and any other as a string
+func bodyOf(body []byte) interface{} {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	if json.Valid(body) {
+		return json.RawMessage(body)
+	}
+	return strings.ToValidUTF8(string(body), "")
+}
+
+// bodyWriter keeps a copy of the response body
+type bodyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
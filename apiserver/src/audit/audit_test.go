@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"minik8s/apiserver/src/auth"
+	"minik8s/apiserver/src/registry"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPolicyLevel(t *testing.T) {
+	policy := &Policy{Rules: []Rule{
+		{Level: LevelNone, Users: []string{"system:kube-scheduler"}},
+		{Level: LevelRequestResponse, Kinds: []string{"replicaset"}, Verbs: []string{"delete"}},
+		{Level: LevelNone, Verbs: []string{"get", "list", "watch"}},
+		{Level: LevelMetadata},
+	}}
+	assert.NoError(t, policy.complete())
+
+	deleteRS := &requestInfo{user: "alice", verb: "delete", kind: "ReplicaSet", namespace: "default"}
+	assert.Equal(t, LevelRequestResponse, policy.levelOf(deleteRS))
+	assert.Equal(t, LevelMetadata, policy.levelOf(&requestInfo{user: "alice", verb: "delete", kind: "Service"}))
+	assert.Equal(t, LevelNone, policy.levelOf(&requestInfo{user: "alice", verb: "list", kind: "ReplicaSet"}))
+	assert.Equal(t, LevelNone, policy.levelOf(&requestInfo{user: "system:kube-scheduler", verb: "delete", kind: "ReplicaSet"}))
+	assert.Equal(t, LevelMetadata, policy.levelOf(&requestInfo{user: "alice", verb: "create"}))
+
+	assert.Error(t, (&Policy{Rules: []Rule{{Level: "Everything"}}}).complete())
+}
+
+func TestFileWriterRotates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "audit.log")
+	w, err := NewFileWriter(name, 10, 2)
+	assert.NoError(t, err)
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err = w.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+
+	for file, content := range map[string]string{name: "fourth\n", name + ".1": "third\n", name + ".2": "second\n"} {
+		read, err := ioutil.ReadFile(file)
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(read))
+	}
+	_, err = os.Stat(name + ".3")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestAuditRecordsCreate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry.Register(&registry.Kind{Kind: "AuditTest", Prefix: "/api/v1/audittests/", Namespaced: true})
+	defer registry.Unregister("AuditTest")
+
+	var out bytes.Buffer
+	policy := &Policy{Rules: []Rule{{Level: LevelRequestResponse, Kinds: []string{"AuditTest"}}}}
+	engine := gin.New()
+	engine.Use(auth.Authenticate(), Audit(policy, &out))
+	engine.POST("/api/v1/audittests/", func(c *gin.Context) {
+		body, _ := ioutil.ReadAll(c.Request.Body)
+		c.Data(http.StatusCreated, "application/json", body)
+	})
+	engine.GET("/reset", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	body := `{"Metadata":{"Name":"example"}}`
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/audittests/", strings.NewReader(body)))
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/reset", nil))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 1)
+	record := struct {
+		Record
+		RequestBody  json.RawMessage `json:"requestBody"`
+		ResponseBody json.RawMessage `json:"responseBody"`
+	}{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, auth.UserAnonymous, record.User)
+	assert.Equal(t, auth.VerbCreate, record.Verb)
+	assert.Equal(t, "AuditTest", record.Kind)
+	assert.Equal(t, "/api/v1/audittests/default/example", record.ObjectKey)
+	assert.Equal(t, http.StatusCreated, record.Code)
+	assert.JSONEq(t, body, string(record.RequestBody))
+	assert.JSONEq(t, body, string(record.ResponseBody))
+}
@@ -0,0 +1,196 @@
+package audit
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"minik8s/global"
+	"os"
+	"path"
+	"strings"
+)
+
+// Level is how much of a request is recorded
+type Level string
+
+const (
+	// LevelNone records nothing
+	LevelNone Level = "None"
+	// LevelMetadata records who did what to which object, and the response
+	// code.
+	LevelMetadata Level = "Metadata"
+	// LevelRequest records the request body as well
+	LevelRequest Level = "Request"
+	// LevelRequestResponse records the response body as well, except for
+	// watches.
+	LevelRequestResponse Level = "RequestResponse"
+)
+
+var levelOrder = map[Level]int{
+	LevelNone:            0,
+	LevelMetadata:        1,
+	LevelRequest:         2,
+	LevelRequestResponse: 3,
+}
+
+// Less tells whether l records less than other
+func (l Level) Less(other Level) bool {
+	return levelOrder[l] < levelOrder[other]
+}
+
+func (l Level) valid() bool {
+	_, ok := levelOrder[l]
+	return ok
+}
+
+// Rule gives the level of the requests it matches. An empty list matches
+// anything, "*" too.
+type Rule struct {
+	Level Level `yaml:"level"`
+	// Users and Groups match who sent the request
+	Users  []string `yaml:"users,omitempty"`
+	Groups []string `yaml:"groups,omitempty"`
+	// Verbs are those of RBAC, e.g. get, list, watch, create, update, delete
+	Verbs []string `yaml:"verbs,omitempty"`
+	// Kinds, e.g. ReplicaSet, only match the requests on objects. Requests on
+	// other urls, e.g. /reset, have no kind.
+	Kinds      []string `yaml:"kinds,omitempty"`
+	Namespaces []string `yaml:"namespaces,omitempty"`
+}
+
+// Policy selects what is recorded, by its first rule that matches a request.
+// A request no rule matches is not recorded. The records go to LogFile, which
+// is rotated once it grows over MaxSize megabytes, keeping MaxBackups former
+// files as LogFile.1, LogFile.2 and so on.
+type Policy struct {
+	LogFile    string `yaml:"logFile,omitempty"`
+	MaxSize    int    `yaml:"maxSize,omitempty"`
+	MaxBackups int    `yaml:"maxBackups,omitempty"`
+	Rules      []Rule `yaml:"rules"`
+}
+
+const (
+	defaultMaxSize    = 100
+	defaultMaxBackups = 5
+)
+
+// DefaultLogFile is where the records go unless the policy tells otherwise
+var DefaultLogFile = "/var/log/minik8s/audit.log"
+
+// defaultRules record who changed what, leaving out the reads, which the
+// components make all the time.
+var defaultRules = []Rule{
+	{Level: LevelNone, Verbs: []string{"get", "list", "watch"}},
+	{Level: LevelMetadata},
+}
+
+// PolicyFile is where the audit policy of the api-server is
+func PolicyFile() string {
+	return path.Join(global.CredentialsDir, "audit-policy.yaml")
+}
+
+// LoadPolicy reads the policy in file, or returns the default policy if there
+// is no such file.
+func LoadPolicy(file string) (*Policy, error) {
+	policy := &Policy{}
+	content, err := ioutil.ReadFile(file)
+	switch {
+	case os.IsNotExist(err):
+		policy.Rules = defaultRules
+	case err != nil:
+		return nil, err
+	default:
+		if err = yaml.Unmarshal(content, policy); err != nil {
+			return nil, err
+		}
+	}
+	if err = policy.complete(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (p *Policy) complete() error {
+	if p.LogFile == "" {
+		p.LogFile = DefaultLogFile
+	}
+	if p.MaxSize <= 0 {
+		p.MaxSize = defaultMaxSize
+	}
+	if p.MaxBackups < 0 {
+		p.MaxBackups = 0
+	} else if p.MaxBackups == 0 {
+		p.MaxBackups = defaultMaxBackups
+	}
+	for i := range p.Rules {
+		if !p.Rules[i].Level.valid() {
+			return fmt.Errorf("invalid audit level \"%s\", acceptable level is None, Metadata, Request or RequestResponse", p.Rules[i].Level)
+		}
+	}
+	return nil
+}
+
+// requestInfo is what the rules match a request by
+type requestInfo struct {
+	user      string
+	groups    []string
+	verb      string
+	kind      string
+	namespace string
+}
+
+// levelOf returns the level of the first rule that matches the request
+func (p *Policy) levelOf(info *requestInfo) Level {
+	for i := range p.Rules {
+		if p.Rules[i].matches(info) {
+			return p.Rules[i].Level
+		}
+	}
+	return LevelNone
+}
+
+func (r *Rule) matches(info *requestInfo) bool {
+	if len(r.Users) > 0 && !contains(r.Users, info.user) {
+		return false
+	}
+	if len(r.Groups) > 0 && !containsAny(r.Groups, info.groups) {
+		return false
+	}
+	if len(r.Verbs) > 0 && !contains(r.Verbs, info.verb) {
+		return false
+	}
+	if len(r.Kinds) > 0 && (info.kind == "" || !containsFold(r.Kinds, info.kind)) {
+		return false
+	}
+	if len(r.Namespaces) > 0 && !contains(r.Namespaces, info.namespace) {
+		return false
+	}
+	return true
+}
+
+func contains(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" || strings.EqualFold(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(patterns []string, values []string) bool {
+	for _, value := range values {
+		if contains(patterns, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// Megabyte is the unit of the MaxSize of a Policy
+const Megabyte = 1 << 20
+
+// FileWriter appends to a file, which it rotates before it grows over
+// maxSize bytes: the file becomes file.1, file.1 becomes file.2 and so on,
+// keeping maxBackups of them.
+type FileWriter struct {
+	lock       sync.Mutex
+	name       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// NewFileWriter opens name for appending, creating it and its directory if
+// needed.
+func NewFileWriter(name string, maxSize int64, maxBackups int) (*FileWriter, error) {
+	w := &FileWriter{name: name, maxSize: maxSize, maxBackups: maxBackups}
+	if err := os.MkdirAll(filepath.Dir(name), 0700); err != nil {
+		return nil, err
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *FileWriter) open() error {
+	file, err := os.OpenFile(w.name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	w.file, w.size = file, info.Size()
+	return nil
+}
+
+// Write writes p at once, so the records of concurrent requests do not mix
+func (w *FileWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *FileWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	_ = os.Remove(w.backupName(w.maxBackups))
+	for i := w.maxBackups - 1; i >= 1; i-- {
+		_ = os.Rename(w.backupName(i), w.backupName(i+1))
+	}
+	if w.maxBackups > 0 {
+		if err := os.Rename(w.name, w.backupName(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(w.name); err != nil {
+		return err
+	}
+	return w.open()
+}
+
+func (w *FileWriter) backupName(i int) string {
+	return w.name + "." + strconv.Itoa(i)
+}
+
+func (w *FileWriter) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.file.Close()
+}
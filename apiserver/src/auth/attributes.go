@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	VerbGet    = "get"
+	VerbList   = "list"
+	VerbWatch  = "watch"
+	VerbCreate = "create"
+	VerbUpdate = "update"
+	VerbDelete = "delete"
+)
+
+// Attributes describe a request to authorize. Resource requests are the ones
+// under /api/v1/<resource>/ and /apis/<group>/<version>/<resource>/, the rest
+// is only known by its Path.
+type Attributes struct {
+	Verb            string
+	ResourceRequest bool
+	Resource        string
+	Namespace       string
+	Name            string
+	Path            string
+}
+
+// AttributesFrom derives the attributes from the route that matched
+func AttributesFrom(c *gin.Context) *Attributes {
+	attrs := &Attributes{Path: c.Request.URL.Path}
+	route := c.FullPath()
+	switch {
+	case strings.HasPrefix(route, "/api/v1/"):
+		attrs.ResourceRequest = true
+		attrs.Resource = strings.SplitN(strings.TrimPrefix(route, "/api/v1/"), "/", 2)[0]
+		attrs.Namespace = c.Param("namespace")
+		attrs.Name = c.Param("name")
+	case strings.HasPrefix(route, "/apis/"):
+		attrs.ResourceRequest = true
+		attrs.Resource = c.Param("plural")
+		if trimmed := strings.Trim(c.Param("path"), "/"); trimmed != "" {
+			parts := strings.Split(trimmed, "/")
+			attrs.Name = parts[len(parts)-1]
+			if len(parts) > 1 {
+				attrs.Namespace = parts[0]
+			}
+		}
+	}
+
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead:
+		attrs.Verb = VerbGet
+		if watch, _ := strconv.ParseBool(c.Query("watch")); watch {
+			attrs.Verb = VerbWatch
+		} else if attrs.ResourceRequest && attrs.Name == "" {
+			attrs.Verb = VerbList
+		}
+	case http.MethodPost:
+		attrs.Verb = VerbCreate
+	case http.MethodPut, http.MethodPatch:
+		attrs.Verb = VerbUpdate
+	case http.MethodDelete:
+		attrs.Verb = VerbDelete
+	default:
+		attrs.Verb = strings.ToLower(c.Request.Method)
+	}
+	return attrs
+}
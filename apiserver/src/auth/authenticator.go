@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Authenticator tells who sent a request. It returns false if the request
+// carries no credentials it knows about, and an error if they are invalid.
+type Authenticator interface {
+	Authenticate(req *http.Request) (*User, bool, error)
+}
+
+// TokenAuthenticator checks the bearer token of a request against a file of
+// lines like token,user,uid,"group1,group2"
+type TokenAuthenticator struct {
+	tokens map[string]*User
+}
+
+func NewTokenAuthenticator(filePath string) (*TokenAuthenticator, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	a := &TokenAuthenticator{tokens: make(map[string]*User)}
+	for i, record := range records {
+		if len(record) < 2 || record[0] == "" || record[1] == "" {
+			return nil, fmt.Errorf("%s:%d: a token and a user are required", filePath, i+1)
+		}
+		user := &User{Name: record[1]}
+		if len(record) > 2 {
+			user.UID = record[2]
+		}
+		if len(record) > 3 && record[3] != "" {
+			user.Groups = strings.Split(record[3], ",")
+		}
+		a.tokens[record[0]] = user
+	}
+	return a, nil
+}
+
+func (a *TokenAuthenticator) Authenticate(req *http.Request) (*User, bool, error) {
+	header := req.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return nil, false, nil
+	}
+	if user, ok := a.tokens[strings.TrimPrefix(header, "Bearer ")]; ok {
+		return user, true, nil
+	}
+	return nil, false, fmt.Errorf("invalid bearer token")
+}
+
+// CertAuthenticator takes the user from the common name of a verified client
+// certificate, and the groups from its organizations.
+type CertAuthenticator struct{}
+
+func (a *CertAuthenticator) Authenticate(req *http.Request) (*User, bool, error) {
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+		return nil, false, nil
+	}
+	subject := req.TLS.VerifiedChains[0][0].Subject
+	if subject.CommonName == "" {
+		return nil, false, fmt.Errorf("the client certificate has no common name")
+	}
+	return &User{Name: subject.CommonName, Groups: subject.Organization}, true, nil
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"minik8s/global"
+	"minik8s/util/httputil"
+	"os"
+	"path"
+	"strings"
+)
+
+// identities are given a token when the api-server first starts. The
+// credentials of each are written to global.CredentialsDir/<file>.yaml, from
+// where the component, or kubectl for admin, reads them.
+var identities = []struct {
+	file   string
+	user   string
+	groups []string
+}{
+	{"admin", "admin", []string{GroupMasters}},
+	{"kube-scheduler", "system:kube-scheduler", []string{GroupComponents}},
+	{"kube-controller-manager", "system:kube-controller-manager", []string{GroupComponents}},
+	{"kubelet", "system:kubelet", []string{GroupComponents}},
+	{"serverless", "system:serverless", []string{GroupComponents}},
+}
+
+// TokenFile is where the tokens the api-server accepts are
+func TokenFile() string {
+	return path.Join(global.CredentialsDir, "tokens.csv")
+}
+
+// Bootstrap generates the token file and the credentials of the identities,
+// unless the token file already exists.
+func Bootstrap() error {
+	if _, err := os.Stat(TokenFile()); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(global.CredentialsDir, 0700); err != nil {
+		return err
+	}
+
+	var records [][]string
+	for _, identity := range identities {
+		token, err := newToken()
+		if err != nil {
+			return err
+		}
+		credsYaml, _ := yaml.Marshal(&httputil.Credentials{User: identity.user, Token: token})
+		if err = ioutil.WriteFile(httputil.CredentialsFile(identity.file), credsYaml, 0600); err != nil {
+			return err
+		}
+		records = append(records, []string{token, identity.user, identity.user, strings.Join(identity.groups, ",")})
+	}
+
+	file, err := os.OpenFile(TokenFile(), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	if err = writer.WriteAll(records); err != nil {
+		return err
+	}
+	log("generated the credentials in %s", global.CredentialsDir)
+	return nil
+}
+
+func newToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
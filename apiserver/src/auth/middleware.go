@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"minik8s/apiserver/src/registry"
+	"minik8s/util/httputil"
+	"minik8s/util/logger"
+)
+
+var log = logger.Log("Api-server")
+
+const userKey = "user"
+
+// Authenticate tries the authenticators in order and stores who sent the
+// request in the context. A request without credentials is anonymous.
+func Authenticate(authenticators ...Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, authenticator := range authenticators {
+			user, ok, err := authenticator.Authenticate(c.Request)
+			if err != nil {
+				registry.WriteError(c, httputil.NewUnauthorized(err.Error()))
+				c.Abort()
+				return
+			}
+			if ok {
+				c.Set(userKey, &User{
+					Name:   user.Name,
+					UID:    user.UID,
+					Groups: append(append([]string{}, user.Groups...), GroupAuthenticated),
+				})
+				return
+			}
+		}
+		c.Set(userKey, anonymous)
+	}
+}
+
+// UserFrom returns who sent the request, Authenticate must have run
+func UserFrom(c *gin.Context) *User {
+	if user, ok := c.Get(userKey); ok {
+		return user.(*User)
+	}
+	return anonymous
+}
+
+// Authorize rejects the requests the authorizer does not allow
+func Authorize(authorizer Authorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, attrs := UserFrom(c), AttributesFrom(c)
+		if authorizer.Authorize(user, attrs) {
+			return
+		}
+		var message string
+		if attrs.ResourceRequest {
+			message = fmt.Sprintf("user %s cannot %s %s", user.Name, attrs.Verb, attrs.Resource)
+			if attrs.Namespace != "" {
+				message += " in namespace " + attrs.Namespace
+			}
+		} else {
+			message = fmt.Sprintf("user %s cannot %s path %s", user.Name, attrs.Verb, attrs.Path)
+		}
+		log("%s", message)
+		registry.WriteError(c, httputil.NewForbidden(message))
+		c.Abort()
+	}
+}
@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/registry"
+	"sync"
+)
+
+// Authorizer decides whether user may do what attrs describe
+type Authorizer interface {
+	Authorize(user *User, attrs *Attributes) bool
+}
+
+// Policy is a snapshot of the RBAC objects
+type Policy struct {
+	Roles               []*apiObject.Role
+	ClusterRoles        []*apiObject.ClusterRole
+	RoleBindings        []*apiObject.RoleBinding
+	ClusterRoleBindings []*apiObject.ClusterRoleBinding
+}
+
+// Allows grants a request if a binding of the user allows it. The members of
+// system:masters are allowed everything, so the cluster cannot be locked out
+// by deleting its roles.
+func (p *Policy) Allows(user *User, attrs *Attributes) bool {
+	if user.InGroup(GroupMasters) {
+		return true
+	}
+	for _, binding := range p.ClusterRoleBindings {
+		if binds(binding.Subjects, user) && allows(p.clusterRoleRules(binding.RoleRef.Name), attrs) {
+			return true
+		}
+	}
+	if !attrs.ResourceRequest || attrs.Namespace == "" {
+		return false
+	}
+	for _, binding := range p.RoleBindings {
+		if binding.Metadata.Namespace != attrs.Namespace || !binds(binding.Subjects, user) {
+			continue
+		}
+		rules := p.roleRules(attrs.Namespace, binding.RoleRef.Name)
+		if binding.RoleRef.Kind == "ClusterRole" {
+			rules = p.clusterRoleRules(binding.RoleRef.Name)
+		}
+		if allows(rules, attrs) {
+			return true
+		}
+	}
+	return false
+}
+
+// RulesOf returns the rules of the role ref refers to, a Role of namespace or
+// a ClusterRole.
+func (p *Policy) RulesOf(namespace string, ref apiObject.RoleRef) []apiObject.PolicyRule {
+	if ref.Kind == "ClusterRole" {
+		return p.clusterRoleRules(ref.Name)
+	}
+	return p.roleRules(namespace, ref.Name)
+}
+
+// Covers tells whether user is allowed everything rules grant in namespace,
+// or in the whole cluster if it is "". A wildcard in rules is only covered
+// by the same wildcard, so that no one grants more than they have.
+func (p *Policy) Covers(user *User, namespace string, rules []apiObject.PolicyRule) bool {
+	for _, rule := range rules {
+		for _, verb := range rule.Verbs {
+			for _, nonResourceURL := range rule.NonResourceURLs {
+				if !p.Allows(user, &Attributes{Verb: verb, Path: nonResourceURL}) {
+					return false
+				}
+			}
+			names := rule.ResourceNames
+			if len(names) == 0 {
+				// every name, which only a rule naming none allows
+				names = []string{""}
+			}
+			for _, resource := range rule.Resources {
+				for _, name := range names {
+					attrs := &Attributes{Verb: verb, ResourceRequest: true, Resource: resource, Namespace: namespace, Name: name}
+					if !p.Allows(user, attrs) {
+						return false
+					}
+				}
+			}
+		}
+	}
+	return true
+}
+
+func (p *Policy) roleRules(namespace, name string) []apiObject.PolicyRule {
+	for _, role := range p.Roles {
+		if role.Metadata.Namespace == namespace && role.Metadata.Name == name {
+			return role.Rules
+		}
+	}
+	return nil
+}
+
+func (p *Policy) clusterRoleRules(name string) []apiObject.PolicyRule {
+	for _, role := range p.ClusterRoles {
+		if role.Metadata.Name == name {
+			return role.Rules
+		}
+	}
+	return nil
+}
+
+func binds(subjects []apiObject.Subject, user *User) bool {
+	for _, subject := range subjects {
+		switch subject.Kind {
+		case apiObject.SubjectUser:
+			if subject.Name == user.Name {
+				return true
+			}
+		case apiObject.SubjectGroup:
+			if user.InGroup(subject.Name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func allows(rules []apiObject.PolicyRule, attrs *Attributes) bool {
+	for i := range rules {
+		if attrs.ResourceRequest && rules[i].AllowsResource(attrs.Verb, attrs.Resource, attrs.Name) {
+			return true
+		}
+		if !attrs.ResourceRequest && rules[i].AllowsNonResourceURL(attrs.Verb, attrs.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// RBACAuthorizer checks every request against the RBAC objects in storage
+type RBACAuthorizer struct{}
+
+func (a *RBACAuthorizer) Authorize(user *User, attrs *Attributes) bool {
+	if user.InGroup(GroupMasters) {
+		return true
+	}
+	policy, err := LoadPolicy()
+	if err != nil {
+		log("load policy: %s", err.Error())
+		return false
+	}
+	return policy.Allows(user, attrs)
+}
+
+// policyCache keeps the policy between requests. generation tells a load
+// that the policy was invalidated while it read the RBAC objects, what it
+// read may then be stale.
+var policyCache struct {
+	sync.Mutex
+	policy     *Policy
+	generation int
+}
+
+// InvalidatePolicy drops the cached policy once an RBAC object is written, or
+// etcd is restored or reset; the next request reads it again.
+func InvalidatePolicy() {
+	policyCache.Lock()
+	defer policyCache.Unlock()
+	policyCache.policy = nil
+	policyCache.generation++
+}
+
+// LoadPolicy returns the RBAC objects in storage, as cached until
+// InvalidatePolicy. The policy is shared, it must not be changed.
+func LoadPolicy() (*Policy, error) {
+	policyCache.Lock()
+	policy, generation := policyCache.policy, policyCache.generation
+	policyCache.Unlock()
+	if policy != nil {
+		return policy, nil
+	}
+
+	policy, err := readPolicy()
+	if err != nil {
+		return nil, err
+	}
+	policyCache.Lock()
+	if policyCache.generation == generation {
+		policyCache.policy = policy
+	}
+	policyCache.Unlock()
+	return policy, nil
+}
+
+func readPolicy() (*Policy, error) {
+	policy := &Policy{}
+	for kindName, add := range map[string]func(obj apiObject.Object){
+		"Role": func(obj apiObject.Object) { policy.Roles = append(policy.Roles, obj.(*apiObject.Role)) },
+		"ClusterRole": func(obj apiObject.Object) {
+			policy.ClusterRoles = append(policy.ClusterRoles, obj.(*apiObject.ClusterRole))
+		},
+		"RoleBinding": func(obj apiObject.Object) {
+			policy.RoleBindings = append(policy.RoleBindings, obj.(*apiObject.RoleBinding))
+		},
+		"ClusterRoleBinding": func(obj apiObject.Object) {
+			policy.ClusterRoleBindings = append(policy.ClusterRoleBindings, obj.(*apiObject.ClusterRoleBinding))
+		},
+	} {
+		kind := registry.Lookup(kindName)
+		if kind == nil {
+			continue
+		}
+		objs, _, err := kind.List()
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			add(obj)
+		}
+	}
+	return policy, nil
+}
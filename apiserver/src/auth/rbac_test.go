@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"minik8s/apiObject"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testPolicy() *Policy {
+	podReader := &apiObject.Role{Rules: []apiObject.PolicyRule{{Verbs: []string{VerbGet, VerbList}, Resources: []string{"pods"}}}}
+	podReader.Metadata = apiObject.Metadata{Name: "pod-reader", Namespace: "test"}
+	readPods := &apiObject.RoleBinding{
+		Subjects: []apiObject.Subject{{Kind: apiObject.SubjectUser, Name: "alice"}},
+		RoleRef:  apiObject.RoleRef{Kind: "Role", Name: "pod-reader"},
+	}
+	readPods.Metadata = apiObject.Metadata{Name: "read-pods", Namespace: "test"}
+
+	component := &apiObject.ClusterRole{Rules: []apiObject.PolicyRule{
+		{Verbs: []string{"*"}, Resources: []string{"*"}},
+		{Verbs: []string{"*"}, NonResourceURLs: []string{"/generator/*"}},
+	}}
+	component.Metadata.Name = "system:component"
+	components := &apiObject.ClusterRoleBinding{
+		Subjects: []apiObject.Subject{{Kind: apiObject.SubjectGroup, Name: GroupComponents}},
+		RoleRef:  apiObject.RoleRef{Kind: "ClusterRole", Name: "system:component"},
+	}
+	return &Policy{
+		Roles:               []*apiObject.Role{podReader},
+		ClusterRoles:        []*apiObject.ClusterRole{component},
+		RoleBindings:        []*apiObject.RoleBinding{readPods},
+		ClusterRoleBindings: []*apiObject.ClusterRoleBinding{components},
+	}
+}
+
+func TestPolicyAllows(t *testing.T) {
+	policy := testPolicy()
+	alice := &User{Name: "alice"}
+	getPod := &Attributes{Verb: VerbGet, ResourceRequest: true, Resource: "pods", Namespace: "test", Name: "example"}
+	assert.True(t, policy.Allows(alice, getPod))
+
+	deletePod := *getPod
+	deletePod.Verb = VerbDelete
+	assert.False(t, policy.Allows(alice, &deletePod))
+
+	otherNamespace := *getPod
+	otherNamespace.Namespace = "default"
+	assert.False(t, policy.Allows(alice, &otherNamespace))
+
+	scheduler := &User{Name: "system:kube-scheduler", Groups: []string{GroupComponents}}
+	assert.True(t, policy.Allows(scheduler, &deletePod))
+	assert.True(t, policy.Allows(scheduler, &Attributes{Verb: VerbCreate, Path: "/generator/ip/pod"}))
+	assert.False(t, policy.Allows(scheduler, &Attributes{Verb: VerbCreate, Path: "/reset"}))
+
+	admin := &User{Name: "admin", Groups: []string{GroupMasters}}
+	assert.True(t, policy.Allows(admin, &Attributes{Verb: VerbCreate, Path: "/reset"}))
+}
+
+func TestPolicyCovers(t *testing.T) {
+	policy := testPolicy()
+	alice := &User{Name: "alice"}
+	readPods := []apiObject.PolicyRule{{Verbs: []string{VerbGet}, Resources: []string{"pods"}}}
+	assert.True(t, policy.Covers(alice, "test", readPods))
+	assert.False(t, policy.Covers(alice, "default", readPods))
+	assert.False(t, policy.Covers(alice, "test", []apiObject.PolicyRule{{Verbs: []string{"*"}, Resources: []string{"pods"}}}))
+
+	// a component may not hand out cluster-admin, whose non-resource urls it lacks
+	scheduler := &User{Name: "system:kube-scheduler", Groups: []string{GroupComponents}}
+	assert.True(t, policy.Covers(scheduler, "", policy.RulesOf("", apiObject.RoleRef{Kind: "ClusterRole", Name: "system:component"})))
+	clusterAdmin := []apiObject.PolicyRule{{Verbs: []string{"*"}, Resources: []string{"*"}}, {Verbs: []string{"*"}, NonResourceURLs: []string{"*"}}}
+	assert.False(t, policy.Covers(scheduler, "", clusterAdmin))
+}
+
+func TestAttributesFrom(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var attrs *Attributes
+	router := gin.New()
+	router.Use(func(c *gin.Context) { attrs = AttributesFrom(c) })
+	noop := func(c *gin.Context) {}
+	router.GET("/api/v1/pods/:namespace/:name", noop)
+	router.GET("/api/v1/nodes/", noop)
+	router.DELETE("/apis/:group/:version/:plural/*path", noop)
+	router.POST("/reset", noop)
+
+	serve := func(method, target string) *Attributes {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, target, nil))
+		return attrs
+	}
+	assert.Equal(t, &Attributes{Verb: VerbGet, ResourceRequest: true, Resource: "pods", Namespace: "test", Name: "example", Path: "/api/v1/pods/test/example"},
+		serve(http.MethodGet, "/api/v1/pods/test/example"))
+	assert.Equal(t, VerbList, serve(http.MethodGet, "/api/v1/nodes/").Verb)
+	assert.Equal(t, VerbWatch, serve(http.MethodGet, "/api/v1/nodes/?watch=true").Verb)
+	assert.Equal(t, &Attributes{Verb: VerbDelete, ResourceRequest: true, Resource: "crontabs", Namespace: "test", Name: "example", Path: "/apis/example.com/v1/crontabs/test/example"},
+		serve(http.MethodDelete, "/apis/example.com/v1/crontabs/test/example"))
+	assert.Equal(t, &Attributes{Verb: VerbCreate, Path: "/reset"}, serve(http.MethodPost, "/reset"))
+}
+
+func TestPolicyCache(t *testing.T) {
+	policy := testPolicy()
+	policyCache.policy = policy
+	defer InvalidatePolicy()
+
+	loaded, err := LoadPolicy()
+	assert.Nil(t, err)
+	assert.Same(t, policy, loaded)
+
+	InvalidatePolicy()
+	assert.Nil(t, policyCache.policy)
+}
@@ -0,0 +1,32 @@
+package auth
+
+const (
+	// GroupMasters may do anything, whatever the roles say
+	GroupMasters = "system:masters"
+	// GroupComponents holds the identities of the kubelet, the scheduler,
+	// the controller manager and the other components.
+	GroupComponents      = "system:components"
+	GroupAuthenticated   = "system:authenticated"
+	GroupUnauthenticated = "system:unauthenticated"
+	UserAnonymous        = "system:anonymous"
+	// NodeUserPrefix names the kubelets, system:node:<hostname>
+	NodeUserPrefix = "system:node:"
+)
+
+// User is who sent a request
+type User struct {
+	Name   string
+	UID    string
+	Groups []string
+}
+
+func (user *User) InGroup(group string) bool {
+	for _, g := range user.Groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+var anonymous = &User{Name: UserAnonymous, Groups: []string{GroupUnauthenticated}}
@@ -2,4 +2,9 @@ package contentType
 
 const (
 	Json = "application/json"
+	// The bodies of a PATCH request
+	MergePatch = "application/merge-patch+json"
+	JsonPatch  = "application/json-patch+json"
+	// ApplyPatch is a whole configuration, as kubectl apply sends it
+	ApplyPatch = "application/apply-patch+json"
 )
@@ -7,6 +7,7 @@ import (
 
 type Manager interface {
 	AddEntry(host, ip string) error
+	GetEntry(host string) (ip string, err error)
 	DeleteIfExistEntry(host string) error
 }
 
@@ -66,6 +67,15 @@ func (dm *dnsManager) AddEntry(host, ip string) error {
 	return dm.writeBack(mp)
 }
 
+// GetEntry returns the ip host resolves to, empty if there is no such host
+func (dm *dnsManager) GetEntry(host string) (string, error) {
+	mp, err := dm.getMapping()
+	if err != nil {
+		return "", err
+	}
+	return mp[host], nil
+}
+
 func (dm *dnsManager) DeleteIfExistEntry(host string) error {
 	mp, err := dm.getMapping()
 	if err != nil {
@@ -0,0 +1,49 @@
+package etcd
+
+import (
+	"fmt"
+	"go.etcd.io/etcd/embed"
+	"log"
+	"minik8s/util/configutil"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const embeddedStartTimeout = time.Minute
+
+// StartEmbedded runs an etcd server in this process instead of the container
+// of Start, with its data in dataDir. It listens on the first etcd endpoint of
+// the config and returns once it is ready to serve.
+func StartEmbedded(dataDir string) (*embed.Etcd, error) {
+	endpoint := configutil.Current().EtcdEndpoints[0]
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	clientURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := embed.NewConfig()
+	cfg.Dir = dataDir
+	cfg.LCUrls = []url.URL{*clientURL}
+	cfg.ACUrls = []url.URL{*clientURL}
+	cfg.MaxRequestBytes = maxRequestBytes
+	cfg.MaxTxnOps = maxTxnOps
+	server, err := embed.StartEtcd(cfg)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case <-server.Server.ReadyNotify():
+		log.Printf("[etcd] Embedded etcd ready, data dir: %s\n", dataDir)
+		return server, nil
+	case err = <-server.Err():
+		server.Close()
+		return nil, err
+	case <-time.After(embeddedStartTimeout):
+		server.Close()
+		return nil, fmt.Errorf("the embedded etcd is not ready after %v", embeddedStartTimeout)
+	}
+}
@@ -2,11 +2,29 @@ package etcd
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/etcdserver/api/v3rpc/rpctypes"
 	"log"
+	"minik8s/util/configutil"
 	"time"
 )
 
+// ErrConflict is returned by the compare-and-swap operations when the key
+// has been modified since the given revision was read.
+var ErrConflict = errors.New("the object has been modified; please apply your changes to the latest version and try again")
+
+// ErrCompacted is returned when reading at a revision etcd has compacted
+var ErrCompacted = errors.New("the revision has been compacted")
+
+// maxRequestBytes and maxTxnOps bound a transaction, both in the client and
+// in the etcd server, which is started with them.
+const (
+	maxRequestBytes = 64 << 20
+	maxTxnOps       = 100000
+)
+
 var (
 	ctx    = context.Background()
 	config clientv3.Config
@@ -16,15 +34,17 @@ var (
 )
 
 func startClient() (err error) {
+	config.Endpoints = configutil.Current().EtcdEndpoints
 	cli, err = clientv3.New(config)
 	return
 }
 
 func init() {
 	config = clientv3.Config{
-		Endpoints:            []string{"localhost:2379"},
 		DialTimeout:          30 * time.Second,
 		DialKeepAliveTimeout: 30 * time.Second,
+		// A restore writes the whole key space in one request
+		MaxCallSendMsgSize: maxRequestBytes,
 	}
 }
 
@@ -98,3 +118,170 @@ func GetAll(keyPrefix string) (values []string, err error) {
 	}
 	return values, nil
 }
+
+// GetWithRevision is like Get, but also returns the mod revision of the key.
+// A revision of 0 means the key does not exist.
+func GetWithRevision(key string) (value string, revision int64, err error) {
+	if err = checkAndStartClient(); err != nil {
+		return "", 0, err
+	}
+	var resp *clientv3.GetResponse
+	resp, err = cli.Get(ctx, key)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if len(resp.Kvs) > 0 {
+		return string(resp.Kvs[0].Value), resp.Kvs[0].ModRevision, nil
+	} else {
+		return "", 0, nil
+	}
+}
+
+// CompareAndPut puts value to key only if the mod revision of key still
+// equals revision, and returns the revision of the write. A revision of 0
+// means the key must not exist yet. ErrConflict is returned if the
+// comparison fails.
+func CompareAndPut(key, value string, revision int64) (newRevision int64, err error) {
+	if err = checkAndStartClient(); err != nil {
+		return 0, err
+	}
+	var resp *clientv3.TxnResponse
+	resp, err = cli.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(key), "=", revision)).
+		Then(clientv3.OpPut(key, value)).
+		Commit()
+	if err != nil {
+		return 0, err
+	}
+	if !resp.Succeeded {
+		return 0, ErrConflict
+	}
+	return resp.Header.Revision, nil
+}
+
+// CompareAndDelete deletes key only if its mod revision still equals revision.
+func CompareAndDelete(key string, revision int64) (err error) {
+	if err = checkAndStartClient(); err != nil {
+		return err
+	}
+	var resp *clientv3.TxnResponse
+	resp, err = cli.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(key), "=", revision)).
+		Then(clientv3.OpDelete(key)).
+		Commit()
+	if err != nil {
+		return err
+	}
+	if !resp.Succeeded {
+		return ErrConflict
+	}
+	return nil
+}
+
+// KeyValue is a key read by List, with the revision it was last modified at
+type KeyValue struct {
+	Key         string
+	Value       string
+	ModRevision int64
+}
+
+// List returns all the keys with the given prefix, and the revision of the
+// key space the result is consistent with.
+func List(keyPrefix string) (kvs []KeyValue, revision int64, err error) {
+	if err = checkAndStartClient(); err != nil {
+		return nil, 0, err
+	}
+	var resp *clientv3.GetResponse
+	resp, err = cli.Get(ctx, keyPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, kv := range resp.Kvs {
+		kvs = append(kvs, KeyValue{
+			Key:         string(kv.Key),
+			Value:       string(kv.Value),
+			ModRevision: kv.ModRevision,
+		})
+	}
+	return kvs, resp.Header.Revision, nil
+}
+
+// ListPage returns at most limit keys with the given prefix, from key start
+// on, as of revision, 0 meaning the latest one. A limit of 0 means no limit.
+// more tells whether keys are left after the page, to be read from the key
+// following the last one at the same revision. ErrCompacted is returned if
+// that revision is gone.
+func ListPage(keyPrefix, start string, revision, limit int64) (kvs []KeyValue, readRevision int64, more bool, err error) {
+	if err = checkAndStartClient(); err != nil {
+		return nil, 0, false, err
+	}
+	opts := []clientv3.OpOption{
+		clientv3.WithRange(clientv3.GetPrefixRangeEnd(keyPrefix)),
+		clientv3.WithLimit(limit),
+	}
+	if revision > 0 {
+		opts = append(opts, clientv3.WithRev(revision))
+	}
+	var resp *clientv3.GetResponse
+	resp, err = cli.Get(ctx, start, opts...)
+	if err == rpctypes.ErrCompacted {
+		return nil, 0, false, ErrCompacted
+	} else if err != nil {
+		return nil, 0, false, err
+	}
+
+	for _, kv := range resp.Kvs {
+		kvs = append(kvs, KeyValue{
+			Key:         string(kv.Key),
+			Value:       string(kv.Value),
+			ModRevision: kv.ModRevision,
+		})
+	}
+	// The header holds the latest revision, not the one read at
+	if readRevision = revision; readRevision == 0 {
+		readRevision = resp.Header.Revision
+	}
+	return kvs, readRevision, resp.More, nil
+}
+
+// Snapshot returns every key, consistent with the returned revision
+func Snapshot() (kvs []KeyValue, revision int64, err error) {
+	return List("")
+}
+
+// Restore replaces the whole key space by kvs in a single transaction, so
+// either all of them are restored or nothing changes. The keys not in kvs are
+// deleted.
+func Restore(kvs []KeyValue) error {
+	current, _, err := List("")
+	if err != nil {
+		return err
+	}
+	restored := make(map[string]bool, len(kvs))
+	for _, kv := range kvs {
+		restored[kv.Key] = true
+	}
+
+	// etcd refuses a transaction that both deletes and puts a key
+	var ops []clientv3.Op
+	for _, kv := range current {
+		if !restored[kv.Key] {
+			ops = append(ops, clientv3.OpDelete(kv.Key))
+		}
+	}
+	for _, kv := range kvs {
+		ops = append(ops, clientv3.OpPut(kv.Key, kv.Value))
+	}
+	if len(ops) > maxTxnOps {
+		return fmt.Errorf("restoring takes %d operations, more than the %d of a transaction", len(ops), maxTxnOps)
+	}
+	_, err = cli.Txn(ctx).Then(ops...).Commit()
+	if err == rpctypes.ErrTooManyOps || err == rpctypes.ErrRequestTooLarge {
+		return fmt.Errorf("restoring takes %d operations, more than the etcd server takes in a transaction (%s). "+
+			"A container %s created before its limits were raised keeps the defaults of etcd, remove it "+
+			"and restart the api server to have it recreated, then restore", len(ops), err.Error(), serverName)
+	}
+	return err
+}
@@ -8,6 +8,8 @@ import (
 	"minik8s/kubelet/src/runtime/container"
 	"minik8s/kubelet/src/runtime/docker"
 	"minik8s/kubelet/src/runtime/image"
+	"strconv"
+	"strings"
 )
 
 const etcdImage = "bitnami/etcd:3.5"
@@ -49,8 +51,13 @@ func createContainer() string {
 		Image:      etcdImage,
 		Entrypoint: nil,
 		Cmd:        nil,
-		Env:        []string{"ALLOW_NONE_AUTHENTICATION=yes", "ETCD_ADVERTISE_CLIENT_URLS=http://etcd-server:2379"},
-		Volumes:    nil,
+		Env: []string{
+			"ALLOW_NONE_AUTHENTICATION=yes",
+			"ETCD_ADVERTISE_CLIENT_URLS=http://etcd-server:2379",
+			"ETCD_MAX_REQUEST_BYTES=" + strconv.Itoa(maxRequestBytes),
+			"ETCD_MAX_TXN_OPS=" + strconv.Itoa(maxTxnOps),
+		},
+		Volumes: nil,
 		ExposedPorts: nat.PortSet{
 			"2379/tcp": {},
 		},
@@ -75,6 +82,22 @@ func createContainer() string {
 	return ID
 }
 
+// checkLimits warns if the container was created before it was given the
+// transaction limits a restore needs, which only apply to a new container.
+func checkLimits(ID string) {
+	info, err := cm.InspectContainer(ID)
+	if err != nil || info.Config == nil {
+		return
+	}
+	for _, env := range info.Config.Env {
+		if strings.HasPrefix(env, "ETCD_MAX_TXN_OPS=") {
+			return
+		}
+	}
+	log.Printf("[etcd] Container %s keeps the transaction limits of etcd, a restore of more than 128 keys fails. "+
+		"Remove it and restart to have it recreated\n", serverName)
+}
+
 func startContainer(ID string) {
 	err := cm.StartContainer(ID, &container.StartConfig{})
 	if err != nil {
@@ -88,7 +111,7 @@ func Start() {
 	ID, State := findContainer()
 	if ID != "" {
 		log.Printf("[etcd] Find etcd container, ID: %s, State: %s\n", ID, State)
-
+		checkLimits(ID)
 	} else {
 		ID = createContainer()
 		log.Printf("[etcd] Create Container, ID: %s\n", ID)
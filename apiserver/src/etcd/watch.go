@@ -0,0 +1,110 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"go.etcd.io/etcd/clientv3"
+)
+
+type EventType string
+
+const (
+	Added    EventType = "ADDED"
+	Modified EventType = "MODIFIED"
+	Deleted  EventType = "DELETED"
+	Error    EventType = "ERROR"
+)
+
+type WatchEvent struct {
+	Type  EventType
+	Key   string
+	Value string
+	// PrevValue is the value before a Modified event
+	PrevValue string
+	Revision  int64
+	Err       error
+}
+
+// CurrentRevision returns the latest revision of the whole key space.
+func CurrentRevision() (int64, error) {
+	if err := checkAndStartClient(); err != nil {
+		return 0, err
+	}
+	resp, err := cli.Get(ctx, "/", clientv3.WithCountOnly())
+	if err != nil {
+		return 0, err
+	}
+	return resp.Header.Revision, nil
+}
+
+// Watch watches all the keys with the given prefix, starting right after
+// revision, so a client that has listed at revision N misses nothing by
+// watching from N. A revision of 0 means watching from now on. The returned
+// channel is closed when ctx is done; if the watch fails (e.g. the revision
+// has been compacted), an Error event is sent before closing.
+func Watch(watchCtx context.Context, prefix string, revision int64) <-chan WatchEvent {
+	events := make(chan WatchEvent)
+	go func() {
+		defer close(events)
+		if err := checkAndStartClient(); err != nil {
+			events <- WatchEvent{Type: Error, Err: err}
+			return
+		}
+
+		opts := []clientv3.OpOption{clientv3.WithPrefix(), clientv3.WithPrevKV()}
+		if revision > 0 {
+			opts = append(opts, clientv3.WithRev(revision+1))
+		}
+		watchCtx, cancel := context.WithCancel(watchCtx)
+		defer cancel()
+		for resp := range cli.Watch(clientv3.WithRequireLeader(watchCtx), prefix, opts...) {
+			if resp.CompactRevision != 0 {
+				err := fmt.Errorf("revision %d has been compacted, the oldest available is %d", revision, resp.CompactRevision)
+				sendEvent(watchCtx, events, WatchEvent{Type: Error, Err: err})
+				return
+			}
+			if err := resp.Err(); err != nil {
+				sendEvent(watchCtx, events, WatchEvent{Type: Error, Err: err})
+				return
+			}
+			for _, ev := range resp.Events {
+				if !sendEvent(watchCtx, events, parseEvent(ev)) {
+					return
+				}
+			}
+		}
+	}()
+	return events
+}
+
+func parseEvent(ev *clientv3.Event) WatchEvent {
+	event := WatchEvent{
+		Key:      string(ev.Kv.Key),
+		Value:    string(ev.Kv.Value),
+		Revision: ev.Kv.ModRevision,
+	}
+	switch {
+	case ev.Type == clientv3.EventTypeDelete:
+		event.Type = Deleted
+		if ev.PrevKv != nil {
+			event.Value = string(ev.PrevKv.Value)
+		}
+	case ev.IsCreate():
+		event.Type = Added
+	default:
+		event.Type = Modified
+		if ev.PrevKv != nil {
+			event.PrevValue = string(ev.PrevKv.Value)
+		}
+	}
+	return event
+}
+
+func sendEvent(watchCtx context.Context, events chan<- WatchEvent, event WatchEvent) bool {
+	select {
+	case events <- event:
+		return true
+	case <-watchCtx.Done():
+		return false
+	}
+}
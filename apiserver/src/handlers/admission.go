@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"fmt"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/registry"
+	"minik8s/util/schemautil"
+	"strings"
+)
+
+// The built-in admission plugins, the webhooks are in webhook.go
+
+var namespaceDefaultPlugin = &registry.AdmissionPlugin{
+	Name: "NamespaceDefault",
+	Mutate: func(attrs *registry.AdmissionAttributes) error {
+		if metadata := attrs.Object.Meta(); attrs.Namespaced && metadata.Namespace == "" {
+			metadata.Namespace = apiObject.DefaultNamespace
+		}
+		return nil
+	},
+}
+
+var namespaceLifecyclePlugin = &registry.AdmissionPlugin{
+	Name: "NamespaceLifecycle",
+	Validate: func(attrs *registry.AdmissionAttributes) error {
+		if attrs.Namespaced && attrs.Operation == registry.OperationCreate {
+			return registry.CheckNamespace(attrs.Object.Meta().Namespace)
+		}
+		return nil
+	},
+}
+
+var imagePullPolicyPlugin = &registry.AdmissionPlugin{
+	Name: "ImagePullPolicy",
+	Mutate: func(attrs *registry.AdmissionAttributes) error {
+		for _, spec := range podSpecsOf(attrs.Object) {
+			for i := range spec.Containers {
+				container := &spec.Containers[i]
+				if container.ImagePullPolicy == "" {
+					container.ImagePullPolicy = defaultImagePullPolicy(container.Image)
+				}
+			}
+		}
+		return nil
+	},
+}
+
+// nameFormats are the formats of the names of the kinds whose names end up
+// in host names, e.g. the dns names of the services.
+var nameFormats = map[string]string{
+	"Namespace":               schemautil.FormatDNS1123Label,
+	"Service":                 schemautil.FormatDNS1123Label,
+	"Pod":                     schemautil.FormatDNS1123Subdomain,
+	"ReplicaSet":              schemautil.FormatDNS1123Subdomain,
+	"Deployment":              schemautil.FormatDNS1123Subdomain,
+	"HorizontalPodAutoscaler": schemautil.FormatDNS1123Subdomain,
+	"Dns":                     schemautil.FormatDNS1123Subdomain,
+	"GpuJob":                  schemautil.FormatDNS1123Subdomain,
+}
+
+// schemaPlugin validates every object against the schema generated from its
+// type, reporting all the invalid fields at once. An update is only rejected
+// for the fields it makes invalid, not for those the stored object has
+// invalid already, so that e.g. the garbage collector can still remove the
+// finalizers of an object created before the schemas.
+var schemaPlugin = &registry.AdmissionPlugin{
+	Name: "Schema",
+	Validate: func(attrs *registry.AdmissionAttributes) error {
+		if attrs.Operation == registry.OperationUpdate && attrs.OldObject != nil {
+			return registry.ValidateSchemaUpdate(attrs.Kind, attrs.Object, attrs.OldObject, nameFormats[attrs.Kind])
+		}
+		return registry.ValidateSchema(attrs.Kind, attrs.Object, nameFormats[attrs.Kind])
+	},
+}
+
+var requiredFieldsPlugin = &registry.AdmissionPlugin{
+	Name: "RequiredFields",
+	Validate: func(attrs *registry.AdmissionAttributes) error {
+		metadata := attrs.Object.Meta()
+		if metadata.Name == "" {
+			return fmt.Errorf("metadata.name is required")
+		}
+		if strings.Contains(metadata.Name, "/") || strings.Contains(metadata.Namespace, "/") {
+			return fmt.Errorf("metadata.name and metadata.namespace must not contain '/'")
+		}
+
+		for _, spec := range podSpecsOf(attrs.Object) {
+			if err := validatePodSpec(spec); err != nil {
+				return err
+			}
+		}
+		switch obj := attrs.Object.(type) {
+		case *apiObject.ReplicaSet:
+			if obj.Replicas() < 0 {
+				return fmt.Errorf("spec.replicas must not be negative")
+			}
+		case *apiObject.Deployment:
+			if obj.Replicas() < 0 {
+				return fmt.Errorf("spec.replicas must not be negative")
+			}
+			if strategy := obj.Spec.Strategy.Type; strategy != apiObject.RollingUpdateDeploymentStrategyType &&
+				strategy != apiObject.RecreateDeploymentStrategyType {
+				return fmt.Errorf("spec.strategy.type must be %s or %s", apiObject.RollingUpdateDeploymentStrategyType, apiObject.RecreateDeploymentStrategyType)
+			}
+			if _, _, err := obj.MaxSurgeAndUnavailable(); err != nil {
+				return fmt.Errorf("spec.strategy.rollingUpdate: %s", err.Error())
+			}
+			if obj.RevisionHistoryLimit() < 0 {
+				return fmt.Errorf("spec.revisionHistoryLimit must not be negative")
+			}
+		case *apiObject.HorizontalPodAutoscaler:
+			if obj.MinReplicas() > obj.MaxReplicas() {
+				return fmt.Errorf("spec.minReplicas must not be greater than spec.maxReplicas")
+			}
+		}
+		return nil
+	},
+}
+
+// registerAdmissionPlugins builds the admission chain, the mutating plugins
+// and the validating plugins each run in this order.
+func registerAdmissionPlugins() {
+	for _, plugin := range []*registry.AdmissionPlugin{
+		namespaceDefaultPlugin,
+		imagePullPolicyPlugin,
+		mutatingWebhookPlugin,
+		namespaceLifecyclePlugin,
+		schemaPlugin,
+		requiredFieldsPlugin,
+		validatingWebhookPlugin,
+	} {
+		registry.RegisterAdmissionPlugin(plugin)
+	}
+}
+
+// podSpecsOf returns the pod specs in obj, which the plugins about containers
+// look at.
+func podSpecsOf(obj apiObject.Object) []*apiObject.PodSpec {
+	switch obj := obj.(type) {
+	case *apiObject.Pod:
+		return []*apiObject.PodSpec{&obj.Spec}
+	case *apiObject.ReplicaSet:
+		return []*apiObject.PodSpec{&obj.Spec.Template.Spec}
+	case *apiObject.Deployment:
+		return []*apiObject.PodSpec{&obj.Spec.Template.Spec}
+	}
+	return nil
+}
+
+func validatePodSpec(spec *apiObject.PodSpec) error {
+	if len(spec.Containers) == 0 {
+		return fmt.Errorf("spec.containers must not be empty")
+	}
+	names := make(map[string]bool)
+	for i, container := range spec.Containers {
+		if container.Name == "" || container.Image == "" {
+			return fmt.Errorf("spec.containers[%d]: name and image are required", i)
+		}
+		if names[container.Name] {
+			return fmt.Errorf("spec.containers[%d]: duplicate name %s", i, container.Name)
+		}
+		names[container.Name] = true
+	}
+	return nil
+}
+
+// defaultImagePullPolicy pulls the images without a tag or tagged latest
+// every time, since they may have changed.
+func defaultImagePullPolicy(image string) string {
+	if strings.Contains(image, "@") {
+		return apiObject.PullPolicyIfNotPresent
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.LastIndex(name, ":"); i < 0 || name[i+1:] == "latest" {
+		return apiObject.PullPolicyAlways
+	}
+	return apiObject.PullPolicyIfNotPresent
+}
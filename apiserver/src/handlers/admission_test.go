@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/registry"
+	"minik8s/entity"
+	"minik8s/util/httputil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDefaultImagePullPolicy(t *testing.T) {
+	assert.Equal(t, apiObject.PullPolicyAlways, defaultImagePullPolicy("nginx"))
+	assert.Equal(t, apiObject.PullPolicyAlways, defaultImagePullPolicy("nginx:latest"))
+	assert.Equal(t, apiObject.PullPolicyIfNotPresent, defaultImagePullPolicy("nginx:1.21"))
+	assert.Equal(t, apiObject.PullPolicyAlways, defaultImagePullPolicy("localhost:5000/nginx"))
+	assert.Equal(t, apiObject.PullPolicyIfNotPresent, defaultImagePullPolicy("nginx@sha256:0d17b565"))
+}
+
+func TestRequiredFields(t *testing.T) {
+	pod := &apiObject.Pod{}
+	pod.Metadata.Name = "example"
+	attrs := &registry.AdmissionAttributes{Operation: registry.OperationCreate, Kind: "Pod", Namespaced: true, Object: pod}
+	assert.NotNil(t, requiredFieldsPlugin.Validate(attrs))
+
+	pod.Spec.Containers = []apiObject.Container{{Name: "nginx", Image: "nginx"}}
+	assert.Nil(t, requiredFieldsPlugin.Validate(attrs))
+
+	pod.Spec.Containers = append(pod.Spec.Containers, apiObject.Container{Name: "nginx", Image: "nginx"})
+	assert.NotNil(t, requiredFieldsPlugin.Validate(attrs))
+}
+
+func TestSchema(t *testing.T) {
+	pod := &apiObject.Pod{}
+	pod.Metadata.Name = "Example"
+	pod.Spec.RestartPolicy = "Sometimes"
+	pod.Spec.Containers = []apiObject.Container{{
+		Name:  "nginx",
+		Ports: []apiObject.ContainerPort{{ContainerPort: "80", Protocol: "TCP"}, {ContainerPort: "http"}},
+	}}
+	attrs := &registry.AdmissionAttributes{Operation: registry.OperationCreate, Kind: "Pod", Namespaced: true, Object: pod}
+	err := schemaPlugin.Validate(attrs)
+	assert.True(t, httputil.IsInvalid(err))
+
+	var fields []string
+	for _, cause := range httputil.StatusOf(err).Details.Causes {
+		fields = append(fields, cause.Field)
+	}
+	assert.ElementsMatch(t, []string{
+		"metadata.name",
+		"spec.restartPolicy",
+		"spec.containers[0].image",
+		"spec.containers[0].ports[1].containerPort",
+	}, fields)
+
+	pod.Metadata.Name = "example"
+	pod.Spec.RestartPolicy = "Always"
+	pod.Spec.Containers[0].Image = "nginx"
+	pod.Spec.Containers[0].Ports = pod.Spec.Containers[0].Ports[:1]
+	assert.Nil(t, schemaPlugin.Validate(attrs))
+
+	// an update keeps the invalid name and image of a legacy pod
+	old := &apiObject.Pod{}
+	old.Metadata.Name = "Legacy"
+	old.Spec.Containers = []apiObject.Container{{Name: "nginx"}}
+	updated := &apiObject.Pod{}
+	updated.Metadata = old.Metadata
+	updated.Metadata.Finalizers = []string{"example.com/cleanup"}
+	updated.Spec.Containers = []apiObject.Container{{Name: "nginx"}}
+	attrs = &registry.AdmissionAttributes{Operation: registry.OperationUpdate, Kind: "Pod", Namespaced: true, Object: updated, OldObject: old}
+	assert.Nil(t, schemaPlugin.Validate(attrs))
+
+	updated.Spec.RestartPolicy = "Sometimes"
+	err = schemaPlugin.Validate(attrs)
+	assert.True(t, httputil.IsInvalid(err))
+	causes := httputil.StatusOf(err).Details.Causes
+	assert.Len(t, causes, 1)
+	assert.Equal(t, "spec.restartPolicy", causes[0].Field)
+}
+
+func TestCallWebhook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		review := entity.AdmissionReview{}
+		_ = json.NewDecoder(r.Body).Decode(&review)
+		pod := apiObject.Pod{}
+		_ = json.Unmarshal(review.Request.Object, &pod)
+		response := &entity.AdmissionResponse{UID: review.Request.UID, Allowed: pod.Metadata.Labels["team"] != ""}
+		if response.Allowed {
+			pod.Metadata.Name = "renamed"
+			pod.Spec.RestartPolicy = "Always"
+			response.Object, _ = json.Marshal(pod)
+		} else {
+			response.Message = "every pod needs a team label"
+		}
+		_ = json.NewEncoder(w).Encode(entity.AdmissionReview{Response: response})
+	}))
+	defer server.Close()
+
+	webhook := &apiObject.Webhook{Name: "team.example.com", URL: server.URL}
+	pod := &apiObject.Pod{}
+	pod.Metadata.Name = "example"
+	attrs := &registry.AdmissionAttributes{Operation: registry.OperationCreate, Kind: "Pod", Namespaced: true, Object: pod}
+
+	err := callWebhook(webhook, attrs)
+	assert.True(t, isDenied(err))
+	assert.Contains(t, err.Error(), "team label")
+
+	pod.Metadata.Labels = apiObject.Labels{"team": "infra"}
+	assert.Nil(t, callWebhook(webhook, attrs))
+	assert.Equal(t, "Always", pod.Spec.RestartPolicy)
+	// the webhook cannot rename the object
+	assert.Equal(t, "example", pod.Metadata.Name)
+}
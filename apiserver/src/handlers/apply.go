@@ -1,409 +1,198 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
-	"fmt"
 	"github.com/gin-gonic/gin"
+	"io/ioutil"
 	"minik8s/apiObject"
-	dns2 "minik8s/apiserver/src/dns"
-	"minik8s/apiserver/src/etcd"
+	"minik8s/apiserver/src/contentType"
 	"minik8s/apiserver/src/helper"
+	"minik8s/apiserver/src/registry"
 	"minik8s/apiserver/src/url"
 	"minik8s/entity"
 	"minik8s/listwatch"
-	"minik8s/nginx"
 	"minik8s/util/httputil"
 	"minik8s/util/logger"
 	"minik8s/util/topicutil"
 	"minik8s/util/uidutil"
-	"minik8s/util/weaveutil"
 	"net/http"
 	"path"
-	"time"
 )
 
 var log = logger.Log("Api-server")
 
-func HandleApplyNode(c *gin.Context) {
-	node := apiObject.Node{}
-	err := httputil.ReadAndUnmarshal(c.Request.Body, &node)
+// createPod admits a new pod and hands it to the scheduler, which stores it
+// once it has picked a node.
+func createPod(pod *apiObject.Pod) (err error) {
+	// Pods are not stored through the registry, but are admitted all the same
+	err = registry.Admit(&registry.AdmissionAttributes{
+		Operation:  registry.OperationCreate,
+		Kind:       "Pod",
+		Namespaced: true,
+		Object:     pod,
+	}, nil)
 	if err != nil {
-		c.String(http.StatusOK, err.Error())
-		return
-	}
-	node.Metadata.UID = uidutil.New()
-	log("receive node[ID = %v]: %v", node.UID(), node)
-
-	var nodeJson []byte
-	if nodeJson, err = json.Marshal(node); err != nil {
-		c.String(http.StatusOK, err.Error())
-		return
-	}
-
-	// exists?
-	etcdNodeURL := path.Join(url.NodeURL, node.Namespace(), node.Name())
-	if podJsonStr, err := etcd.Get(etcdNodeURL); err == nil {
-		getNode := &apiObject.Node{}
-		if err = json.Unmarshal([]byte(podJsonStr), getNode); err == nil {
-			c.String(http.StatusOK, fmt.Sprintf("Node %s/%s already exists", getNode.Namespace(), getNode.Name()))
-			return
-		}
-	}
-
-	if err = etcd.Put(etcdNodeURL, string(nodeJson)); err != nil {
-		c.String(http.StatusOK, err.Error())
-		return
-	}
-
-	etcdNodeStatusURL := path.Join(url.NodeURL, "status", node.Namespace(), node.Name())
-	var nodeStatusJson []byte
-	if nodeStatusJson, err = json.Marshal(entity.NodeStatus{
-		Hostname:   node.Name(),
-		Ip:         node.Ip,
-		Labels:     node.Labels(),
-		Lifecycle:  entity.NodeUnknown,
-		Error:      "",
-		CpuPercent: 0,
-		MemPercent: 0,
-		NumPods:    0,
-		SyncTime:   time.Now(),
-	}); err == nil {
-		_ = etcd.Put(etcdNodeStatusURL, string(nodeStatusJson))
-	}
-
-	c.String(http.StatusOK, "ok")
-}
-
-func HandleApplyPod(c *gin.Context) {
-	pod := apiObject.Pod{}
-	err := httputil.ReadAndUnmarshal(c.Request.Body, &pod)
-	if err != nil {
-		c.String(http.StatusOK, err.Error())
-		return
+		return err
 	}
 
 	if helper.ExistsPod(pod.Namespace(), pod.Name()) {
-		c.String(http.StatusOK, fmt.Sprintf("pod %s/%s already exists", pod.Namespace(), pod.Name()))
-		return
+		return registry.NewAlreadyExists("Pod", pod.Namespace(), pod.Name())
 	}
 
 	pod.Metadata.UID = uidutil.New()
 	if pod.Spec.ClusterIp, err = helper.NewPodIp(); err != nil {
-		c.String(http.StatusOK, err.Error())
-		return
+		return err
 	}
-	log("receive pod %s/%s[ID = %v] %+v", pod.Namespace(), pod.Name(), pod.UID(), pod)
+	log("receive pod %s/%s[ID = %v] %+v", pod.Namespace(), pod.Name(), pod.UID(), *pod)
 
 	// Schedule first, then put the data to url: PodURL/node/namespace/name
 	podUpdateMsg, _ := json.Marshal(entity.PodUpdate{
 		Action: entity.CreateAction,
-		Target: pod,
+		Target: *pod,
 	})
 
 	listwatch.Publish(topicutil.SchedulerPodUpdateTopic(), podUpdateMsg)
-	c.String(http.StatusOK, "ok")
+	return nil
 }
 
-func HandleApplyReplicaSet(c *gin.Context) {
-	rs := apiObject.ReplicaSet{}
-	err := httputil.ReadAndUnmarshal(c.Request.Body, &rs)
-	if err != nil {
-		c.String(http.StatusOK, err.Error())
+func HandleApplyPod(c *gin.Context) {
+	pod := apiObject.Pod{}
+	if err := registry.DecodeBody(c, "Pod", &pod); err != nil {
+		registry.WriteError(c, err)
 		return
 	}
-	rs.Metadata.UID = uidutil.New()
-	log("receive rs[ID = %v]: %v", rs.UID(), rs)
 
-	var rsJson []byte
-	if rsJson, err = json.Marshal(rs); err != nil {
-		c.String(http.StatusOK, err.Error())
+	if err := createPod(&pod); err != nil {
+		registry.WriteError(c, err)
 		return
 	}
+	c.String(http.StatusOK, "ok")
+}
 
-	// exists?
-	etcdReplicaSetURL := path.Join(url.ReplicaSetURL, rs.Namespace(), rs.Name())
-	if rsJsonStr, err := etcd.Get(etcdReplicaSetURL); err == nil {
-		getRs := &apiObject.ReplicaSet{}
-		if err = json.Unmarshal([]byte(rsJsonStr), getRs); err == nil {
-			c.String(http.StatusOK, fmt.Sprintf("replicaSet %s/%s already exists", getRs.Namespace(), getRs.Name()))
+// getScheduledPod finds the pod namespace/name on the node it is scheduled
+// to. The revision is 0 if there is no such pod.
+func getScheduledPod(namespace, name string) (pod *apiObject.Pod, node string, revision int64, err error) {
+	for _, node = range helper.GetNodeHostnames() {
+		pod = &apiObject.Pod{}
+		if revision, err = registry.GetObject(path.Join(url.PodURL, node, namespace, name), pod); err != nil || revision != 0 {
 			return
 		}
 	}
+	return nil, "", 0, nil
+}
 
-	if err = etcd.Put(etcdReplicaSetURL, string(rsJson)); err != nil {
-		c.String(http.StatusOK, err.Error())
-		return
+// updatePod stores the new version of a scheduled pod and has its kubelet
+// recreate it. Its endpoints follow its labels and ports.
+func updatePod(node string, pod, old *apiObject.Pod, revision int64) error {
+	err := registry.Admit(&registry.AdmissionAttributes{
+		Operation:  registry.OperationUpdate,
+		Kind:       "Pod",
+		Namespaced: true,
+		Object:     pod,
+		OldObject:  old,
+	}, nil)
+	if err != nil {
+		return err
 	}
+	if err = registry.PutObject(path.Join(url.PodURL, node, pod.Namespace(), pod.Name()), pod, revision); err != nil {
+		return err
+	}
+	log("update pod %s/%s on node %s", pod.Namespace(), pod.Name(), node)
 
-	etcdReplicaSetStatusURL := path.Join(url.ReplicaSetURL, "status", rs.Namespace(), rs.Name())
-	var replicaSetStatusJson []byte
-	if replicaSetStatusJson, err = json.Marshal(entity.ReplicaSetStatus{
-		ID:         rs.UID(),
-		Name:       rs.Name(),
-		Namespace:  rs.Namespace(),
-		Labels:     rs.Labels(),
-		Lifecycle:  entity.ReplicaSetUnknown,
-		CpuPercent: 0,
-		MemPercent: 0,
-		Error:      "",
-		SyncTime:   time.Now(),
-	}); err == nil {
-		_ = etcd.Put(etcdReplicaSetStatusURL, string(replicaSetStatusJson))
+	if err = helper.DelEndpoints(*old); err != nil {
+		logger.Error(err.Error())
+	}
+	if err = helper.AddEndpoints(*pod); err != nil {
+		logger.Error(err.Error())
 	}
 
-	replicaSetUpdateMsg, _ := json.Marshal(entity.ReplicaSetUpdate{
-		Action: entity.CreateAction,
-		Target: rs,
+	podUpdateMsg, _ := json.Marshal(entity.PodUpdate{
+		Action: entity.UpdateAction,
+		Node:   node,
+		Target: *pod,
 	})
-
-	listwatch.Publish(topicutil.ReplicaSetUpdateTopic(), replicaSetUpdateMsg)
-	c.String(http.StatusOK, "ok")
+	listwatch.Publish(topicutil.PodUpdateTopic(node), podUpdateMsg)
+	return nil
 }
 
-func HandleApplyHPA(c *gin.Context) {
-	hpa := apiObject.HorizontalPodAutoscaler{}
-	err := httputil.ReadAndUnmarshal(c.Request.Body, &hpa)
+// HandlePatchPod serves PATCH on a pod, like registry.HandlePatch does for the
+// other kinds: kubectl apply creates the pod if need be, otherwise the pod is
+// patched and recreated by its kubelet. Its uid and its ip stay the same.
+func HandlePatchPod(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	patch, err := ioutil.ReadAll(c.Request.Body)
 	if err != nil {
-		c.String(http.StatusOK, err.Error())
+		registry.WriteError(c, httputil.NewBadRequest(err.Error()))
 		return
 	}
-
-	log("receive hpa[ID = %v]: %v", hpa.UID(), hpa)
-
-	if err = addHPA(&hpa); err != nil {
-		c.String(http.StatusOK, err.Error())
+	checked := &apiObject.Pod{}
+	checked.Metadata.Namespace, checked.Metadata.Name = namespace, name
+	if err = registry.CheckUnknownPatchFields(c, "Pod", checked, patch); err != nil {
+		registry.WriteError(c, err)
 		return
 	}
-	c.String(http.StatusOK, "ok")
-}
 
-func HandleApplyService(c *gin.Context) {
-	service := apiObject.Service{}
-	err := httputil.ReadAndUnmarshal(c.Request.Body, &service)
+	old, node, revision, err := getScheduledPod(namespace, name)
 	if err != nil {
-		c.String(http.StatusOK, err.Error())
+		registry.WriteError(c, err)
 		return
-	}
-
-	if helper.ExistsService(service.Metadata.Namespace, service.Metadata.Name) {
-		c.String(http.StatusOK, fmt.Sprintf("service %s/%s already exists", service.Metadata.Namespace, service.Metadata.Name))
-		return
-	}
-
-	service.Metadata.UID = uidutil.New()
-	if service.Spec.ClusterIP, err = helper.NewServiceIp(); err != nil {
-		c.String(http.StatusOK, err.Error())
-		return
-	}
-	log("receive service: %+v", service)
-
-	serviceUpdate := entity.ServiceUpdate{
-		Action: entity.CreateAction,
-		Target: entity.ServiceTarget{
-			Service:   service,
-			Endpoints: make([]apiObject.Endpoint, 0),
-		},
-	}
-
-	var serviceJson []byte
-	if serviceJson, err = json.Marshal(service); err != nil {
-		c.String(http.StatusOK, err.Error())
-		return
-	}
-	if err := etcd.Put(path.Join(url.ServiceURL, service.Metadata.Namespace, service.Metadata.Name), string(serviceJson)); err != nil {
-		c.String(http.StatusOK, err.Error())
-		return
-	}
-	for key, value := range service.Spec.Selector {
-		if err := etcd.Put(path.Join(url.ServiceURL, key, value, service.Metadata.UID), string(serviceJson)); err != nil {
-			c.String(http.StatusOK, err.Error())
+	} else if revision == 0 {
+		if c.ContentType() != contentType.ApplyPatch {
+			registry.WriteError(c, registry.NewNotFound("Pod", namespace, name))
 			return
 		}
-		if endpoints, err := helper.GetEndpoints(key, value); err != nil {
-			c.String(http.StatusOK, err.Error())
+		pod := apiObject.Pod{}
+		if err = json.Unmarshal(patch, &pod); err != nil {
+			registry.WriteError(c, httputil.NewBadRequest(err.Error()))
 			return
-		} else {
-			serviceUpdate.Target.Endpoints = append(serviceUpdate.Target.Endpoints, endpoints...)
 		}
-	}
-
-	serviceUpdateMsg, _ := json.Marshal(serviceUpdate)
-	listwatch.Publish(topicutil.ServiceUpdateTopic(), serviceUpdateMsg)
-
-	c.String(http.StatusOK, "ok")
-}
-
-func HandleApplyDNS(c *gin.Context) {
-	dns := apiObject.Dns{}
-	err := httputil.ReadAndUnmarshal(c.Request.Body, &dns)
-	if err != nil {
-		c.String(http.StatusOK, err.Error())
-		return
-	}
-	if helper.ExistsDNS(dns.Metadata.Namespace, dns.Metadata.Name) {
-		c.String(http.StatusOK, fmt.Sprintf("dns %s/%s already exists", dns.Metadata.Namespace, dns.Metadata.Name))
-		return
-	}
-
-	dns.Metadata.UID = uidutil.New()
-	log("receive dns: %+v", dns)
-
-	nm := nginx.New(dns.Metadata.UID)
-
-	// Step 1: Apply mappings to nginx.conf
-	servers := make([]nginx.Server, 1)
-	servers[0].Port = 80
-	for _, p := range dns.Spec.Paths {
-		log("%#v", p)
-		if !helper.ExistsService(dns.Metadata.Namespace, p.Service.Name) {
-			c.String(http.StatusOK, fmt.Sprintf("service %s/%s does not exist", dns.Metadata.Namespace, p.Service.Name))
-			return
+		pod.Metadata.Namespace = namespace
+		pod.Metadata.Name = name
+		if err = registry.SetLastApplied(&pod, patch); err == nil {
+			err = createPod(&pod)
 		}
-		service := apiObject.Service{}
-		if serviceJsonStr, err := etcd.Get(path.Join(url.ServiceURL, dns.Metadata.Namespace, p.Service.Name)); err != nil {
-			c.String(http.StatusOK, err.Error())
+		if err != nil {
+			registry.WriteError(c, err)
 			return
-		} else {
-			log("%#v", serviceJsonStr)
-			if err := json.Unmarshal([]byte(serviceJsonStr), &service); err != nil {
-				c.String(http.StatusOK, err.Error())
-			}
 		}
-
-		log("dns service: %+v", service)
-		servers[0].Locations = append(servers[0].Locations, nginx.Location{
-			Dest: service.Spec.ClusterIP + ":" + p.Service.Port,
-			Addr: p.Path,
-		})
-	}
-	if err := nm.Apply(servers); err != nil {
-		c.String(http.StatusOK, err.Error())
+		c.JSON(http.StatusCreated, pod)
 		return
 	}
-
-	// Step 2: Start nginx container
-	log("nginx starting..")
-	if err = nm.Start(); err != nil {
-		c.String(http.StatusOK, err.Error())
+	if err = registry.CheckResourceVersion(c.Query("resourceVersion"), revision); err != nil {
+		registry.WriteError(c, err)
 		return
 	}
 
-	// Step 3: Attach ip to nginx container
-	var nginxIp string
-	if nginxIp, err = helper.NewPodIp(); err != nil {
-		c.String(http.StatusOK, err.Error())
+	var original, patched, updated []byte
+	if original, err = json.Marshal(old); err != nil {
+		registry.WriteError(c, err)
 		return
 	}
-	if err = weaveutil.WeaveAttach(nm.GetName(), nginxIp+url.MaskStr); err != nil {
-		c.String(http.StatusOK, err.Error())
+	if patched, err = registry.ApplyPatch(c.ContentType(), original, patch); err != nil {
+		registry.WriteError(c, err)
 		return
 	}
-	log("%#v", nginxIp)
-
-	// Step 4: Modify dns configuration
-	if err = dns2.New(path.Join(url.DNSDirPath, url.DNSHostsFileName)).AddEntry(dns.Spec.Host, nginxIp); err != nil {
-		c.String(http.StatusOK, err.Error())
-		return
-	}
-
-	// Step 5: Store to etcd
-	if dnsJson, err := json.Marshal(dns); err != nil {
-		c.String(http.StatusOK, err.Error())
+	pod := apiObject.Pod{}
+	if err = json.Unmarshal(patched, &pod); err != nil {
+		registry.WriteError(c, httputil.NewBadRequest(err.Error()))
 		return
-	} else {
-		if err := etcd.Put(path.Join(url.DNSURL, dns.Metadata.Namespace, dns.Metadata.Name), string(dnsJson)); err != nil {
-			c.String(http.StatusOK, err.Error())
-			return
-		}
 	}
-	c.String(http.StatusOK, "Apply successfully!")
-}
-
-func HandleApplyGpuJob(c *gin.Context) {
-	gpu := apiObject.GpuJob{}
-	err := httputil.ReadAndUnmarshal(c.Request.Body, &gpu)
-	if err != nil {
-		c.String(http.StatusOK, err.Error())
+	pod.Metadata.Namespace = namespace
+	pod.Metadata.Name = name
+	pod.Metadata.UID = old.UID()
+	pod.Spec.ClusterIp = old.Spec.ClusterIp
+	if updated, _ = json.Marshal(pod); bytes.Equal(updated, original) {
+		c.JSON(http.StatusOK, old)
 		return
 	}
-	gpu.Metadata.UID = uidutil.New()
-	log("receive gpu job[ID = %v]: %v", gpu.UID(), gpu)
-
-	etcdURL := path.Join(url.GpuURL, gpu.Namespace(), gpu.Name())
-	if raw, err := etcd.Get(etcdURL); err == nil {
-		old := apiObject.GpuJob{}
-		if err := json.Unmarshal([]byte(raw), &old); err == nil {
-			c.String(http.StatusOK, fmt.Sprintf("gpu job %s/%s already exists", gpu.Namespace(), gpu.Name()))
-			return
-		}
-	}
-
-	gpuJobJson, _ := json.Marshal(gpu)
-	if err = etcd.Put(etcdURL, string(gpuJobJson)); err != nil {
-		c.String(http.StatusOK, err.Error())
-		return
+	if err = registry.CheckResourceVersion(pod.Metadata.ResourceVersion, revision); err == nil {
+		err = updatePod(node, &pod, old, revision)
 	}
-
-	statusJson, _ := json.Marshal(entity.GpuJobStatus{
-		Namespace:    gpu.Namespace(),
-		Name:         gpu.Name(),
-		State:        "Unknown",
-		LastSyncTime: time.Now(),
-	})
-	_ = etcd.Put(path.Join(url.GpuURL, "status", gpu.Namespace(), gpu.Name()), string(statusJson))
-
-	GpuUpdateMsg, _ := json.Marshal(entity.GpuUpdate{
-		Action: entity.CreateAction,
-		Target: gpu,
-	})
-
-	listwatch.Publish(topicutil.GpuJobUpdateTopic(), GpuUpdateMsg)
-	c.String(http.StatusOK, "ok")
-}
-
-func HandleApplyWorkflow(c *gin.Context) {
-	wf := apiObject.Workflow{}
-	err := httputil.ReadAndUnmarshal(c.Request.Body, &wf)
 	if err != nil {
-		c.String(http.StatusOK, err.Error())
+		registry.WriteError(c, err)
 		return
 	}
-	wf.Metadata.UID = uidutil.New()
-	log("receive workflow[ID = %v]: %v", wf.UID(), wf)
-
-	etcdURL := path.Join(url.WorkflowURL, wf.Namespace(), wf.Name())
-	if raw, err := etcd.Get(etcdURL); err == nil {
-		old := apiObject.Workflow{}
-		if err := json.Unmarshal([]byte(raw), &old); err == nil {
-			c.String(http.StatusOK, fmt.Sprintf("Workflow %s/%s already exists", wf.Namespace(), wf.Name()))
-			return
-		}
-	}
-
-	workflowJson, _ := json.Marshal(wf)
-	if err = etcd.Put(etcdURL, string(workflowJson)); err != nil {
-		c.String(http.StatusOK, err.Error())
-		return
-	}
-
-	result := entity.FunctionTriggerResult{
-		WorkflowNamespace: wf.Namespace(),
-		WorkflowName:      wf.Name(),
-		Data:              "",
-		Time:              time.Now(),
-		Status:            entity.TriggerUnknown,
-		Error:             "",
-		FinishedAll:       false,
-	}
-	resultJson, _ := json.Marshal(result)
-
-	_ = etcd.Put(path.Join(url.WorkflowURL, "result", wf.Namespace(), wf.Name()), string(resultJson))
-
-	workflowMsg, _ := json.Marshal(entity.WorkflowUpdate{
-		Action: entity.CreateAction,
-		Target: wf,
-	})
-
-	listwatch.Publish(topicutil.WorkflowUpdateTopic(), workflowMsg)
-	c.String(http.StatusOK, "ok")
+	c.JSON(http.StatusOK, pod)
 }
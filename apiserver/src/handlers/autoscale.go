@@ -3,6 +3,7 @@ package handlers
 import (
 	"github.com/gin-gonic/gin"
 	"minik8s/apiObject"
+	"minik8s/apiserver/src/registry"
 	"minik8s/util/uidutil"
 	"net/http"
 	"strconv"
@@ -54,8 +55,8 @@ func HandleAutoscale(c *gin.Context) {
 		},
 	}
 
-	if err := addHPA(hpa); err != nil {
-		c.String(http.StatusOK, err.Error())
+	if err := hpaKind.Create(hpa); err != nil {
+		registry.WriteError(c, err)
 		return
 	}
 
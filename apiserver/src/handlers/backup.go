@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/auth"
+	"minik8s/apiserver/src/etcd"
+	"minik8s/apiserver/src/helper"
+	"minik8s/apiserver/src/registry"
+	"minik8s/apiserver/src/url"
+	"minik8s/entity"
+	"minik8s/listwatch"
+	"minik8s/util/httputil"
+	"minik8s/util/logger"
+	"minik8s/util/topicutil"
+	"net/http"
+	"path"
+	"time"
+)
+
+// HandleBackup serves GET /backup, a snapshot of every key
+func HandleBackup(c *gin.Context) {
+	kvs, revision, err := etcd.Snapshot()
+	if err != nil {
+		registry.WriteError(c, err)
+		return
+	}
+	backup := entity.Backup{Revision: revision, Time: time.Now(), Keys: make([]entity.BackupKey, 0, len(kvs))}
+	for _, kv := range kvs {
+		backup.Keys = append(backup.Keys, entity.BackupKey{Key: kv.Key, Value: kv.Value, ModRevision: kv.ModRevision})
+	}
+	log("back up %d keys at revision %d", len(backup.Keys), revision)
+	c.JSON(http.StatusOK, backup)
+}
+
+// HandleRestore serves POST /backup, which replaces every key by those of the
+// backup at once. The objects the backup does not have are then published as
+// deleted and the objects restored as created, so that the components drop
+// the former and take on the latter.
+func HandleRestore(c *gin.Context) {
+	backup := entity.Backup{}
+	if err := httputil.ReadAndUnmarshal(c.Request.Body, &backup); err != nil {
+		registry.WriteError(c, httputil.NewBadRequest(err.Error()))
+		return
+	}
+	if backup.Revision == 0 {
+		registry.WriteError(c, httputil.NewBadRequest("not a backup, it has no revision"))
+		return
+	}
+
+	kvs := make([]etcd.KeyValue, 0, len(backup.Keys))
+	for _, key := range backup.Keys {
+		kvs = append(kvs, etcd.KeyValue{Key: key.Key, Value: key.Value})
+	}
+	replaced := listPublished()
+	if err := etcd.Restore(kvs); err != nil {
+		registry.WriteError(c, err)
+		return
+	}
+	auth.InvalidatePolicy()
+	log("restored %d keys of the backup at revision %d", len(kvs), backup.Revision)
+
+	republish(replaced)
+	c.String(http.StatusOK, "ok")
+}
+
+// published are the objects the components learn about from the updates
+// the api server publishes: those of the kinds by kind and namespace/name,
+// the pods by node and namespace/name, and the functions by name.
+type published struct {
+	kinds []*registry.Kind
+	objs  map[string]map[string]apiObject.Object
+	pods  map[string]map[string]*apiObject.Pod
+	funcs map[string]apiObject.Function
+}
+
+// listPublished lists the objects that are published, the definitions of the
+// custom kinds before the other kinds.
+func listPublished() *published {
+	p := &published{
+		kinds: []*registry.Kind{crdKind},
+		objs:  make(map[string]map[string]apiObject.Object),
+		pods:  make(map[string]map[string]*apiObject.Pod),
+		funcs: make(map[string]apiObject.Function),
+	}
+	for _, kind := range registry.Kinds() {
+		if kind != crdKind {
+			p.kinds = append(p.kinds, kind)
+		}
+	}
+	for _, kind := range p.kinds {
+		objs, _, err := kind.List()
+		if err != nil {
+			logger.Error(err.Error())
+			continue
+		}
+		p.objs[kind.Kind] = make(map[string]apiObject.Object, len(objs))
+		for _, obj := range objs {
+			p.objs[kind.Kind][path.Join(obj.Meta().Namespace, obj.Meta().Name)] = obj
+		}
+	}
+
+	for _, node := range helper.GetNodeHostnames() {
+		p.pods[node] = make(map[string]*apiObject.Pod)
+		for _, pod := range helper.GetPodsApiObjectFromEtcd(node) {
+			p.pods[node][path.Join(pod.Namespace(), pod.Name())] = pod
+		}
+	}
+
+	raws, err := etcd.GetAll(url.FuncURL)
+	if err != nil {
+		logger.Error(err.Error())
+	}
+	for _, raw := range raws {
+		apiFunc := apiObject.Function{}
+		if err = json.Unmarshal([]byte(raw), &apiFunc); err == nil {
+			p.funcs[apiFunc.Name] = apiFunc
+		}
+	}
+	return p
+}
+
+// republish publishes the objects replaced by a restore that are gone as
+// deleted, and every object as if it had just been created. The custom kinds
+// are registered by their definitions first, and unregistered last.
+func republish(replaced *published) {
+	republishKind(crdKind)
+	restored := listPublished()
+
+	for i := len(replaced.kinds) - 1; i >= 0; i-- {
+		kind := replaced.kinds[i]
+		if kind.AfterDelete == nil {
+			continue
+		}
+		for key, obj := range replaced.objs[kind.Kind] {
+			if _, exists := restored.objs[kind.Kind][key]; !exists {
+				kind.AfterDelete(obj)
+			}
+		}
+	}
+	for node, pods := range replaced.pods {
+		for key, pod := range pods {
+			if _, exists := restored.pods[node][key]; !exists {
+				createAndPublishPodDeleteMsg(node, pod)
+			}
+		}
+	}
+	for name, apiFunc := range replaced.funcs {
+		if _, exists := restored.funcs[name]; !exists {
+			publishFunctionUpdate(entity.DeleteAction, apiFunc)
+		}
+	}
+
+	for _, kind := range restored.kinds {
+		if kind == crdKind || kind.AfterCreate == nil {
+			continue
+		}
+		for _, obj := range restored.objs[kind.Kind] {
+			kind.AfterCreate(obj)
+		}
+	}
+	for node, pods := range restored.pods {
+		for _, pod := range pods {
+			podUpdateMsg, _ := json.Marshal(entity.PodUpdate{
+				Action: entity.CreateAction,
+				Node:   node,
+				Target: *pod,
+			})
+			listwatch.Publish(topicutil.PodUpdateTopic(node), podUpdateMsg)
+		}
+	}
+	for _, apiFunc := range restored.funcs {
+		publishFunctionUpdate(entity.CreateAction, apiFunc)
+	}
+}
+
+func publishFunctionUpdate(action entity.ApiObjectUpdateAction, apiFunc apiObject.Function) {
+	funcUpdateMsg, _ := json.Marshal(entity.FunctionUpdate{
+		Action: action,
+		Target: apiFunc,
+	})
+	listwatch.Publish(topicutil.FunctionUpdateTopic(), funcUpdateMsg)
+}
+
+func republishKind(kind *registry.Kind) {
+	if kind.AfterCreate == nil {
+		return
+	}
+	objs, _, err := kind.List()
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+	for _, obj := range objs {
+		kind.AfterCreate(obj)
+	}
+}
+
+// HandleMigrate serves POST /migrate, which rewrites the objects of every kind
+// that are stored in another version than its storage version. The objects
+// are the same in the hub version, so nothing is published.
+func HandleMigrate(c *gin.Context) {
+	migrations := make([]entity.StorageMigration, 0)
+	for _, kind := range registry.Kinds() {
+		migrated, err := kind.Migrate()
+		if err != nil {
+			registry.WriteError(c, err)
+			return
+		}
+		migrations = append(migrations, entity.StorageMigration{
+			Kind:           kind.Kind,
+			StorageVersion: kind.StorageApiVersion(),
+			Migrated:       migrated,
+		})
+	}
+	c.JSON(http.StatusOK, migrations)
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"crypto/x509"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/auth"
+	"minik8s/apiserver/src/registry"
+	"minik8s/util/certutil"
+	"minik8s/util/httputil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HandleCreateCSR stores a certificate signing request along with who sent
+// it and whom it is for, for the admin to approve. Only the admin may request
+// a certificate of any user, the others only that of a kubelet.
+func HandleCreateCSR(c *gin.Context) {
+	csr := &apiObject.CertificateSigningRequest{}
+	if err := httputil.ReadAndUnmarshal(c.Request.Body, csr); err != nil {
+		registry.WriteError(c, httputil.NewBadRequest(err.Error()))
+		return
+	}
+	user := auth.UserFrom(c)
+	csr.Spec.Username, csr.Spec.Groups = user.Name, user.Groups
+	err := checkRequester(user, csr)
+	if err == nil {
+		err = csrKind.Create(csr)
+	}
+	if err != nil {
+		registry.WriteError(c, err)
+		return
+	}
+	c.String(http.StatusOK, "ok")
+}
+
+// authorizeCSR is the AuthorizeWrite of csrKind, which keeps the requests
+// stored by any other way than HandleCreateCSR, e.g. kubectl apply, from
+// claiming another requester or a subject their requester may not ask for.
+func authorizeCSR(c *gin.Context, obj apiObject.Object) error {
+	csr, user := obj.(*apiObject.CertificateSigningRequest), auth.UserFrom(c)
+	if user.InGroup(auth.GroupMasters) {
+		return nil
+	}
+	if csr.Spec.Username != user.Name || strings.Join(csr.Spec.Groups, ",") != strings.Join(user.Groups, ",") {
+		return httputil.NewForbidden(fmt.Sprintf("user %s may only request certificates as themselves", user.Name))
+	}
+	return checkRequester(user, csr)
+}
+
+// checkRequester checks that requester may ask for the certificate of csr:
+// the admin for any user, the others only for a kubelet.
+func checkRequester(requester *auth.User, csr *apiObject.CertificateSigningRequest) error {
+	request, err := certutil.ParseRequest([]byte(csr.Spec.Request))
+	if err != nil {
+		return httputil.NewBadRequest(err.Error())
+	}
+	if !requester.InGroup(auth.GroupMasters) && !isNodeSubject(request.Subject.CommonName, request.Subject.Organization) {
+		return httputil.NewForbidden(fmt.Sprintf("%s may only request a certificate of %s<hostname> in %s",
+			requester.Name, auth.NodeUserPrefix, auth.GroupComponents))
+	}
+	return nil
+}
+
+// isNodeSubject tells whether a certificate is requested for a kubelet, the
+// subject a kubelet started with --request-certificate asks for.
+func isNodeSubject(commonName string, organization []string) bool {
+	return strings.HasPrefix(commonName, auth.NodeUserPrefix) && len(commonName) > len(auth.NodeUserPrefix) &&
+		len(organization) == 1 && organization[0] == auth.GroupComponents
+}
+
+// HandleApproveCSR approves or denies a pending certificate signing request
+// by the condition in the body. An approved request is signed by the cluster
+// CA right away.
+func HandleApproveCSR(c *gin.Context) {
+	condition := apiObject.CertificateSigningRequestCondition{}
+	if err := httputil.ReadAndUnmarshal(c.Request.Body, &condition); err != nil {
+		registry.WriteError(c, httputil.NewBadRequest(err.Error()))
+		return
+	}
+	if condition.Type != apiObject.CertificateApproved && condition.Type != apiObject.CertificateDenied {
+		registry.WriteError(c, httputil.NewBadRequest(fmt.Sprintf("invalid condition \"%s\", acceptable condition is Approved or Denied", condition.Type)))
+		return
+	}
+
+	name := c.Param("name")
+	key := csrKind.Key("", name)
+	csr := &apiObject.CertificateSigningRequest{}
+	revision, err := registry.GetObject(key, csr)
+	if err != nil {
+		registry.WriteError(c, err)
+		return
+	} else if revision == 0 {
+		registry.WriteError(c, httputil.NewNotFound(csrKind.Kind, "", name))
+		return
+	}
+	if csr.Condition() != "" {
+		registry.WriteError(c, httputil.NewConflict(fmt.Sprintf("the certificate signing request %s is %s already", name, csr.Condition())))
+		return
+	}
+
+	request, err := certutil.ParseRequest([]byte(csr.Spec.Request))
+	if err != nil {
+		registry.WriteError(c, httputil.NewInvalid(csrKind.Kind, "", name, err))
+		return
+	}
+	subject := request.Subject.CommonName
+	if condition.Type == apiObject.CertificateApproved {
+		if !recordsSubject(csr, request) {
+			registry.WriteError(c, httputil.NewInvalid(csrKind.Kind, "", name, fmt.Errorf(
+				"it is recorded for %s in [%s] but requests %s in [%s]", csr.Spec.CommonName, strings.Join(csr.Spec.Organization, ","),
+				subject, strings.Join(request.Subject.Organization, ","))))
+			return
+		}
+		certificate, err := signCSR(csr)
+		if err != nil {
+			registry.WriteError(c, err)
+			return
+		}
+		csr.Status.Certificate = string(certificate)
+	}
+	condition.LastUpdateTime = time.Now()
+	csr.Status.Conditions = append(csr.Status.Conditions, condition)
+	if err = registry.PutObject(key, csr, revision); err != nil {
+		registry.WriteError(c, err)
+		return
+	}
+	log("certificate signing request %s of %s for %s %s", name, csr.Spec.Username, subject, condition.Type)
+	c.JSON(http.StatusOK, csr)
+}
+
+// recordsSubject tells whether the subject recorded on csr is that of request,
+// its parsed request, which is what gets signed.
+func recordsSubject(csr *apiObject.CertificateSigningRequest, request *x509.CertificateRequest) bool {
+	return csr.Spec.CommonName == request.Subject.CommonName &&
+		strings.Join(csr.Spec.Organization, ",") == strings.Join(request.Subject.Organization, ",")
+}
+
+// signCSR signs the request of csr, checking again that its requester may ask
+// for it, whatever was stored.
+func signCSR(csr *apiObject.CertificateSigningRequest) ([]byte, error) {
+	if err := checkRequester(&auth.User{Name: csr.Spec.Username, Groups: csr.Spec.Groups}, csr); err != nil {
+		return nil, err
+	}
+	ca, err := certutil.ReadCA()
+	if err != nil {
+		return nil, err
+	}
+	return ca.SignRequest([]byte(csr.Spec.Request))
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/auth"
+	"minik8s/util/certutil"
+	"minik8s/util/httputil"
+	"testing"
+)
+
+func TestIsNodeSubject(t *testing.T) {
+	assert.True(t, isNodeSubject("system:node:vm", []string{auth.GroupComponents}))
+	assert.False(t, isNodeSubject("system:node:", []string{auth.GroupComponents}))
+	assert.False(t, isNodeSubject("admin", []string{auth.GroupComponents}))
+	assert.False(t, isNodeSubject("system:node:vm", []string{auth.GroupComponents, auth.GroupMasters}))
+	assert.False(t, isNodeSubject("system:node:vm", nil))
+}
+
+func newCSR(t *testing.T, user string, groups []string) *apiObject.CertificateSigningRequest {
+	key, err := certutil.NewKey()
+	assert.Nil(t, err)
+	request, err := certutil.NewRequest(key, user, groups)
+	assert.Nil(t, err)
+	csr := &apiObject.CertificateSigningRequest{}
+	csr.Spec.Request = string(request)
+	return csr
+}
+
+func TestCheckRequester(t *testing.T) {
+	kubelet := &auth.User{Name: "system:kubelet", Groups: []string{auth.GroupComponents}}
+	admin := &auth.User{Name: "admin", Groups: []string{auth.GroupMasters}}
+	node := newCSR(t, "system:node:vm", []string{auth.GroupComponents})
+	master := newCSR(t, "admin", []string{auth.GroupMasters})
+
+	assert.Nil(t, checkRequester(kubelet, node))
+	assert.True(t, httputil.IsForbidden(checkRequester(kubelet, master)))
+	assert.Nil(t, checkRequester(admin, master))
+
+	// the subject is taken from the request, not from what was sent along
+	master.Spec.CommonName, master.Spec.Organization = "system:node:vm", []string{auth.GroupComponents}
+	assert.Nil(t, csrKind.PrepareForCreate(master))
+	assert.Equal(t, "admin", master.Spec.CommonName)
+	assert.Equal(t, []string{auth.GroupMasters}, master.Spec.Organization)
+
+	request, err := certutil.ParseRequest([]byte(master.Spec.Request))
+	assert.Nil(t, err)
+	assert.True(t, recordsSubject(master, request))
+	master.Spec.CommonName = "system:node:vm"
+	assert.False(t, recordsSubject(master, request))
+}
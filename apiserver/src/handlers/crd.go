@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/etcd"
+	"minik8s/apiserver/src/registry"
+	"minik8s/apiserver/src/url"
+	"minik8s/util/httputil"
+	"minik8s/util/logger"
+	"minik8s/util/schemautil"
+	"net/http"
+	"path"
+	"strings"
+)
+
+var crdKind = &registry.Kind{
+	Kind:       "CustomResourceDefinition",
+	Prefix:     url.CRDURL,
+	Namespaced: false,
+	New:        func() apiObject.Object { return &apiObject.CustomResourceDefinition{} },
+	ApiVersion: "apiextensions/v1",
+	Hooks: registry.Hooks{
+		PrepareForCreate: func(obj apiObject.Object) error {
+			crd := obj.(*apiObject.CustomResourceDefinition)
+			if crd.Metadata.Name == "" {
+				crd.Metadata.Name = crd.Spec.Names.Plural + "." + crd.Spec.Group
+			}
+			if crd.Spec.Scope == "" {
+				crd.Spec.Scope = apiObject.NamespacedScope
+			}
+			if kind := registry.Lookup(crd.Spec.Names.Kind); kind != nil {
+				return httputil.NewConflict(fmt.Sprintf("kind %s already exists", kind.Kind))
+			}
+			for _, kind := range unregisteredKinds {
+				if strings.EqualFold(crd.Spec.Names.Kind, kind) {
+					return httputil.NewConflict(fmt.Sprintf("kind %s already exists", kind))
+				}
+			}
+			if kind := registry.LookupResource(crd.Spec.Group, crd.Spec.Names.Plural); kind != nil {
+				return httputil.NewConflict(fmt.Sprintf("%s of %s already exists", crd.Spec.Names.Plural, crd.Spec.Group))
+			}
+			return nil
+		},
+		PrepareForUpdate: func(obj, old apiObject.Object) error {
+			crd, oldCrd := obj.(*apiObject.CustomResourceDefinition), old.(*apiObject.CustomResourceDefinition)
+			if crd.Spec.Scope == "" {
+				crd.Spec.Scope = apiObject.NamespacedScope
+			}
+			if crd.Spec.Group != oldCrd.Spec.Group || crd.Spec.Names != oldCrd.Spec.Names || crd.Spec.Scope != oldCrd.Spec.Scope {
+				return httputil.NewInvalid("CustomResourceDefinition", "", crd.Name(), fmt.Errorf("the group, names and scope cannot be changed"))
+			}
+			return nil
+		},
+		Validate: func(obj apiObject.Object) error {
+			return validateCRD(obj.(*apiObject.CustomResourceDefinition))
+		},
+		AfterCreate: func(obj apiObject.Object) {
+			registerCustomKind(obj.(*apiObject.CustomResourceDefinition))
+		},
+		AfterUpdate: func(obj, old apiObject.Object) {
+			registerCustomKind(obj.(*apiObject.CustomResourceDefinition))
+		},
+		AfterDelete: func(obj apiObject.Object) {
+			crd := obj.(*apiObject.CustomResourceDefinition)
+			registry.Unregister(crd.Spec.Names.Kind)
+			if err := etcd.DeleteAll(customResourcePrefix(crd)); err != nil {
+				logger.Error(err.Error())
+			}
+		},
+	},
+}
+
+// unregisteredKinds are the built-in kinds served by their own handlers
+// rather than the registry, which a custom kind cannot take either.
+var unregisteredKinds = []string{"Pod", "Function"}
+
+func validateCRD(crd *apiObject.CustomResourceDefinition) error {
+	spec := crd.Spec
+	if spec.Group == "" || spec.Version == "" || spec.Names.Kind == "" || spec.Names.Plural == "" {
+		return fmt.Errorf("group, version, names.kind and names.plural of %s are required", crd.Name())
+	}
+	if strings.Contains(spec.Group+spec.Version+spec.Names.Plural, "/") {
+		return fmt.Errorf("group, version and names.plural of %s must not contain '/'", crd.Name())
+	}
+	if crd.Name() != spec.Names.Plural+"."+spec.Group {
+		return fmt.Errorf("the name of %s must be %s.%s", crd.Name(), spec.Names.Plural, spec.Group)
+	}
+	if spec.Scope != apiObject.NamespacedScope && spec.Scope != apiObject.ClusterScope {
+		return fmt.Errorf("scope of %s must be %s or %s", crd.Name(), apiObject.NamespacedScope, apiObject.ClusterScope)
+	}
+	return nil
+}
+
+// customResourcePrefix is where the objects of crd are stored. Unlike the
+// URL, it does not depend on the version.
+func customResourcePrefix(crd *apiObject.CustomResourceDefinition) string {
+	return path.Join(url.CustomResourceURL, crd.Spec.Group, crd.Spec.Names.Plural) + "/"
+}
+
+func registerCustomKind(crd *apiObject.CustomResourceDefinition) {
+	log("register custom kind %s of %s", crd.Spec.Names.Kind, crd.GroupVersion())
+	schema := crd.Spec.Schema
+	registry.Register(&registry.Kind{
+		Kind:       crd.Spec.Names.Kind,
+		Prefix:     customResourcePrefix(crd),
+		Namespaced: crd.Namespaced(),
+		New:        func() apiObject.Object { return &apiObject.CustomObject{} },
+		ApiVersion: crd.GroupVersion(),
+		Group:      crd.Spec.Group,
+		Version:    crd.Spec.Version,
+		Plural:     crd.Spec.Names.Plural,
+		Hooks: registry.Hooks{
+			PrepareForCreate: func(obj apiObject.Object) error {
+				defaultCustomObject(obj.(*apiObject.CustomObject), crd)
+				return nil
+			},
+			PrepareForUpdate: func(obj, old apiObject.Object) error {
+				defaultCustomObject(obj.(*apiObject.CustomObject), crd)
+				return nil
+			},
+			Validate: func(obj apiObject.Object) error {
+				customObj := obj.(*apiObject.CustomObject)
+				if customObj.Metadata.Name == "" {
+					return fmt.Errorf("metadata.name is required")
+				}
+				if errs := schemautil.Validate(schema, customObj.Fields); errs != nil {
+					return errs
+				}
+				return nil
+			},
+		},
+	})
+}
+
+func defaultCustomObject(obj *apiObject.CustomObject, crd *apiObject.CustomResourceDefinition) {
+	obj.ApiVersion = crd.GroupVersion()
+	obj.Kind = crd.Spec.Names.Kind
+	if !crd.Namespaced() {
+		obj.Metadata.Namespace = ""
+	} else if obj.Metadata.Namespace == "" {
+		obj.Metadata.Namespace = apiObject.DefaultNamespace
+	}
+}
+
+// loadCustomKinds registers the kinds of the stored definitions, so that they
+// survive a restart of the api-server.
+func loadCustomKinds() {
+	crds, _, err := crdKind.List()
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+	for _, obj := range crds {
+		registerCustomKind(obj.(*apiObject.CustomResourceDefinition))
+	}
+}
+
+// HandleCustomResource serves /apis/group/version/plural/[namespace/]name for
+// every custom kind, which may be registered after the routes are bound, and
+// for the built-in kinds in their other versions than the hub one.
+func HandleCustomResource(c *gin.Context) {
+	kind := registry.LookupVersion(c.Param("group"), c.Param("version"), c.Param("plural"))
+	if kind == nil {
+		registry.WriteError(c, registry.NewNotFound("resource", "", path.Join(c.Param("group"), c.Param("version"), c.Param("plural"))))
+		return
+	}
+
+	var parts []string
+	if trimmed := strings.Trim(c.Param("path"), "/"); trimmed != "" {
+		parts = strings.Split(trimmed, "/")
+	}
+
+	if len(parts) == 0 {
+		switch c.Request.Method {
+		case http.MethodGet:
+			registry.HandleList(kind)(c)
+			return
+		case http.MethodPost:
+			registry.HandleCreate(kind)(c)
+			return
+		}
+	} else {
+		var namespace, name string
+		if kind.Namespaced && len(parts) == 2 {
+			namespace, name = parts[0], parts[1]
+		} else if !kind.Namespaced && len(parts) == 1 {
+			name = parts[0]
+		} else {
+			registry.WriteError(c, registry.NewNotFound(kind.Kind, "", strings.Join(parts, "/")))
+			return
+		}
+
+		c.Params = append(c.Params, gin.Param{Key: "namespace", Value: namespace}, gin.Param{Key: "name", Value: name})
+		switch c.Request.Method {
+		case http.MethodGet:
+			registry.HandleGet(kind)(c)
+			return
+		case http.MethodPut:
+			registry.HandleUpdate(kind)(c)
+			return
+		case http.MethodPatch:
+			registry.HandlePatch(kind)(c)
+			return
+		case http.MethodDelete:
+			registry.HandleDelete(kind)(c)
+			return
+		}
+	}
+	registry.WriteError(c, httputil.NewMethodNotSupported(c.Request.Method, c.Request.URL.Path))
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/etcd"
+	"minik8s/apiserver/src/registry"
+	"minik8s/apiserver/src/url"
+	"minik8s/util/httputil"
+	"minik8s/util/logger"
+	"net/http"
+	"time"
+)
+
+// EventTTL is how long an event is kept after it last happened
+const EventTTL = time.Hour
+
+// maxRecordAttempts is how many times an event is counted again when another
+// report of it comes in between.
+const maxRecordAttempts = 3
+
+var eventKind = &registry.Kind{
+	Kind:       "Event",
+	Prefix:     url.EventURL,
+	Namespaced: true,
+	New:        func() apiObject.Object { return &apiObject.Event{} },
+	ApiVersion: "v1",
+	Hooks: registry.Hooks{
+		Validate: func(obj apiObject.Object) error {
+			event := obj.(*apiObject.Event)
+			if event.InvolvedObject.Kind == "" || event.InvolvedObject.Name == "" {
+				return fmt.Errorf("involvedObject.kind and involvedObject.name are required")
+			}
+			if event.Reason == "" {
+				return fmt.Errorf("reason is required")
+			}
+			if event.Type != apiObject.EventTypeNormal && event.Type != apiObject.EventTypeWarning {
+				return fmt.Errorf("type must be %s or %s", apiObject.EventTypeNormal, apiObject.EventTypeWarning)
+			}
+			return nil
+		},
+	},
+}
+
+// HandleRecordEvent serves POST /api/v1/events/, which the components report
+// events to. An event that was reported before is counted instead of stored
+// again, it answers 201 Created for a new event and 200 otherwise.
+func HandleRecordEvent(c *gin.Context) {
+	event := &apiObject.Event{}
+	if err := httputil.ReadAndUnmarshal(c.Request.Body, event); err != nil {
+		registry.WriteError(c, httputil.NewBadRequest(err.Error()))
+		return
+	}
+	recorded, created, err := recordEvent(event)
+	if err != nil {
+		registry.WriteError(c, err)
+		return
+	}
+	if created {
+		c.JSON(http.StatusCreated, recorded)
+	} else {
+		c.JSON(http.StatusOK, recorded)
+	}
+}
+
+// recordEvent stores the event under its key, in the namespace of the object
+// it is about, or adds one to the count of the stored one.
+func recordEvent(event *apiObject.Event) (recorded *apiObject.Event, created bool, err error) {
+	now := time.Now()
+	if event.LastTimestamp.IsZero() {
+		event.LastTimestamp = now
+	}
+	if event.FirstTimestamp.IsZero() {
+		event.FirstTimestamp = event.LastTimestamp
+	}
+	if event.Count == 0 {
+		event.Count = 1
+	}
+	event.Kind = eventKind.Kind
+	event.Metadata.Namespace = event.InvolvedObject.Namespace
+	if event.Metadata.Namespace == "" {
+		event.Metadata.Namespace = apiObject.DefaultNamespace
+	}
+	event.Metadata.Name = event.Key()
+
+	for attempt := 1; ; attempt++ {
+		var obj apiObject.Object
+		obj, err = eventKind.Get(event.Metadata.Namespace, event.Metadata.Name)
+		if registry.IsNotFound(err) {
+			if err = eventKind.Create(event); !registry.IsAlreadyExists(err) {
+				return event, err == nil, err
+			}
+		} else if err != nil {
+			return nil, false, err
+		} else {
+			stored := obj.(*apiObject.Event)
+			stored.Count += event.Count
+			if event.LastTimestamp.After(stored.LastTimestamp) {
+				stored.LastTimestamp = event.LastTimestamp
+			}
+			if err = eventKind.Update(stored); err != etcd.ErrConflict {
+				return stored, false, err
+			}
+		}
+		// Another report of the event came in between
+		if attempt == maxRecordAttempts {
+			return nil, false, err
+		}
+	}
+}
+
+// PurgeEvents deletes the events that last happened more than EventTTL ago
+func PurgeEvents() {
+	objs, _, err := eventKind.List()
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+	for _, obj := range objs {
+		event := obj.(*apiObject.Event)
+		if time.Since(event.LastTimestamp) < EventTTL {
+			continue
+		}
+		_, err = eventKind.Delete(event.Namespace(), event.Name(), registry.DeleteOptions{ResourceVersion: event.Metadata.ResourceVersion})
+		if err != nil && !registry.IsNotFound(err) && err != etcd.ErrConflict {
+			logger.Error(err.Error())
+		}
+	}
+}
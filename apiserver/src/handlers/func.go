@@ -2,14 +2,15 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
 	"github.com/gin-gonic/gin"
 	"minik8s/apiObject"
 	"minik8s/apiserver/src/etcd"
+	"minik8s/apiserver/src/registry"
 	"minik8s/apiserver/src/url"
 	"minik8s/entity"
 	"minik8s/listwatch"
 	"minik8s/util/httputil"
+	"minik8s/util/schemautil"
 	"minik8s/util/topicutil"
 	"net/http"
 	"path"
@@ -18,7 +19,12 @@ import (
 func HandleApplyFunc(c *gin.Context) {
 	apiFunc := apiObject.Function{}
 	if err := httputil.ReadAndUnmarshal(c.Request.Body, &apiFunc); err != nil {
-		c.String(http.StatusOK, err.Error())
+		registry.WriteError(c, httputil.NewBadRequest(err.Error()))
+		return
+	}
+	// the name of a function names its replica set, pods and containers too
+	if err := registry.ValidateName("Function", "", apiFunc.Name, schemautil.FormatDNS1123Label); err != nil {
+		registry.WriteError(c, err)
 		return
 	}
 
@@ -26,14 +32,14 @@ func HandleApplyFunc(c *gin.Context) {
 	if raw, err := etcd.Get(etcdURL); err == nil {
 		oldFunc := apiObject.Function{}
 		if err = json.Unmarshal([]byte(raw), &oldFunc); err == nil {
-			c.String(http.StatusOK, fmt.Sprintf("function %s already exists", apiFunc.Name))
+			registry.WriteError(c, registry.NewAlreadyExists("Function", "", apiFunc.Name))
 			return
 		}
 	}
 
 	functionJson, _ := json.Marshal(apiFunc)
 	if err := etcd.Put(etcdURL, string(functionJson)); err != nil {
-		c.String(http.StatusOK, err.Error())
+		registry.WriteError(c, err)
 		return
 	}
 
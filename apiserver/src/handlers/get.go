@@ -5,27 +5,17 @@ import (
 	"github.com/gin-gonic/gin"
 	"minik8s/apiObject"
 	"minik8s/apiserver/src/etcd"
-	"minik8s/apiserver/src/helper"
+	"minik8s/apiserver/src/registry"
 	"minik8s/apiserver/src/url"
 	"minik8s/entity"
 	"minik8s/kubelet/src/runtime/runtime"
 	"minik8s/util/logger"
+	"minik8s/util/selectorutil"
 	"net/http"
 	"path"
+	"reflect"
 )
 
-func getNodeStatusesFromEtcd() (nodes []*entity.NodeStatus) {
-	etcdURL := path.Join(url.NodeURL, "status")
-	raws, err := etcd.GetAll(etcdURL)
-	for _, raw := range raws {
-		node := &entity.NodeStatus{}
-		if err = json.Unmarshal([]byte(raw), &node); err == nil {
-			nodes = append(nodes, node)
-		}
-	}
-	return
-}
-
 func getNodeStatusFromEtcd(namespace, name string) (node *entity.NodeStatus) {
 	etcdURL := path.Join(url.NodeURL, "status", namespace, name)
 	if raw, err := etcd.Get(etcdURL); err == nil {
@@ -71,18 +61,6 @@ func getReplicaSetStatusFromEtcd(namespace, name string) (replicaSet *entity.Rep
 	return nil
 }
 
-func getReplicaSetStatusesFromEtcd() (replicaSets []*entity.ReplicaSetStatus) {
-	etcdURL := path.Join(url.ReplicaSetURL, "status")
-	raws, err := etcd.GetAll(etcdURL)
-	for _, raw := range raws {
-		replicaSet := &entity.ReplicaSetStatus{}
-		if err = json.Unmarshal([]byte(raw), &replicaSet); err == nil {
-			replicaSets = append(replicaSets, replicaSet)
-		}
-	}
-	return
-}
-
 func getHPAStatusFromEtcd(namespace, name string) (hpa *entity.HPAStatus) {
 	etcdURL := path.Join(url.HPAURL, "status", namespace, name)
 	if raw, err := etcd.Get(etcdURL); err == nil {
@@ -94,123 +72,44 @@ func getHPAStatusFromEtcd(namespace, name string) (hpa *entity.HPAStatus) {
 	return nil
 }
 
-func getHPAStatusesFromEtcd() (hpas []*entity.HPAStatus) {
-	etcdURL := path.Join(url.HPAURL, "status")
-	raws, err := etcd.GetAll(etcdURL)
-	for _, raw := range raws {
-		hpa := &entity.HPAStatus{}
-		if err = json.Unmarshal([]byte(raw), &hpa); err == nil {
-			hpas = append(hpas, hpa)
-		}
-	}
-	return
-}
-
 func getPodApiObjectFromEtcd(node, namespace, name string) (pod *apiObject.Pod) {
 	etcdURL := path.Join(url.PodURL, node, namespace, name)
-	if raw, err := etcd.Get(etcdURL); err == nil {
-		if err = json.Unmarshal([]byte(raw), &pod); err == nil {
-			return pod
-		}
+	pod = &apiObject.Pod{}
+	if revision, err := registry.GetObject(etcdURL, pod); err == nil && revision != 0 {
+		return pod
 	}
 	return nil
 }
 
 func getReplicaSetApiObjectFromEtcd(namespace, name string) (replicaSet *apiObject.ReplicaSet) {
-	etcdURL := path.Join(url.ReplicaSetURL, namespace, name)
-	if raw, err := etcd.Get(etcdURL); err == nil {
-		if err = json.Unmarshal([]byte(raw), &replicaSet); err == nil {
-			return replicaSet
-		}
+	if obj, err := replicaSetKind.Get(namespace, name); err == nil {
+		return obj.(*apiObject.ReplicaSet)
 	}
 	return nil
 }
 
-func getHPAApiObjectFromEtcd(namespace, name string) (hpa *apiObject.HorizontalPodAutoscaler) {
-	etcdURL := path.Join(url.HPAURL, namespace, name)
-	if raw, err := etcd.Get(etcdURL); err == nil {
-		if err = json.Unmarshal([]byte(raw), &hpa); err == nil {
-			return hpa
-		}
+// getFuncPodsFromEtcd gets the pods of the function name. Those of a function
+// deployed before they were labeled by FunctionLabel are found by the uid of
+// its ReplicaSet instead.
+func getFuncPodsFromEtcd(name string) []*entity.PodStatus {
+	pods := selectPodStatuses(map[string]string{apiObject.FunctionLabel: name})
+	if len(pods) > 0 {
+		return pods
 	}
-	return nil
-}
-
-func getGpuApiObjectFromEtcd(namespace, name string) (gpu *apiObject.GpuJob) {
-	etcdURL := path.Join(url.GpuURL, namespace, name)
-	if raw, err := etcd.Get(etcdURL); err == nil {
-		if err = json.Unmarshal([]byte(raw), &gpu); err == nil {
-			return gpu
-		}
+	if replicaSet := getReplicaSetApiObjectFromEtcd("function", name); replicaSet != nil {
+		return selectPodStatuses(map[string]string{runtime.KubernetesReplicaSetUIDLabel: replicaSet.UID()})
 	}
 	return nil
 }
 
-func getServiceFromEtcd(namespace, name string) (service *apiObject.Service) {
-	etcdURL := path.Join(url.ServiceURL, namespace, name)
-	if raw, err := etcd.Get(etcdURL); err == nil {
-		if err = json.Unmarshal([]byte(raw), &service); err == nil {
-			return service
-		}
-		logger.Error(err.Error())
-	}
-	return nil
-}
-
-func getServicesFromEtcd() (services []apiObject.Service) {
-	etcdURL := url.ServiceURL
-	services = make([]apiObject.Service, 0)
-	vis := make(map[string]bool)
-	if raws, err := etcd.GetAll(etcdURL); err == nil {
-		for _, raw := range raws {
-			service := apiObject.Service{}
-			if err = json.Unmarshal([]byte(raw), &service); err == nil {
-				if !vis[service.Metadata.UID] {
-					services = append(services, service)
-					vis[service.Metadata.UID] = true
-				}
-			} else {
-				logger.Error(err.Error())
-			}
-		}
-	}
-	return services
-}
-
-func getFuncPodsFromEtcd(name string) (pods []entity.PodStatus) {
-	// step 1: get replicaSet
-	replicaSet := getReplicaSetApiObjectFromEtcd("function", name)
-	if replicaSet == nil {
-		return
-	}
-	replicaSetUID := replicaSet.UID()
-	log("Got replicaSet for func %s, UID = %s", name, replicaSetUID)
-	etcdPodURL := path.Join(url.PodURL, "status", "function")
-	if raws, err := etcd.GetAll(etcdPodURL); err == nil {
-		for _, raw := range raws {
-			pod := entity.PodStatus{}
-			if err = json.Unmarshal([]byte(raw), &pod); err == nil {
-				if pod.Labels[runtime.KubernetesReplicaSetUIDLabel] == replicaSetUID {
-					log("pod labels: %v", pod.Labels)
-					pods = append(pods, pod)
-				}
-			} else {
-				logger.Error(err.Error())
-			}
-		}
-	}
-	return pods
-}
-
-func getDNSFromEtcd(namespace, name string) (dns *apiObject.Dns) {
-	etcdURL := path.Join(url.DNSURL, namespace, name)
-	if raw, err := etcd.Get(etcdURL); err == nil {
-		if err = json.Unmarshal([]byte(raw), &dns); err == nil {
-			return dns
+func selectPodStatuses(labels map[string]string) (pods []*entity.PodStatus) {
+	selector := selectorutil.FromSet(labels)
+	for _, pod := range getPodStatusesFromEtcd() {
+		if selector.Matches(pod.Labels) {
+			pods = append(pods, pod)
 		}
-		logger.Error(err.Error())
 	}
-	return nil
+	return
 }
 
 func getWorkflowResultFromEtcd(namespace, name string) (result *entity.FunctionTriggerResult) {
@@ -224,42 +123,6 @@ func getWorkflowResultFromEtcd(namespace, name string) (result *entity.FunctionT
 	return nil
 }
 
-func getWorkflowResultsFromEtcd() (results []*entity.FunctionTriggerResult) {
-	etcdURL := path.Join(url.WorkflowURL, "result")
-	if raws, err := etcd.GetAll(etcdURL); err == nil {
-		for _, raw := range raws {
-			result := entity.FunctionTriggerResult{}
-			log("workflow raw = %s", raw)
-			if err = json.Unmarshal([]byte(raw), &result); err == nil {
-				results = append(results, &result)
-			} else {
-				logger.Error(err.Error())
-			}
-		}
-	}
-	return
-}
-
-func getDNSesFromEtcd() (dnses []apiObject.Dns) {
-	etcdURL := url.DNSURL
-	dnses = make([]apiObject.Dns, 0)
-	vis := make(map[string]bool)
-	if raws, err := etcd.GetAll(etcdURL); err == nil {
-		for _, raw := range raws {
-			dns := apiObject.Dns{}
-			if err = json.Unmarshal([]byte(raw), &dns); err == nil {
-				if !vis[dns.Metadata.UID] {
-					dnses = append(dnses, dns)
-					vis[dns.Metadata.UID] = true
-				}
-			} else {
-				logger.Error(err.Error())
-			}
-		}
-	}
-	return dnses
-}
-
 func getFunctionInstances(function string) int {
 	return len(getFuncPodsFromEtcd(function))
 }
@@ -280,22 +143,6 @@ func getFunctionFromEtcd(name string) *entity.FunctionStatus {
 	return nil
 }
 
-func getFunctionsFromEtcd() (functionStatuses []*entity.FunctionStatus) {
-	etcdURL := url.FuncURL
-	raws, err := etcd.GetAll(etcdURL)
-	for _, raw := range raws {
-		function := apiObject.Function{}
-		if err = json.Unmarshal([]byte(raw), &function); err == nil {
-			functionStatuses = append(functionStatuses, &entity.FunctionStatus{
-				Name:      function.Name,
-				Instances: getFunctionInstances(function.Name),
-				CodePath:  function.Path,
-			})
-		}
-	}
-	return
-}
-
 func getGpuJobFromEtcd(namespace, name string) (gpu *entity.GpuJobStatus) {
 	etcdURL := path.Join(url.GpuURL, "status", namespace, name)
 	if raw, err := etcd.Get(etcdURL); err == nil {
@@ -307,46 +154,84 @@ func getGpuJobFromEtcd(namespace, name string) (gpu *entity.GpuJobStatus) {
 	return nil
 }
 
-func getGpuJobsFromEtcd() (gpus []*entity.GpuJobStatus) {
-	etcdURL := path.Join(url.GpuURL, "status")
-	raws, err := etcd.GetAll(etcdURL)
-	for _, raw := range raws {
-		gpu := entity.GpuJobStatus{}
-		if err = json.Unmarshal([]byte(raw), &gpu); err == nil {
-			gpus = append(gpus, &gpu)
+// writePage answers the page a list request asks for of the values under
+// prefix, which decode turns into the listed objects.
+func writePage(c *gin.Context, prefix string, decode func(kv etcd.KeyValue) (interface{}, bool)) {
+	opts, err := registry.ListOptionsFrom(c)
+	if err != nil {
+		registry.WriteError(c, err)
+		return
+	}
+	objs, meta, err := registry.ListPage(prefix, opts, decode)
+	if err != nil {
+		registry.WriteError(c, err)
+		return
+	}
+	registry.WritePage(c, objs, meta)
+}
+
+// decodeInto is the decode of writePage for values stored as they are listed
+func decodeInto(newObject func() interface{}) func(kv etcd.KeyValue) (interface{}, bool) {
+	return func(kv etcd.KeyValue) (interface{}, bool) {
+		obj := newObject()
+		if err := json.Unmarshal([]byte(kv.Value), obj); err != nil {
+			logger.Error(err.Error())
+			return nil, false
 		}
+		return obj, true
 	}
-	return
+}
+
+func decodeFunction(kv etcd.KeyValue) (interface{}, bool) {
+	function := apiObject.Function{}
+	if err := json.Unmarshal([]byte(kv.Value), &function); err != nil {
+		logger.Error(err.Error())
+		return nil, false
+	}
+	return &entity.FunctionStatus{
+		Name:      function.Name,
+		Instances: getFunctionInstances(function.Name),
+		CodePath:  function.Path,
+	}, true
+}
+
+// writeObject answers obj, or NotFound if it is a nil pointer
+func writeObject(c *gin.Context, obj interface{}, kind, namespace, name string) {
+	if value := reflect.ValueOf(obj); value.Kind() == reflect.Ptr && value.IsNil() {
+		registry.WriteError(c, registry.NewNotFound(kind, namespace, name))
+		return
+	}
+	c.JSON(http.StatusOK, obj)
 }
 
 func HandleGetNodeStatus(c *gin.Context) {
 	namespace := c.Param("namespace")
 	name := c.Param("name")
-	c.JSON(http.StatusOK, getNodeStatusFromEtcd(namespace, name))
+	writeObject(c, getNodeStatusFromEtcd(namespace, name), "Node", namespace, name)
 }
 
 func HandleGetNodeStatuses(c *gin.Context) {
-	c.JSON(http.StatusOK, getNodeStatusesFromEtcd())
+	writePage(c, path.Join(url.NodeURL, "status"), decodeInto(func() interface{} { return &entity.NodeStatus{} }))
 }
 
 func HandleGetPodStatus(c *gin.Context) {
 	namespace := c.Param("namespace")
 	name := c.Param("name")
-	c.JSON(http.StatusOK, getPodStatusFromEtcd(namespace, name))
+	writeObject(c, getPodStatusFromEtcd(namespace, name), "Pod", namespace, name)
 }
 
 func HandleGetPodStatuses(c *gin.Context) {
-	c.JSON(http.StatusOK, getPodStatusesFromEtcd())
+	writePage(c, path.Join(url.PodURL, "status"), decodeInto(func() interface{} { return &entity.PodStatus{} }))
 }
 
 func HandleGetReplicaSetStatus(c *gin.Context) {
 	namespace := c.Param("namespace")
 	name := c.Param("name")
-	c.JSON(http.StatusOK, getReplicaSetStatusFromEtcd(namespace, name))
+	writeObject(c, getReplicaSetStatusFromEtcd(namespace, name), "ReplicaSet", namespace, name)
 }
 
 func HandleGetReplicaSetStatuses(c *gin.Context) {
-	c.JSON(http.StatusOK, getReplicaSetStatusesFromEtcd())
+	writePage(c, path.Join(url.ReplicaSetURL, "status"), decodeInto(func() interface{} { return &entity.ReplicaSetStatus{} }))
 }
 
 func HandleDescribePod(c *gin.Context) {
@@ -358,92 +243,49 @@ func HandleGetPodApiObject(c *gin.Context) {
 	node := c.Param("node")
 	namespace := c.Param("namespace")
 	name := c.Param("name")
-	c.JSON(http.StatusOK, getPodApiObjectFromEtcd(node, namespace, name))
+	writeObject(c, getPodApiObjectFromEtcd(node, namespace, name), "Pod", namespace, name)
 }
 
 func HandleGetPodsApiObject(c *gin.Context) {
 	node := c.Param("node")
-	c.JSON(http.StatusOK, helper.GetPodsApiObjectFromEtcd(node))
-}
-
-func HandleGetReplicaSetApiObject(c *gin.Context) {
-	namespace := c.Param("namespace")
-	name := c.Param("name")
-	c.JSON(http.StatusOK, getReplicaSetApiObjectFromEtcd(namespace, name))
-}
-
-func HandleGetHPAApiObject(c *gin.Context) {
-	namespace := c.Param("namespace")
-	name := c.Param("name")
-	c.JSON(http.StatusOK, getHPAApiObjectFromEtcd(namespace, name))
-}
-
-func HandleGetGpuApiObject(c *gin.Context) {
-	namespace := c.Param("namespace")
-	name := c.Param("name")
-	c.JSON(http.StatusOK, getGpuApiObjectFromEtcd(namespace, name))
+	writePage(c, path.Join(url.PodURL, node)+"/", decodeInto(func() interface{} { return &apiObject.Pod{} }))
 }
 
 func HandleGetHPAStatus(c *gin.Context) {
 	namespace := c.Param("namespace")
 	name := c.Param("name")
-	c.JSON(http.StatusOK, getHPAStatusFromEtcd(namespace, name))
+	writeObject(c, getHPAStatusFromEtcd(namespace, name), "HorizontalPodAutoscaler", namespace, name)
 }
 
 func HandleGetHPAStatuses(c *gin.Context) {
-	c.JSON(http.StatusOK, getHPAStatusesFromEtcd())
-}
-
-func HandleGetService(c *gin.Context) {
-	namespace := c.Param("namespace")
-	name := c.Param("name")
-	c.JSON(http.StatusOK, getServiceFromEtcd(namespace, name))
-}
-
-func HandleGetServices(c *gin.Context) {
-	c.JSON(http.StatusOK, getServicesFromEtcd())
-}
-
-func HandleGetFuncPods(c *gin.Context) {
-	name := c.Param("name")
-	c.JSON(http.StatusOK, getFuncPodsFromEtcd(name))
+	writePage(c, path.Join(url.HPAURL, "status"), decodeInto(func() interface{} { return &entity.HPAStatus{} }))
 }
 
 func HandleGetWorkflowResult(c *gin.Context) {
 	namespace := c.Param("namespace")
 	name := c.Param("name")
-	c.JSON(http.StatusOK, getWorkflowResultFromEtcd(namespace, name))
+	writeObject(c, getWorkflowResultFromEtcd(namespace, name), "WorkflowResult", namespace, name)
 }
 
 func HandleGetWorkflowResults(c *gin.Context) {
-	c.JSON(http.StatusOK, getWorkflowResultsFromEtcd())
-}
-
-func HandleGetDNS(c *gin.Context) {
-	namespace := c.Param("namespace")
-	name := c.Param("name")
-	c.JSON(http.StatusOK, getDNSFromEtcd(namespace, name))
-}
-
-func HandleGetDNSes(c *gin.Context) {
-	c.JSON(http.StatusOK, getDNSesFromEtcd())
+	writePage(c, path.Join(url.WorkflowURL, "result"), decodeInto(func() interface{} { return &entity.FunctionTriggerResult{} }))
 }
 
 func HandleGetFunction(c *gin.Context) {
 	name := c.Param("name")
-	c.JSON(http.StatusOK, getFunctionFromEtcd(name))
+	writeObject(c, getFunctionFromEtcd(name), "Function", "", name)
 }
 
 func HandleGetFunctions(c *gin.Context) {
-	c.JSON(http.StatusOK, getFunctionsFromEtcd())
+	writePage(c, url.FuncURL, decodeFunction)
 }
 
 func HandleGetGpuJob(c *gin.Context) {
 	namespace := c.Param("namespace")
 	name := c.Param("name")
-	c.JSON(http.StatusOK, getGpuJobFromEtcd(namespace, name))
+	writeObject(c, getGpuJobFromEtcd(namespace, name), "GpuJob", namespace, name)
 }
 
 func HandleGetGpuJobs(c *gin.Context) {
-	c.JSON(http.StatusOK, getGpuJobsFromEtcd())
+	writePage(c, path.Join(url.GpuURL, "status"), decodeInto(func() interface{} { return &entity.GpuJobStatus{} }))
 }
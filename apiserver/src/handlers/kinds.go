@@ -0,0 +1,629 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"minik8s/apiObject"
+	autoscalingv2 "minik8s/apiObject/autoscaling/v2"
+	dns2 "minik8s/apiserver/src/dns"
+	"minik8s/apiserver/src/etcd"
+	"minik8s/apiserver/src/helper"
+	"minik8s/apiserver/src/registry"
+	"minik8s/apiserver/src/url"
+	hpaController "minik8s/controller/src/controller/hpa"
+	"minik8s/entity"
+	"minik8s/listwatch"
+	"minik8s/nginx"
+	"minik8s/util/certutil"
+	"minik8s/util/configutil"
+	"minik8s/util/httputil"
+	"minik8s/util/logger"
+	"minik8s/util/topicutil"
+	"minik8s/util/weaveutil"
+	"path"
+	"reflect"
+	"time"
+)
+
+// The kinds below are served by the generic registry, their hooks keep the
+// statuses and the other components in sync. Pods are scheduled before they
+// are stored and functions have no metadata, so both keep their own handlers.
+
+var nodeKind = &registry.Kind{
+	Kind:       "Node",
+	Prefix:     url.NodeURL,
+	Namespaced: true,
+	New:        func() apiObject.Object { return &apiObject.Node{} },
+	ApiVersion: "v1",
+	Hooks: registry.Hooks{
+		AfterCreate: func(obj apiObject.Object) {
+			node := obj.(*apiObject.Node)
+			putStatus(path.Join(url.NodeURL, "status", node.Namespace(), node.Name()), entity.NodeStatus{
+				Hostname:   node.Name(),
+				Ip:         node.Ip,
+				Labels:     node.Labels(),
+				Lifecycle:  entity.NodeUnknown,
+				Error:      "",
+				CpuPercent: 0,
+				MemPercent: 0,
+				NumPods:    0,
+				SyncTime:   time.Now(),
+			})
+		},
+		AfterDelete: func(obj apiObject.Object) {
+			_ = etcd.Delete(path.Join(url.NodeURL, "status", obj.Meta().Namespace, obj.Meta().Name))
+		},
+	},
+}
+
+var replicaSetKind = &registry.Kind{
+	Kind:       "ReplicaSet",
+	Prefix:     url.ReplicaSetURL,
+	Namespaced: true,
+	New:        func() apiObject.Object { return &apiObject.ReplicaSet{} },
+	ApiVersion: "apps/v1",
+	Hooks: registry.Hooks{
+		AfterCreate: func(obj apiObject.Object) {
+			rs := obj.(*apiObject.ReplicaSet)
+			putStatus(path.Join(url.ReplicaSetURL, "status", rs.Namespace(), rs.Name()), entity.ReplicaSetStatus{
+				ID:         rs.UID(),
+				Name:       rs.Name(),
+				Namespace:  rs.Namespace(),
+				Labels:     rs.Labels(),
+				Lifecycle:  entity.ReplicaSetUnknown,
+				CpuPercent: 0,
+				MemPercent: 0,
+				Error:      "",
+				SyncTime:   time.Now(),
+			})
+			publishReplicaSetUpdate(entity.CreateAction, rs)
+		},
+		AfterUpdate: func(obj, old apiObject.Object) {
+			publishReplicaSetUpdate(entity.UpdateAction, obj.(*apiObject.ReplicaSet))
+		},
+		AfterDelete: func(obj apiObject.Object) {
+			rs := obj.(*apiObject.ReplicaSet)
+			_ = etcd.Delete(path.Join(url.ReplicaSetURL, "status", rs.Namespace(), rs.Name()))
+			publishReplicaSetUpdate(entity.DeleteAction, rs)
+		},
+	},
+}
+
+var deploymentKind = &registry.Kind{
+	Kind:       "Deployment",
+	Prefix:     url.DeploymentURL,
+	Namespaced: true,
+	New:        func() apiObject.Object { return &apiObject.Deployment{} },
+	ApiVersion: "apps/v1",
+	Hooks: registry.Hooks{
+		PrepareForCreate: func(obj apiObject.Object) error {
+			deployment := obj.(*apiObject.Deployment)
+			if deployment.Spec.Strategy.Type == "" {
+				deployment.Spec.Strategy.Type = apiObject.RollingUpdateDeploymentStrategyType
+			}
+			// The status is the controller's
+			deployment.Status = apiObject.DeploymentStatus{}
+			return nil
+		},
+		PrepareForUpdate: func(obj, old apiObject.Object) error {
+			deployment := obj.(*apiObject.Deployment)
+			if deployment.Spec.Strategy.Type == "" {
+				deployment.Spec.Strategy.Type = apiObject.RollingUpdateDeploymentStrategyType
+			}
+			return nil
+		},
+		AfterCreate: func(obj apiObject.Object) {
+			publishDeploymentUpdate(entity.CreateAction, obj.(*apiObject.Deployment))
+		},
+		AfterUpdate: func(obj, old apiObject.Object) {
+			publishDeploymentUpdate(entity.UpdateAction, obj.(*apiObject.Deployment))
+		},
+		AfterDelete: func(obj apiObject.Object) {
+			publishDeploymentUpdate(entity.DeleteAction, obj.(*apiObject.Deployment))
+		},
+	},
+}
+
+var hpaKind = &registry.Kind{
+	Kind:       "HorizontalPodAutoscaler",
+	Prefix:     url.HPAURL,
+	Namespaced: true,
+	New:        func() apiObject.Object { return &apiObject.HorizontalPodAutoscaler{} },
+	ApiVersion: "autoscaling/v1",
+	Versions: []*registry.Version{{
+		ApiVersion: autoscalingv2.GroupVersion,
+		New:        func() apiObject.Convertible { return &autoscalingv2.HorizontalPodAutoscaler{} },
+	}},
+	StorageVersion: autoscalingv2.GroupVersion,
+	Hooks: registry.Hooks{
+		PrepareForCreate: func(obj apiObject.Object) error {
+			return prepareHPA(obj.(*apiObject.HorizontalPodAutoscaler))
+		},
+		PrepareForUpdate: func(obj, old apiObject.Object) error {
+			return prepareHPA(obj.(*apiObject.HorizontalPodAutoscaler))
+		},
+		AfterCreate: func(obj apiObject.Object) {
+			hpa := obj.(*apiObject.HorizontalPodAutoscaler)
+			putStatus(path.Join(url.HPAURL, "status", hpa.Namespace(), hpa.Name()), entity.HPAStatus{
+				ID:        hpa.UID(),
+				Name:      hpa.Name(),
+				Namespace: hpa.Namespace(),
+				Labels:    hpa.Labels(),
+				Lifecycle: entity.HPACreated,
+				Metrics:   "Unknown",
+				Benchmark: 0,
+				Error:     "",
+				SyncTime:  time.Now(),
+			})
+			publishHPAUpdate(entity.CreateAction, hpa)
+		},
+		AfterUpdate: func(obj, old apiObject.Object) {
+			publishHPAUpdate(entity.UpdateAction, obj.(*apiObject.HorizontalPodAutoscaler))
+		},
+		AfterDelete: func(obj apiObject.Object) {
+			hpa := obj.(*apiObject.HorizontalPodAutoscaler)
+			_ = etcd.Delete(path.Join(url.HPAURL, "status", hpa.Namespace(), hpa.Name()))
+			publishHPAUpdate(entity.DeleteAction, hpa)
+		},
+	},
+}
+
+var gpuJobKind = &registry.Kind{
+	Kind:       "GpuJob",
+	Prefix:     url.GpuURL,
+	Namespaced: true,
+	New:        func() apiObject.Object { return &apiObject.GpuJob{} },
+	ApiVersion: "v1",
+	Hooks: registry.Hooks{
+		AfterCreate: func(obj apiObject.Object) {
+			gpu := obj.(*apiObject.GpuJob)
+			putStatus(path.Join(url.GpuURL, "status", gpu.Namespace(), gpu.Name()), entity.GpuJobStatus{
+				Namespace:    gpu.Namespace(),
+				Name:         gpu.Name(),
+				State:        "Unknown",
+				LastSyncTime: time.Now(),
+			})
+			publishGpuUpdate(entity.CreateAction, gpu)
+		},
+		AfterUpdate: func(obj, old apiObject.Object) {
+			publishGpuUpdate(entity.UpdateAction, obj.(*apiObject.GpuJob))
+		},
+		AfterDelete: func(obj apiObject.Object) {
+			gpu := obj.(*apiObject.GpuJob)
+			_ = etcd.Delete(path.Join(url.GpuURL, "status", gpu.Namespace(), gpu.Name()))
+			publishGpuUpdate(entity.DeleteAction, gpu)
+		},
+	},
+}
+
+var workflowKind = &registry.Kind{
+	Kind:       "Workflow",
+	Prefix:     url.WorkflowURL,
+	Namespaced: true,
+	New:        func() apiObject.Object { return &apiObject.Workflow{} },
+	ApiVersion: "v1",
+	Hooks: registry.Hooks{
+		AfterCreate: func(obj apiObject.Object) {
+			wf := obj.(*apiObject.Workflow)
+			putStatus(path.Join(url.WorkflowURL, "result", wf.Namespace(), wf.Name()), entity.FunctionTriggerResult{
+				WorkflowNamespace: wf.Namespace(),
+				WorkflowName:      wf.Name(),
+				Data:              "",
+				Time:              time.Now(),
+				Status:            entity.TriggerUnknown,
+				Error:             "",
+				FinishedAll:       false,
+			})
+			publishWorkflowUpdate(entity.CreateAction, wf)
+		},
+		AfterUpdate: func(obj, old apiObject.Object) {
+			publishWorkflowUpdate(entity.UpdateAction, obj.(*apiObject.Workflow))
+		},
+		AfterDelete: func(obj apiObject.Object) {
+			wf := obj.(*apiObject.Workflow)
+			_ = etcd.Delete(path.Join(url.WorkflowURL, "result", wf.Namespace(), wf.Name()))
+			publishWorkflowUpdate(entity.DeleteAction, wf)
+		},
+	},
+}
+
+var serviceKind = &registry.Kind{
+	Kind:       "Service",
+	Prefix:     url.ServiceURL,
+	Namespaced: true,
+	New:        func() apiObject.Object { return &apiObject.Service{} },
+	ApiVersion: "v1",
+	Hooks: registry.Hooks{
+		BeforeCreate: func(obj apiObject.Object) (err error) {
+			service := obj.(*apiObject.Service)
+			service.Spec.ClusterIP, err = helper.NewServiceIp()
+			return
+		},
+		AbortCreate: func(obj apiObject.Object) {
+			if ip := obj.(*apiObject.Service).Spec.ClusterIP; ip != "" {
+				if err := helper.ReleaseServiceIp(ip); err != nil {
+					logger.Error(err.Error())
+				}
+			}
+		},
+		PrepareForUpdate: func(obj, old apiObject.Object) error {
+			// The proxy keeps serving the service on its cluster ip
+			obj.(*apiObject.Service).Spec.ClusterIP = old.(*apiObject.Service).Spec.ClusterIP
+			return nil
+		},
+		AfterCreate: func(obj apiObject.Object) {
+			service := obj.(*apiObject.Service)
+			indexService(service)
+			publishServiceUpdate(entity.CreateAction, service)
+		},
+		AfterUpdate: func(obj, old apiObject.Object) {
+			service := obj.(*apiObject.Service)
+			unindexService(old.(*apiObject.Service))
+			indexService(service)
+			publishServiceUpdate(entity.UpdateAction, service)
+		},
+		AfterDelete: func(obj apiObject.Object) {
+			service := obj.(*apiObject.Service)
+			publishServiceUpdate(entity.DeleteAction, service)
+			unindexService(service)
+		},
+	},
+}
+
+var dnsKind = &registry.Kind{
+	Kind:       "Dns",
+	Prefix:     url.DNSURL,
+	Namespaced: true,
+	New:        func() apiObject.Object { return &apiObject.Dns{} },
+	ApiVersion: "v1",
+	Hooks: registry.Hooks{
+		PrepareForCreate: func(obj apiObject.Object) error {
+			obj.(*apiObject.Dns).Status = apiObject.DnsStatus{}
+			return nil
+		},
+		BeforeCreate: func(obj apiObject.Object) error {
+			return startDNS(obj.(*apiObject.Dns))
+		},
+		AbortCreate: func(obj apiObject.Object) {
+			abortDNS(obj.(*apiObject.Dns))
+		},
+		PrepareForUpdate: func(obj, old apiObject.Object) error {
+			dns := obj.(*apiObject.Dns)
+			dns.Status = old.(*apiObject.Dns).Status
+			if !dnsChanged(dns, old.(*apiObject.Dns)) {
+				return nil
+			}
+			// The services of the new paths must exist
+			_, err := dnsServers(dns)
+			return err
+		},
+		AfterUpdate: func(obj, old apiObject.Object) {
+			if err := updateDNS(obj.(*apiObject.Dns), old.(*apiObject.Dns)); err != nil {
+				logger.Error(err.Error())
+			}
+		},
+		AfterDelete: func(obj apiObject.Object) {
+			dns := obj.(*apiObject.Dns)
+			if err := nginx.New(dns.Metadata.UID).Shutdown(); err != nil {
+				logger.Error(err.Error())
+			}
+			if err := dns2.New(path.Join(url.DNSDirPath, url.DNSHostsFileName)).DeleteIfExistEntry(dns.Spec.Host); err != nil {
+				logger.Error(err.Error())
+			}
+		},
+	},
+}
+
+var leaseKind = &registry.Kind{
+	Kind:       "Lease",
+	Prefix:     url.LeaseURL,
+	Namespaced: false,
+	New:        func() apiObject.Object { return &apiObject.Lease{} },
+	ApiVersion: "v1",
+	Hooks: registry.Hooks{
+		Validate: func(obj apiObject.Object) error {
+			if obj.(*apiObject.Lease).Spec.LeaseDurationSeconds <= 0 {
+				return fmt.Errorf("spec.leaseDurationSeconds must be positive")
+			}
+			return nil
+		},
+	},
+}
+
+// csrKind is created by HandleCreateCSR, which records who sent it, and only
+// changes its status by HandleApproveCSR. Whom it is for is always taken
+// from its request.
+var csrKind = &registry.Kind{
+	Kind:       "CertificateSigningRequest",
+	Prefix:     url.CSRURL,
+	Namespaced: false,
+	New:        func() apiObject.Object { return &apiObject.CertificateSigningRequest{} },
+	ApiVersion: "v1",
+	Hooks: registry.Hooks{
+		PrepareForCreate: func(obj apiObject.Object) error {
+			csr := obj.(*apiObject.CertificateSigningRequest)
+			request, err := certutil.ParseRequest([]byte(csr.Spec.Request))
+			if err != nil {
+				return httputil.NewBadRequest(err.Error())
+			}
+			csr.Spec.CommonName, csr.Spec.Organization = request.Subject.CommonName, request.Subject.Organization
+			csr.Status = apiObject.CertificateSigningRequestStatus{}
+			return nil
+		},
+		PrepareForUpdate: func(obj, old apiObject.Object) error {
+			csr, oldCSR := obj.(*apiObject.CertificateSigningRequest), old.(*apiObject.CertificateSigningRequest)
+			csr.Spec, csr.Status = oldCSR.Spec, oldCSR.Status
+			return nil
+		},
+		Validate: func(obj apiObject.Object) error {
+			_, err := certutil.ParseRequest([]byte(obj.(*apiObject.CertificateSigningRequest).Spec.Request))
+			return err
+		},
+		AuthorizeWrite: authorizeCSR,
+	},
+}
+
+// RegisterKinds adds the built-in kinds to the registry
+func RegisterKinds() {
+	for _, kind := range []*registry.Kind{
+		namespaceKind,
+		nodeKind,
+		replicaSetKind,
+		deploymentKind,
+		hpaKind,
+		gpuJobKind,
+		workflowKind,
+		serviceKind,
+		dnsKind,
+		eventKind,
+		leaseKind,
+		csrKind,
+		crdKind,
+		mutatingWebhookKind,
+		validatingWebhookKind,
+		roleKind,
+		clusterRoleKind,
+		roleBindingKind,
+		clusterRoleBindingKind,
+	} {
+		registry.Register(kind)
+	}
+	registerAdmissionPlugins()
+	initNamespaces()
+	initRBAC()
+	loadCustomKinds()
+}
+
+func putStatus(etcdURL string, status interface{}) {
+	if statusJson, err := json.Marshal(status); err == nil {
+		_ = etcd.Put(etcdURL, string(statusJson))
+	}
+}
+
+func publishReplicaSetUpdate(action entity.ApiObjectUpdateAction, rs *apiObject.ReplicaSet) {
+	msg, _ := json.Marshal(entity.ReplicaSetUpdate{
+		Action: action,
+		Target: *rs,
+	})
+	listwatch.Publish(topicutil.ReplicaSetUpdateTopic(), msg)
+}
+
+func publishDeploymentUpdate(action entity.ApiObjectUpdateAction, deployment *apiObject.Deployment) {
+	msg, _ := json.Marshal(entity.DeploymentUpdate{
+		Action: action,
+		Target: *deployment,
+	})
+	listwatch.Publish(topicutil.DeploymentUpdateTopic(), msg)
+}
+
+func publishHPAUpdate(action entity.ApiObjectUpdateAction, hpa *apiObject.HorizontalPodAutoscaler) {
+	msg, _ := json.Marshal(entity.HPAUpdate{
+		Action: action,
+		Target: *hpa,
+	})
+	listwatch.Publish(topicutil.HPAUpdateTopic(), msg)
+}
+
+func publishGpuUpdate(action entity.ApiObjectUpdateAction, gpu *apiObject.GpuJob) {
+	msg, _ := json.Marshal(entity.GpuUpdate{
+		Action: action,
+		Target: *gpu,
+	})
+	listwatch.Publish(topicutil.GpuJobUpdateTopic(), msg)
+}
+
+func publishWorkflowUpdate(action entity.ApiObjectUpdateAction, wf *apiObject.Workflow) {
+	msg, _ := json.Marshal(entity.WorkflowUpdate{
+		Action: action,
+		Target: *wf,
+	})
+	listwatch.Publish(topicutil.WorkflowUpdateTopic(), msg)
+}
+
+// indexService stores the service under every key=value of its selector,
+// where the pods with the label find it.
+func indexService(service *apiObject.Service) {
+	indexed := *service
+	indexed.Metadata.ResourceVersion = ""
+	serviceJson, _ := json.Marshal(indexed)
+	for key, value := range service.Spec.Selector {
+		if err := etcd.Put(path.Join(url.ServiceURL, key, value, service.Metadata.UID), string(serviceJson)); err != nil {
+			logger.Error(err.Error())
+		}
+	}
+}
+
+func unindexService(service *apiObject.Service) {
+	for key, value := range service.Spec.Selector {
+		_ = etcd.Delete(path.Join(url.ServiceURL, key, value, service.Metadata.UID))
+	}
+}
+
+func publishServiceUpdate(action entity.ApiObjectUpdateAction, service *apiObject.Service) {
+	serviceUpdate := entity.ServiceUpdate{
+		Action: action,
+		Target: entity.ServiceTarget{
+			Service:   *service,
+			Endpoints: make([]apiObject.Endpoint, 0),
+		},
+	}
+	for key, value := range service.Spec.Selector {
+		if endpoints, err := helper.GetEndpoints(key, value); err != nil {
+			logger.Error(err.Error())
+		} else {
+			serviceUpdate.Target.Endpoints = append(serviceUpdate.Target.Endpoints, endpoints...)
+		}
+	}
+	msg, _ := json.Marshal(serviceUpdate)
+	listwatch.Publish(topicutil.ServiceUpdateTopic(), msg)
+}
+
+// prepareHPA binds the hpa to its target, which must exist
+func prepareHPA(hpa *apiObject.HorizontalPodAutoscaler) error {
+	target := hpa.Target()
+	rs, err := replicaSetKind.Get(target.Namespace(), target.Name())
+	if httputil.IsNotFound(err) {
+		return httputil.NewInvalid("HorizontalPodAutoscaler", hpa.Namespace(), hpa.Name(),
+			fmt.Errorf("target %s/%s does not exist", target.Namespace(), target.Name()))
+	} else if err != nil {
+		return err
+	}
+	hpa.SetTarget(rs.(*apiObject.ReplicaSet))
+
+	// The hpa is garbage collected with its target
+	owners := []apiObject.OwnerReference{rs.(*apiObject.ReplicaSet).OwnerReference()}
+	for _, ref := range hpa.Metadata.OwnerReferences {
+		if ref.Kind != replicaSetKind.Kind {
+			owners = append(owners, ref)
+		}
+	}
+	hpa.Metadata.OwnerReferences = owners
+	if hpa.ScaleInterval() == 0 {
+		hpa.Spec.ScaleInterval = hpaController.DefaultScaleInterval
+	}
+	log("metrics %+v", hpa.Metrics())
+	return nil
+}
+
+// dnsServers maps the paths of dns to the cluster ips of their services,
+// which must exist.
+func dnsServers(dns *apiObject.Dns) ([]nginx.Server, error) {
+	servers := make([]nginx.Server, 1)
+	servers[0].Port = 80
+	for _, p := range dns.Spec.Paths {
+		obj, err := serviceKind.Get(dns.Metadata.Namespace, p.Service.Name)
+		if err != nil {
+			return nil, err
+		}
+		service := obj.(*apiObject.Service)
+		log("dns service: %+v", service)
+		servers[0].Locations = append(servers[0].Locations, nginx.Location{
+			Dest: service.Spec.ClusterIP + ":" + p.Service.Port,
+			Addr: p.Path,
+		})
+	}
+	return servers, nil
+}
+
+// startDNS runs an nginx that forwards the paths of dns to their services,
+// and resolves the host of dns to it.
+func startDNS(dns *apiObject.Dns) (err error) {
+	nm := nginx.New(dns.Metadata.UID)
+
+	// Step 1: Apply mappings to nginx.conf
+	var servers []nginx.Server
+	if servers, err = dnsServers(dns); err != nil {
+		return
+	}
+	if err = nm.Apply(servers); err != nil {
+		return
+	}
+
+	// Step 2: Start nginx container
+	log("nginx starting..")
+	if err = nm.Start(); err != nil {
+		return
+	}
+
+	// Step 3: Attach ip to nginx container
+	if dns.Status.Ip, err = helper.NewPodIp(); err != nil {
+		return
+	}
+	_, mask := configutil.Current().PodIPRange()
+	if err = weaveutil.WeaveAttach(nm.GetName(), fmt.Sprintf("%s/%d", dns.Status.Ip, mask)); err != nil {
+		return
+	}
+	log("%#v", dns.Status.Ip)
+
+	// Step 4: Modify dns configuration
+	return dns2.New(path.Join(url.DNSDirPath, url.DNSHostsFileName)).AddEntry(dns.Spec.Host, dns.Status.Ip)
+}
+
+// abortDNS undoes startDNS for a dns that is not stored. Its host is given
+// back to the dns of the same name that is, if it took the host over.
+func abortDNS(dns *apiObject.Dns) {
+	if err := nginx.New(dns.Metadata.UID).Shutdown(); err != nil {
+		logger.Error(err.Error())
+	}
+	if dns.Status.Ip == "" {
+		return
+	}
+	if err := helper.ReleasePodIp(dns.Status.Ip); err != nil {
+		logger.Error(err.Error())
+	}
+
+	hosts := dns2.New(path.Join(url.DNSDirPath, url.DNSHostsFileName))
+	if ip, err := hosts.GetEntry(dns.Spec.Host); err != nil || ip != dns.Status.Ip {
+		return
+	}
+	var err error
+	if stored, getErr := registry.Lookup("Dns").Get(dns.Metadata.Namespace, dns.Metadata.Name); getErr == nil &&
+		stored.(*apiObject.Dns).Spec.Host == dns.Spec.Host && stored.(*apiObject.Dns).Status.Ip != "" {
+		err = hosts.AddEntry(dns.Spec.Host, stored.(*apiObject.Dns).Status.Ip)
+	} else {
+		err = hosts.DeleteIfExistEntry(dns.Spec.Host)
+	}
+	if err != nil {
+		logger.Error(err.Error())
+	}
+}
+
+// dnsChanged tells whether the nginx of dns has to follow an update from old,
+// which is not the case if e.g. only the finalizers or the labels change.
+func dnsChanged(dns, old *apiObject.Dns) bool {
+	return old.Metadata.DeletionTimestamp == nil && !reflect.DeepEqual(dns.Spec, old.Spec)
+}
+
+// updateDNS reloads the nginx of dns with its new paths, and moves the entry
+// of its nginx to the new host if the host has changed. It runs once the
+// update is stored, so that a rejected update leaves nginx alone.
+func updateDNS(dns, old *apiObject.Dns) error {
+	if !dnsChanged(dns, old) {
+		return nil
+	}
+	servers, err := dnsServers(dns)
+	if err != nil {
+		return err
+	}
+	nm := nginx.New(dns.Metadata.UID)
+	if err = nm.Apply(servers); err != nil {
+		return err
+	}
+	if err = nm.Reload(); err != nil {
+		return err
+	}
+
+	if dns.Spec.Host == old.Spec.Host {
+		return nil
+	}
+	hosts := dns2.New(path.Join(url.DNSDirPath, url.DNSHostsFileName))
+	nginxIp, err := hosts.GetEntry(old.Spec.Host)
+	if err != nil {
+		return err
+	}
+	if err = hosts.DeleteIfExistEntry(old.Spec.Host); err != nil {
+		return err
+	}
+	log("dns %s/%s moves from %s to %s", dns.Metadata.Namespace, dns.Metadata.Name, old.Spec.Host, dns.Spec.Host)
+	return hosts.AddEntry(dns.Spec.Host, nginxIp)
+}
@@ -2,10 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
 	"github.com/gin-gonic/gin"
 	"minik8s/apiObject"
 	"minik8s/apiserver/src/etcd"
+	"minik8s/apiserver/src/registry"
 	"minik8s/apiserver/src/url"
 	"minik8s/entity"
 	"minik8s/util/httputil"
@@ -25,7 +25,7 @@ func HandleLabelNode(c *gin.Context) {
 
 	labels := apiObject.Labels{}
 	if err := httputil.ReadAndUnmarshal(body, &labels); err != nil {
-		c.String(http.StatusOK, err.Error())
+		registry.WriteError(c, httputil.NewBadRequest(err.Error()))
 		return
 	}
 
@@ -34,10 +34,10 @@ func HandleLabelNode(c *gin.Context) {
 	node := apiObject.Node{}
 	etcdNodeURL := path.Join(url.NodeURL, namespace, name)
 	var err error
-	var raw string
-	if raw, err = etcd.Get(etcdNodeURL); err == nil {
-		log("got %s from etcd", raw)
-		if err = json.Unmarshal([]byte(raw), &node); err == nil {
+	var revision int64
+	if revision, err = registry.GetObject(etcdNodeURL, &node); err == nil && revision != 0 {
+		log("got %+v from etcd", node)
+		if err = registry.CheckResourceVersion(c.Query("resourceVersion"), revision); err == nil {
 			nodeLabels := node.Labels()
 			if nodeLabels == nil {
 				nodeLabels = make(apiObject.Labels)
@@ -47,8 +47,8 @@ func HandleLabelNode(c *gin.Context) {
 					nodeLabels[key] = value
 				}
 			}
-			nodeJson, _ := json.Marshal(node)
-			if err = etcd.Put(etcdNodeURL, string(nodeJson)); err == nil {
+			node.Metadata.Labels = nodeLabels
+			if err = registry.PutObject(etcdNodeURL, &node, revision); err == nil {
 				c.String(http.StatusOK, "ok")
 
 				etcdNodeStatusURL := path.Join(url.NodeURL, "status", node.Namespace(), node.Name())
@@ -64,6 +64,9 @@ func HandleLabelNode(c *gin.Context) {
 			}
 		}
 	}
-	fmt.Printf("no such node %s/%s\n", node.Namespace(), node.Name())
-	c.String(http.StatusOK, err.Error())
+	if err == nil {
+		err = registry.NewNotFound("Node", namespace, name)
+	}
+	log(err.Error())
+	registry.WriteError(c, err)
 }
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"fmt"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/etcd"
+	"minik8s/apiserver/src/helper"
+	"minik8s/apiserver/src/registry"
+	"minik8s/apiserver/src/url"
+	"minik8s/util/httputil"
+	"minik8s/util/logger"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// systemNamespaces always exist, functions run their replicaSets in "function"
+var systemNamespaces = []string{apiObject.DefaultNamespace, "function"}
+
+const namespacePurgeInterval = 2 * time.Second
+
+var namespaceKind = &registry.Kind{
+	Kind:       "Namespace",
+	Prefix:     url.NamespaceURL,
+	Namespaced: false,
+	New:        func() apiObject.Object { return &apiObject.Namespace{} },
+	ApiVersion: "v1",
+	Hooks: registry.Hooks{
+		PrepareForCreate: func(obj apiObject.Object) error {
+			ns := obj.(*apiObject.Namespace)
+			ns.Metadata.Namespace = ""
+			ns.Status.Phase = apiObject.NamespaceActive
+			return nil
+		},
+		PrepareForUpdate: func(obj, old apiObject.Object) error {
+			obj.(*apiObject.Namespace).Status = old.(*apiObject.Namespace).Status
+			return nil
+		},
+		Validate: func(obj apiObject.Object) error {
+			name := obj.Meta().Name
+			if name == "" || strings.Contains(name, "/") {
+				return fmt.Errorf("invalid namespace name \"%s\"", name)
+			}
+			return nil
+		},
+		BeforeDelete: func(obj apiObject.Object) (bool, error) {
+			ns := obj.(*apiObject.Namespace)
+			for _, name := range systemNamespaces {
+				if ns.Name() == name {
+					return false, httputil.NewForbidden(fmt.Sprintf("namespace %s cannot be deleted", name))
+				}
+			}
+			if ns.Phase() != apiObject.NamespaceTerminating {
+				revision, _ := strconv.ParseInt(ns.Metadata.ResourceVersion, 10, 64)
+				ns.Status.Phase = apiObject.NamespaceTerminating
+				if err := registry.PutObject(namespaceKey(ns.Name()), ns, revision); err != nil {
+					return false, err
+				}
+			}
+			go finalizeNamespace(ns.Name())
+			return true, nil
+		},
+	},
+}
+
+func namespaceKey(name string) string {
+	return path.Join(url.NamespaceURL, name)
+}
+
+// finalizing holds the namespaces whose contents are being deleted
+var finalizing sync.Map
+
+// finalizeNamespace deletes everything in a Terminating namespace, and then
+// the namespace itself. Controllers may still create pods for a while, so it
+// keeps purging until the namespace is found empty.
+func finalizeNamespace(namespace string) {
+	if _, running := finalizing.LoadOrStore(namespace, true); running {
+		return
+	}
+	defer finalizing.Delete(namespace)
+
+	log("namespace %s is terminating", namespace)
+	for purgeNamespace(namespace) > 0 {
+		time.Sleep(namespacePurgeInterval)
+	}
+	if err := etcd.Delete(namespaceKey(namespace)); err != nil {
+		logger.Error(err.Error())
+		return
+	}
+	log("namespace %s is deleted", namespace)
+}
+
+// purgeNamespace deletes the objects of every namespaced kind and the pods in
+// namespace, and returns how many it found.
+func purgeNamespace(namespace string) (found int) {
+	for _, kind := range registry.Kinds() {
+		if !kind.Namespaced {
+			continue
+		}
+		objs, _, err := kind.List()
+		if err != nil {
+			logger.Error(err.Error())
+			found++
+			continue
+		}
+		for _, obj := range objs {
+			if obj.Meta().Namespace != namespace {
+				continue
+			}
+			found++
+			if _, err = kind.Delete(namespace, obj.Meta().Name, registry.DeleteOptions{}); err != nil && !registry.IsNotFound(err) {
+				logger.Error(err.Error())
+			}
+		}
+	}
+
+	for _, node := range helper.GetNodeHostnames() {
+		for _, pod := range helper.GetPodsApiObjectFromEtcd(node) {
+			if pod.Namespace() != namespace {
+				continue
+			}
+			found++
+			if err := deletePod(namespace, pod.Name()); err != nil {
+				logger.Error(err.Error())
+			}
+		}
+	}
+	return
+}
+
+// initNamespaces creates the system namespaces, and resumes the deletion of
+// the namespaces that were Terminating when the api-server stopped.
+func initNamespaces() {
+	for _, name := range systemNamespaces {
+		ns := &apiObject.Namespace{}
+		ns.ApiVersion = "v1"
+		ns.Kind = namespaceKind.Kind
+		ns.Metadata.Name = name
+		if err := namespaceKind.Create(ns); err != nil && !registry.IsAlreadyExists(err) {
+			logger.Error(err.Error())
+		}
+	}
+
+	namespaces, _, err := namespaceKind.List()
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+	for _, obj := range namespaces {
+		if ns := obj.(*apiObject.Namespace); ns.Phase() == apiObject.NamespaceTerminating {
+			go finalizeNamespace(ns.Name())
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"minik8s/apiserver/src/helper"
+	"minik8s/apiserver/src/registry"
+	"minik8s/apiserver/src/url"
+	"minik8s/entity"
+	"minik8s/util/logger"
+	"net/http"
+	"path"
+)
+
+// HandleGetObjectMetas serves the metadata of the objects of every kind and
+// of the pods, from which the garbage collector builds the ownership graph.
+func HandleGetObjectMetas(c *gin.Context) {
+	metas := make([]*entity.ObjectMeta, 0)
+	for _, kind := range registry.Kinds() {
+		objs, _, err := kind.List()
+		if err != nil {
+			logger.Error(err.Error())
+			registry.WriteError(c, err)
+			return
+		}
+		for _, obj := range objs {
+			metadata := obj.Meta()
+			metas = append(metas, &entity.ObjectMeta{
+				Kind:     kind.Kind,
+				SelfLink: kind.SelfLink(metadata.Namespace, metadata.Name),
+				Metadata: *metadata,
+			})
+		}
+	}
+
+	for _, node := range helper.GetNodeHostnames() {
+		for _, pod := range helper.GetPodsApiObjectFromEtcd(node) {
+			metas = append(metas, &entity.ObjectMeta{
+				Kind:     "Pod",
+				SelfLink: path.Join(url.PodURL, "nodes", node, pod.Namespace(), pod.Name()),
+				Metadata: pod.Metadata,
+			})
+		}
+	}
+	c.JSON(http.StatusOK, metas)
+}
@@ -6,10 +6,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"minik8s/apiObject"
 	"minik8s/apiserver/src/etcd"
+	"minik8s/apiserver/src/registry"
 	"minik8s/apiserver/src/url"
 	"minik8s/entity"
-	"minik8s/listwatch"
-	"minik8s/util/topicutil"
+	"minik8s/util/httputil"
 	"net/http"
 	"path"
 	"strconv"
@@ -20,7 +20,7 @@ func HandleSetNodeStatus(c *gin.Context) {
 	name := c.Param("name")
 	form := getPutForm(c.Request.Body)
 	if form == nil {
-		c.String(http.StatusOK, "must have a form!")
+		registry.WriteError(c, httputil.NewBadRequest("must have a form!"))
 		return
 	}
 	lifecycleInt64, _ := strconv.Atoi(form["lifecycle"])
@@ -28,66 +28,91 @@ func HandleSetNodeStatus(c *gin.Context) {
 	log("Received lifecycle %v from %v", lifecycle.String(), name)
 
 	etcdURL := path.Join(url.NodeURL, "status", namespace, name)
-	raw, err := etcd.Get(etcdURL)
+	raw, revision, err := etcd.GetWithRevision(etcdURL)
 	if err != nil {
-		c.String(http.StatusOK, err.Error())
+		registry.WriteError(c, err)
 		return
 	}
 
 	nodeStatus := &entity.NodeStatus{}
 	if err = json.Unmarshal([]byte(raw), nodeStatus); err != nil {
-		c.String(http.StatusOK, fmt.Sprintf("no such node %s/%s", namespace, name))
+		registry.WriteError(c, registry.NewNotFound("Node", namespace, name))
 		return
 	}
 
 	nodeStatus.Lifecycle = lifecycle
 	nodeStatusJson, _ := json.Marshal(nodeStatus)
 
-	if err = etcd.Put(etcdURL, string(nodeStatusJson)); err != nil {
-		c.String(http.StatusOK, err.Error())
+	if _, err = etcd.CompareAndPut(etcdURL, string(nodeStatusJson), revision); err != nil {
+		registry.WriteError(c, err)
 		return
 	}
 
 	c.String(http.StatusOK, fmt.Sprintf("Set node %s/%s status successfully", namespace, name))
 }
 
-func HandleSetReplicaSet(c *gin.Context) {
+// HandleScaleReplicaSet sets the number of replicas of a replicaSet, the
+// form may carry the resourceVersion the client based its decision on.
+func HandleScaleReplicaSet(c *gin.Context) {
 	namespace := c.Param("namespace")
 	name := c.Param("name")
 	form := getPutForm(c.Request.Body)
 	if form == nil {
-		c.String(http.StatusOK, "must have a form!")
+		registry.WriteError(c, httputil.NewBadRequest("must have a form!"))
 		return
 	}
 
 	replicas, _ := strconv.Atoi(form["replicas"])
 	log("Received replicas %v from %s/%s", replicas, namespace, name)
 
-	etcdURL := path.Join(url.ReplicaSetURL, namespace, name)
-	raw, err := etcd.Get(etcdURL)
+	obj, err := replicaSetKind.Get(namespace, name)
 	if err != nil {
-		c.String(http.StatusOK, err.Error())
+		registry.WriteError(c, err)
 		return
 	}
 
-	replicaSet := &apiObject.ReplicaSet{}
-	if err = json.Unmarshal([]byte(raw), replicaSet); err != nil {
-		c.String(http.StatusOK, fmt.Sprintf("no such rs %s/%s", namespace, name))
+	replicaSet := obj.(*apiObject.ReplicaSet)
+	replicaSet.SetReplicas(replicas)
+	if resourceVersion := form["resourceVersion"]; resourceVersion != "" {
+		replicaSet.Metadata.ResourceVersion = resourceVersion
+	}
+	if err = replicaSetKind.Update(replicaSet); err != nil {
+		registry.WriteError(c, err)
 		return
 	}
+	c.String(http.StatusOK, fmt.Sprintf("Set replicaSet %s/%s num replicas to %v successfully", namespace, name, replicas))
+}
 
-	replicaSet.SetReplicas(replicas)
-	replicaSetJson, _ := json.Marshal(replicaSet)
+// HandleUpdatePodOwnerReferences takes over the owner references of the pod
+// in the body, e.g. when the garbage collector orphans the pod. The rest of a
+// scheduled pod cannot change.
+func HandleUpdatePodOwnerReferences(c *gin.Context) {
+	node := c.Param("node")
+	namespace := c.Param("namespace")
+	name := c.Param("name")
 
-	if err = etcd.Put(etcdURL, string(replicaSetJson)); err != nil {
-		c.String(http.StatusOK, err.Error())
+	newPod := apiObject.Pod{}
+	if err := httputil.ReadAndUnmarshal(c.Request.Body, &newPod); err != nil {
+		registry.WriteError(c, httputil.NewBadRequest(err.Error()))
 		return
 	}
 
-	replicaSetUpdateMsg, _ := json.Marshal(entity.ReplicaSetUpdate{
-		Action: entity.UpdateAction,
-		Target: *replicaSet,
-	})
-	listwatch.Publish(topicutil.ReplicaSetUpdateTopic(), replicaSetUpdateMsg)
-	c.String(http.StatusOK, fmt.Sprintf("Set replicaSet %s/%s num replicas to %v successfully", namespace, name, replicas))
+	etcdURL := path.Join(url.PodURL, node, namespace, name)
+	pod := apiObject.Pod{}
+	revision, err := registry.GetObject(etcdURL, &pod)
+	if err == nil && revision == 0 {
+		err = registry.NewNotFound("Pod", namespace, name)
+	}
+	if err == nil {
+		err = registry.CheckResourceVersion(newPod.Metadata.ResourceVersion, revision)
+	}
+	if err == nil {
+		pod.Metadata.OwnerReferences = newPod.Metadata.OwnerReferences
+		err = registry.PutObject(etcdURL, &pod, revision)
+	}
+	if err != nil {
+		registry.WriteError(c, err)
+		return
+	}
+	c.String(http.StatusOK, "ok")
 }
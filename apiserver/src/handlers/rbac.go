@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/auth"
+	"minik8s/apiserver/src/registry"
+	"minik8s/apiserver/src/url"
+	"minik8s/util/httputil"
+	"minik8s/util/logger"
+)
+
+var roleKind = &registry.Kind{
+	Kind:       "Role",
+	Prefix:     url.RoleURL,
+	Namespaced: true,
+	New:        func() apiObject.Object { return &apiObject.Role{} },
+	ApiVersion: "v1",
+	Hooks: registry.Hooks{
+		Validate: func(obj apiObject.Object) error {
+			return validateRules(obj.(*apiObject.Role).Rules, false)
+		},
+		AuthorizeWrite: func(c *gin.Context, obj apiObject.Object) error {
+			role := obj.(*apiObject.Role)
+			return authorizeGrant(c, role.Metadata.Namespace, func(*auth.Policy) []apiObject.PolicyRule { return role.Rules })
+		},
+		AfterCreate: func(apiObject.Object) { auth.InvalidatePolicy() },
+		AfterUpdate: func(apiObject.Object, apiObject.Object) { auth.InvalidatePolicy() },
+		AfterDelete: func(apiObject.Object) { auth.InvalidatePolicy() },
+	},
+}
+
+var clusterRoleKind = &registry.Kind{
+	Kind:       "ClusterRole",
+	Prefix:     url.ClusterRoleURL,
+	Namespaced: false,
+	New:        func() apiObject.Object { return &apiObject.ClusterRole{} },
+	ApiVersion: "v1",
+	Hooks: registry.Hooks{
+		Validate: func(obj apiObject.Object) error {
+			return validateRules(obj.(*apiObject.ClusterRole).Rules, true)
+		},
+		AuthorizeWrite: func(c *gin.Context, obj apiObject.Object) error {
+			role := obj.(*apiObject.ClusterRole)
+			return authorizeGrant(c, "", func(*auth.Policy) []apiObject.PolicyRule { return role.Rules })
+		},
+		AfterCreate: func(apiObject.Object) { auth.InvalidatePolicy() },
+		AfterUpdate: func(apiObject.Object, apiObject.Object) { auth.InvalidatePolicy() },
+		AfterDelete: func(apiObject.Object) { auth.InvalidatePolicy() },
+	},
+}
+
+var roleBindingKind = &registry.Kind{
+	Kind:       "RoleBinding",
+	Prefix:     url.RoleBindingURL,
+	Namespaced: true,
+	New:        func() apiObject.Object { return &apiObject.RoleBinding{} },
+	ApiVersion: "v1",
+	Hooks: registry.Hooks{
+		Validate: func(obj apiObject.Object) error {
+			binding := obj.(*apiObject.RoleBinding)
+			return validateBinding(binding.Subjects, binding.RoleRef, roleKind.Kind, clusterRoleKind.Kind)
+		},
+		AuthorizeWrite: func(c *gin.Context, obj apiObject.Object) error {
+			binding := obj.(*apiObject.RoleBinding)
+			namespace := binding.Metadata.Namespace
+			return authorizeGrant(c, namespace, func(policy *auth.Policy) []apiObject.PolicyRule {
+				return policy.RulesOf(namespace, binding.RoleRef)
+			})
+		},
+		AfterCreate: func(apiObject.Object) { auth.InvalidatePolicy() },
+		AfterUpdate: func(apiObject.Object, apiObject.Object) { auth.InvalidatePolicy() },
+		AfterDelete: func(apiObject.Object) { auth.InvalidatePolicy() },
+	},
+}
+
+var clusterRoleBindingKind = &registry.Kind{
+	Kind:       "ClusterRoleBinding",
+	Prefix:     url.ClusterRoleBindingURL,
+	Namespaced: false,
+	New:        func() apiObject.Object { return &apiObject.ClusterRoleBinding{} },
+	ApiVersion: "v1",
+	Hooks: registry.Hooks{
+		Validate: func(obj apiObject.Object) error {
+			binding := obj.(*apiObject.ClusterRoleBinding)
+			return validateBinding(binding.Subjects, binding.RoleRef, clusterRoleKind.Kind)
+		},
+		AuthorizeWrite: func(c *gin.Context, obj apiObject.Object) error {
+			binding := obj.(*apiObject.ClusterRoleBinding)
+			return authorizeGrant(c, "", func(policy *auth.Policy) []apiObject.PolicyRule {
+				return policy.RulesOf("", binding.RoleRef)
+			})
+		},
+		AfterCreate: func(apiObject.Object) { auth.InvalidatePolicy() },
+		AfterUpdate: func(apiObject.Object, apiObject.Object) { auth.InvalidatePolicy() },
+		AfterDelete: func(apiObject.Object) { auth.InvalidatePolicy() },
+	},
+}
+
+// authorizeGrant keeps the sender of c from granting what they may not do
+// themselves, e.g. a component binding system:unauthenticated to
+// cluster-admin. rules are what the role or binding grants in namespace, or
+// in the whole cluster if it is "".
+func authorizeGrant(c *gin.Context, namespace string, rules func(policy *auth.Policy) []apiObject.PolicyRule) error {
+	user := auth.UserFrom(c)
+	if user.InGroup(auth.GroupMasters) {
+		return nil
+	}
+	policy, err := auth.LoadPolicy()
+	if err != nil {
+		return err
+	}
+	if !policy.Covers(user, namespace, rules(policy)) {
+		return httputil.NewForbidden(fmt.Sprintf("user %s cannot grant permissions they do not have", user.Name))
+	}
+	return nil
+}
+
+// validateRules checks the rules of a role, only cluster roles may grant
+// non-resource urls since those belong to no namespace.
+func validateRules(rules []apiObject.PolicyRule, cluster bool) error {
+	for i, rule := range rules {
+		if len(rule.Verbs) == 0 {
+			return fmt.Errorf("rules[%d]: verbs are required", i)
+		}
+		if len(rule.Resources) == 0 && len(rule.NonResourceURLs) == 0 {
+			return fmt.Errorf("rules[%d]: resources or nonResourceURLs are required", i)
+		}
+		if len(rule.NonResourceURLs) > 0 && !cluster {
+			return fmt.Errorf("rules[%d]: nonResourceURLs are only allowed in a ClusterRole", i)
+		}
+	}
+	return nil
+}
+
+func validateBinding(subjects []apiObject.Subject, roleRef apiObject.RoleRef, roleKinds ...string) error {
+	for i, subject := range subjects {
+		if subject.Kind != apiObject.SubjectUser && subject.Kind != apiObject.SubjectGroup {
+			return fmt.Errorf("subjects[%d]: kind must be %s or %s", i, apiObject.SubjectUser, apiObject.SubjectGroup)
+		}
+		if subject.Name == "" {
+			return fmt.Errorf("subjects[%d]: name is required", i)
+		}
+	}
+	if roleRef.Name == "" {
+		return fmt.Errorf("roleRef.name is required")
+	}
+	for _, kind := range roleKinds {
+		if roleRef.Kind == kind {
+			return nil
+		}
+	}
+	return fmt.Errorf("roleRef.kind must be one of %v", roleKinds)
+}
+
+// initRBAC creates the roles and bindings every cluster starts with, unless
+// they exist. The components may do anything but /reset, and view lets a
+// user read everything.
+func initRBAC() {
+	allResources := apiObject.PolicyRule{Verbs: []string{"*"}, Resources: []string{"*"}}
+	for _, role := range []*apiObject.ClusterRole{
+		newClusterRole("cluster-admin", allResources, apiObject.PolicyRule{Verbs: []string{"*"}, NonResourceURLs: []string{"*"}}),
+		newClusterRole("system:component", allResources, apiObject.PolicyRule{
+			Verbs:           []string{"*"},
+			NonResourceURLs: []string{"/autoscaling/*", "/endpoint/*", "/generator/*"},
+		}),
+		newClusterRole("view", apiObject.PolicyRule{
+			Verbs:     []string{auth.VerbGet, auth.VerbList, auth.VerbWatch},
+			Resources: []string{"*"},
+		}),
+	} {
+		if err := clusterRoleKind.Create(role); err != nil && !registry.IsAlreadyExists(err) {
+			logger.Error(err.Error())
+		}
+	}
+
+	for _, binding := range []*apiObject.ClusterRoleBinding{
+		newGroupBinding("cluster-admin", auth.GroupMasters, "cluster-admin"),
+		newGroupBinding("system:components", auth.GroupComponents, "system:component"),
+	} {
+		if err := clusterRoleBindingKind.Create(binding); err != nil && !registry.IsAlreadyExists(err) {
+			logger.Error(err.Error())
+		}
+	}
+}
+
+func newClusterRole(name string, rules ...apiObject.PolicyRule) *apiObject.ClusterRole {
+	role := &apiObject.ClusterRole{Rules: rules}
+	role.ApiVersion = "v1"
+	role.Kind = clusterRoleKind.Kind
+	role.Metadata.Name = name
+	return role
+}
+
+func newGroupBinding(name, group, clusterRole string) *apiObject.ClusterRoleBinding {
+	binding := &apiObject.ClusterRoleBinding{
+		Subjects: []apiObject.Subject{{Kind: apiObject.SubjectGroup, Name: group}},
+		RoleRef:  apiObject.RoleRef{Kind: clusterRoleKind.Kind, Name: clusterRole},
+	}
+	binding.ApiVersion = "v1"
+	binding.Kind = clusterRoleBindingKind.Kind
+	binding.Metadata.Name = name
+	return binding
+}
@@ -2,11 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
 	"github.com/gin-gonic/gin"
 	"minik8s/apiObject"
 	"minik8s/apiserver/src/etcd"
 	"minik8s/apiserver/src/helper"
+	"minik8s/apiserver/src/registry"
 	"minik8s/apiserver/src/url"
 	"minik8s/entity"
 	"minik8s/util/httputil"
@@ -21,30 +21,25 @@ func HandleSchedulePod(c *gin.Context) {
 	pod := apiObject.Pod{}
 	err := httputil.ReadAndUnmarshal(c.Request.Body, &pod)
 	if err != nil {
-		c.String(http.StatusOK, err.Error())
-	}
-
-	if helper.ExistsPod(pod.Namespace(), pod.Name()) {
-		c.String(http.StatusOK, fmt.Sprintf("pod %s/%s already exists", pod.Namespace(), pod.Name()))
+		registry.WriteError(c, httputil.NewBadRequest(err.Error()))
 		return
 	}
 
-	var podJson []byte
-	if podJson, err = json.Marshal(pod); err != nil {
-		c.String(http.StatusOK, err.Error())
+	if helper.ExistsPod(pod.Namespace(), pod.Name()) {
+		registry.WriteError(c, registry.NewAlreadyExists("Pod", pod.Namespace(), pod.Name()))
 		return
 	}
 
 	etcdPodURL := path.Join(url.PodURL, node, pod.Namespace(), pod.Name())
-	if err := etcd.Put(etcdPodURL, string(podJson)); err != nil {
-		c.String(http.StatusOK, err.Error())
+	if err := registry.PutObject(etcdPodURL, &pod, 0); err != nil {
+		registry.WriteError(c, err)
 		return
 	}
 
 	// Store pod's endpoints into etcd
 	// @TODO push to proxy
 	if err = helper.AddEndpoints(pod); err != nil {
-		c.String(http.StatusOK, err.Error())
+		registry.WriteError(c, err)
 		return
 	}
 
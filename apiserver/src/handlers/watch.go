@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/registry"
+	"minik8s/apiserver/src/url"
+	"strings"
+)
+
+// watchSpec tells which keys under a list URL are api objects of that kind.
+// Other keys, like the statuses and the service selector indexes, share the
+// prefix but live at a different depth. A watch streams the api objects, so a
+// selector is matched against a new object of the kind.
+type watchSpec struct {
+	depth     int
+	exclude   string
+	newObject func() interface{}
+}
+
+var watchSpecs = map[string]watchSpec{
+	url.NodeURL:       {depth: 2, newObject: func() interface{} { return &apiObject.Node{} }},
+	url.PodURL:        {depth: 3, exclude: "status", newObject: func() interface{} { return &apiObject.Pod{} }},
+	url.ReplicaSetURL: {depth: 2, newObject: func() interface{} { return &apiObject.ReplicaSet{} }},
+	url.HPAURL:        {depth: 2, newObject: func() interface{} { return &apiObject.HorizontalPodAutoscaler{} }},
+	url.GpuURL:        {depth: 2, newObject: func() interface{} { return &apiObject.GpuJob{} }},
+	url.FuncURL:       {depth: 1, newObject: func() interface{} { return &apiObject.Function{} }},
+	url.WorkflowURL:   {depth: 2, newObject: func() interface{} { return &apiObject.Workflow{} }},
+}
+
+func (spec watchSpec) matches(prefix, key string) bool {
+	parts := strings.Split(strings.TrimPrefix(key, prefix), "/")
+	return len(parts) == spec.depth && parts[0] != spec.exclude
+}
+
+// Watchable makes a list handler serve ?watch=true&resourceVersion=N by
+// streaming the etcd events of its kind. A plain list request is left to the
+// handler, whose page tells the revision it is consistent with in the
+// X-Resource-Version header, so that clients can list and then watch without
+// missing anything.
+func Watchable(listURL string, list gin.HandlerFunc) gin.HandlerFunc {
+	spec, ok := watchSpecs[listURL]
+	if !ok {
+		return list
+	}
+	return func(c *gin.Context) {
+		if registry.IsWatch(c) {
+			opts, err := registry.ListOptionsFrom(c)
+			if err != nil {
+				registry.WriteError(c, err)
+				return
+			}
+			var encode func(value string) (string, error)
+			if kind := registry.LookupPrefix(listURL); kind != nil {
+				// the objects may be stored in another version
+				encode = kind.ServedValue
+			}
+			registry.ServeWatch(c, listURL, func(key string) bool {
+				return spec.matches(listURL, key)
+			}, encode, opts.Selected(spec.newObject))
+			return
+		}
+		list(c)
+	}
+}
@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"minik8s/apiserver/src/url"
+	"testing"
+)
+
+func TestWatchSpecMatches(t *testing.T) {
+	pods := watchSpecs[url.PodURL]
+	assert.True(t, pods.matches(url.PodURL, "/api/v1/pods/node1/default/example"))
+	assert.False(t, pods.matches(url.PodURL, "/api/v1/pods/status/default/example"))
+
+	rs := watchSpecs[url.ReplicaSetURL]
+	assert.False(t, rs.matches(url.ReplicaSetURL, "/api/v1/replicaSets/status/default/example"))
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/contentType"
+	"minik8s/apiserver/src/registry"
+	"minik8s/apiserver/src/url"
+	"minik8s/entity"
+	"minik8s/util/httputil"
+	"minik8s/util/logger"
+	"minik8s/util/uidutil"
+	"net/http"
+	"reflect"
+)
+
+var mutatingWebhookKind = &registry.Kind{
+	Kind:       "MutatingWebhookConfiguration",
+	Prefix:     url.MutatingWebhookURL,
+	Namespaced: false,
+	New:        func() apiObject.Object { return &apiObject.MutatingWebhookConfiguration{} },
+	ApiVersion: "admissionregistration/v1",
+	Hooks: registry.Hooks{
+		Validate: func(obj apiObject.Object) error {
+			return validateWebhooks(obj.(*apiObject.MutatingWebhookConfiguration).Webhooks)
+		},
+	},
+}
+
+var validatingWebhookKind = &registry.Kind{
+	Kind:       "ValidatingWebhookConfiguration",
+	Prefix:     url.ValidatingWebhookURL,
+	Namespaced: false,
+	New:        func() apiObject.Object { return &apiObject.ValidatingWebhookConfiguration{} },
+	ApiVersion: "admissionregistration/v1",
+	Hooks: registry.Hooks{
+		Validate: func(obj apiObject.Object) error {
+			return validateWebhooks(obj.(*apiObject.ValidatingWebhookConfiguration).Webhooks)
+		},
+	},
+}
+
+func validateWebhooks(webhooks []apiObject.Webhook) error {
+	for i, webhook := range webhooks {
+		if webhook.Name == "" || webhook.URL == "" {
+			return fmt.Errorf("webhooks[%d]: name and url are required", i)
+		}
+		if webhook.FailurePolicy != "" && webhook.FailurePolicy != apiObject.FailurePolicyFail && webhook.FailurePolicy != apiObject.FailurePolicyIgnore {
+			return fmt.Errorf("webhooks[%d]: failurePolicy must be %s or %s", i, apiObject.FailurePolicyFail, apiObject.FailurePolicyIgnore)
+		}
+	}
+	return nil
+}
+
+var mutatingWebhookPlugin = &registry.AdmissionPlugin{
+	Name: "MutatingAdmissionWebhook",
+	Mutate: func(attrs *registry.AdmissionAttributes) error {
+		return callWebhooks(mutatingWebhookKind, attrs, func(obj apiObject.Object) []apiObject.Webhook {
+			return obj.(*apiObject.MutatingWebhookConfiguration).Webhooks
+		})
+	},
+}
+
+var validatingWebhookPlugin = &registry.AdmissionPlugin{
+	Name: "ValidatingAdmissionWebhook",
+	Validate: func(attrs *registry.AdmissionAttributes) error {
+		return callWebhooks(validatingWebhookKind, attrs, func(obj apiObject.Object) []apiObject.Webhook {
+			return obj.(*apiObject.ValidatingWebhookConfiguration).Webhooks
+		})
+	},
+}
+
+// callWebhooks calls the matching webhooks of every configuration of kind,
+// one after another. The configurations themselves are never sent to a
+// webhook, so a broken webhook cannot lock itself in.
+func callWebhooks(kind *registry.Kind, attrs *registry.AdmissionAttributes, webhooksOf func(obj apiObject.Object) []apiObject.Webhook) error {
+	if attrs.Kind == mutatingWebhookKind.Kind || attrs.Kind == validatingWebhookKind.Kind {
+		return nil
+	}
+	configs, _, err := kind.List()
+	if err != nil {
+		return err
+	}
+	for _, config := range configs {
+		for _, webhook := range webhooksOf(config) {
+			if !webhook.Matches(attrs.Operation, attrs.Kind) {
+				continue
+			}
+			if err = callWebhook(&webhook, attrs); err != nil {
+				if !webhook.IgnoresFailure() || isDenied(err) {
+					return err
+				}
+				logger.Error(err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// webhookDeniedError is a webhook that answered, but did not allow the write
+type webhookDeniedError struct {
+	webhook, message string
+}
+
+func (e *webhookDeniedError) Error() string {
+	return fmt.Sprintf("webhook %s: %s", e.webhook, e.message)
+}
+
+// Status tells the write is forbidden, as the webhook did not say why
+func (e *webhookDeniedError) Status() entity.Status {
+	return httputil.NewForbidden(e.Error()).Status()
+}
+
+func isDenied(err error) bool {
+	_, ok := err.(*webhookDeniedError)
+	return ok
+}
+
+func callWebhook(webhook *apiObject.Webhook, attrs *registry.AdmissionAttributes) error {
+	metadata := attrs.Object.Meta()
+	request := &entity.AdmissionRequest{
+		UID:       uidutil.New(),
+		Kind:      attrs.Kind,
+		Namespace: metadata.Namespace,
+		Name:      metadata.Name,
+		Operation: attrs.Operation,
+	}
+	request.Object, _ = json.Marshal(attrs.Object)
+	if attrs.OldObject != nil {
+		request.OldObject, _ = json.Marshal(attrs.OldObject)
+	}
+	reviewJson, _ := json.Marshal(entity.AdmissionReview{Request: request})
+
+	log("call webhook %s for %s %s/%s", webhook.Name, attrs.Kind, metadata.Namespace, metadata.Name)
+	client := http.Client{Timeout: webhook.Timeout()}
+	resp, err := client.Post(webhook.URL, contentType.Json, bytes.NewReader(reviewJson))
+	if err != nil {
+		return fmt.Errorf("webhook %s: %s", webhook.Name, err.Error())
+	}
+	defer resp.Body.Close()
+
+	review := entity.AdmissionReview{}
+	if err = json.NewDecoder(resp.Body).Decode(&review); err != nil {
+		return fmt.Errorf("webhook %s: %s", webhook.Name, err.Error())
+	}
+	response := review.Response
+	if response == nil || response.UID != request.UID {
+		return fmt.Errorf("webhook %s: no response to request %s", webhook.Name, request.UID)
+	}
+	if !response.Allowed {
+		return &webhookDeniedError{webhook: webhook.Name, message: response.Message}
+	}
+	if len(response.Object) > 0 {
+		return replaceObject(attrs.Object, response.Object)
+	}
+	return nil
+}
+
+// replaceObject replaces obj by the object a mutating webhook returned,
+// keeping what identifies obj.
+func replaceObject(obj apiObject.Object, raw json.RawMessage) error {
+	fresh := reflect.New(reflect.TypeOf(obj).Elem())
+	if err := json.Unmarshal(raw, fresh.Interface()); err != nil {
+		return err
+	}
+
+	metadata := *obj.Meta()
+	reflect.ValueOf(obj).Elem().Set(fresh.Elem())
+	replaced := obj.Meta()
+	replaced.Name = metadata.Name
+	replaced.Namespace = metadata.Namespace
+	replaced.UID = metadata.UID
+	replaced.ResourceVersion = metadata.ResourceVersion
+	replaced.DeletionTimestamp = metadata.DeletionTimestamp
+	return nil
+}
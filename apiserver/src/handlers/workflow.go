@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"github.com/gin-gonic/gin"
 	"minik8s/apiserver/src/etcd"
+	"minik8s/apiserver/src/registry"
 	"minik8s/apiserver/src/url"
 	"minik8s/entity"
 	"minik8s/util/httputil"
@@ -14,14 +15,14 @@ import (
 func HandlePutWorkflowResult(c *gin.Context) {
 	result := entity.FunctionTriggerResult{}
 	if err := httputil.ReadAndUnmarshal(c.Request.Body, &result); err != nil {
-		c.String(http.StatusOK, err.Error())
+		registry.WriteError(c, httputil.NewBadRequest(err.Error()))
 		return
 	} else {
 		etcdURL := path.Join(url.WorkflowURL, "result", result.WorkflowNamespace, result.WorkflowName)
 		log("Receive workflow result: %+v", result)
 		resultJson, _ := json.Marshal(result)
 		if err = etcd.Put(etcdURL, string(resultJson)); err != nil {
-			c.String(http.StatusOK, err.Error())
+			registry.WriteError(c, err)
 			return
 		} else {
 			c.String(http.StatusOK, "ok")
@@ -8,6 +8,7 @@ import (
 	"minik8s/apiserver/src/url"
 	"minik8s/entity"
 	"minik8s/listwatch"
+	"minik8s/util/configutil"
 	"minik8s/util/topicutil"
 	"minik8s/util/uidutil"
 	"path"
@@ -263,9 +264,24 @@ func ExistsDNS(namespace, name string) bool {
 }
 
 func NewPodIp() (ip string, err error) {
-	return ipgen.New(url.PodIpURL, url.Mask).GetNext()
+	_, mask := configutil.Current().PodIPRange()
+	return ipgen.New(url.PodIpURL, mask).GetNext()
 }
 
 func NewServiceIp() (ip string, err error) {
-	return ipgen.New(url.ServiceIpURL, url.Mask).GetNext()
+	_, mask := configutil.Current().ServiceIPRange()
+	return ipgen.New(url.ServiceIpURL, mask).GetNext()
+}
+
+// ReleasePodIp gives back a pod ip that ends up unused, see ipgen Release
+func ReleasePodIp(ip string) error {
+	_, mask := configutil.Current().PodIPRange()
+	return ipgen.New(url.PodIpURL, mask).Release(ip)
+}
+
+// ReleaseServiceIp gives back a service ip that ends up unused, see ipgen
+// Release
+func ReleaseServiceIp(ip string) error {
+	_, mask := configutil.Current().ServiceIPRange()
+	return ipgen.New(url.ServiceIpURL, mask).Release(ip)
 }
@@ -23,6 +23,7 @@ type Generator interface {
 	GetNext() (string, error)
 	GetCurrentWithMask() (string, error)
 	GetNextWithMask() (string, error)
+	Release(ip string) error
 	Clear(ip string) error
 	ClearIfNotInit(ip string) error
 }
@@ -82,6 +83,16 @@ func (ig *ipGenerator) GetNextWithMask() (string, error) {
 	return ret + "/" + strconv.Itoa(ig.mask), nil
 }
 
+// Release gives ip back if it is the last one handed out. The generator only
+// counts up, an ip handed out before it stays taken.
+func (ig *ipGenerator) Release(ip string) error {
+	current, err := ig.GetCurrent()
+	if err != nil || current != ip {
+		return err
+	}
+	return etcd.Put(ig.url, strconv.Itoa(int(inetAtoN(ip))-1))
+}
+
 func (ig *ipGenerator) Clear(ip string) error {
 	return etcd.Put(ig.url, strconv.Itoa(20+int(inetAtoN(ip))))
 }
@@ -2,12 +2,14 @@ package ipgen
 
 import (
 	"minik8s/apiserver/src/url"
+	"minik8s/util/configutil"
 	"testing"
 )
 
 func Test(t *testing.T) {
-	ig := New(url.PodIpURL, 16)
-	if err := ig.Clear(url.PodIpBase); err != nil {
+	base, mask := configutil.Current().PodIPRange()
+	ig := New(url.PodIpURL, mask)
+	if err := ig.Clear(base); err != nil {
 		t.Error(err)
 	}
 	t.Log(ig.GetCurrent())
@@ -0,0 +1,92 @@
+package registry
+
+import (
+	"minik8s/apiObject"
+	"minik8s/util/httputil"
+	"sync"
+)
+
+// The operations an admission plugin is asked about
+const (
+	OperationCreate = "CREATE"
+	OperationUpdate = "UPDATE"
+)
+
+// AdmissionAttributes describe a write on its way to storage
+type AdmissionAttributes struct {
+	Operation  string
+	Kind       string
+	Namespaced bool
+	Object     apiObject.Object
+	// OldObject is the stored object, only set for an update
+	OldObject apiObject.Object
+}
+
+// AdmissionPlugin inspects, and may change, the objects written through the
+// registry. Every mutating plugin runs before the Validate hook of the kind,
+// and every validating plugin after it, in the order they are registered.
+type AdmissionPlugin struct {
+	Name string
+	// Mutate may change attrs.Object, e.g. fill in defaults
+	Mutate func(attrs *AdmissionAttributes) error
+	// Validate rejects the write by returning an error
+	Validate func(attrs *AdmissionAttributes) error
+}
+
+var (
+	admissionLock    sync.RWMutex
+	admissionPlugins []*AdmissionPlugin
+)
+
+// RegisterAdmissionPlugin appends plugin to the admission chain
+func RegisterAdmissionPlugin(plugin *AdmissionPlugin) {
+	admissionLock.Lock()
+	defer admissionLock.Unlock()
+	admissionPlugins = append(admissionPlugins, plugin)
+}
+
+func plugins() []*AdmissionPlugin {
+	admissionLock.RLock()
+	defer admissionLock.RUnlock()
+	return admissionPlugins
+}
+
+// Admit runs the admission chain. validate is the Validate hook of the kind,
+// it may be nil. Objects that are not stored through a Kind, like pods, call
+// it directly.
+func Admit(attrs *AdmissionAttributes, validate func(obj apiObject.Object) error) error {
+	for _, plugin := range plugins() {
+		if plugin.Mutate != nil {
+			if err := plugin.Mutate(attrs); err != nil {
+				return &AdmissionError{Plugin: plugin.Name, Err: err}
+			}
+		}
+	}
+	if validate != nil {
+		if err := validate(attrs.Object); err != nil {
+			if _, ok := err.(httputil.APIStatus); ok {
+				return err
+			}
+			metadata := attrs.Object.Meta()
+			return httputil.NewInvalid(attrs.Kind, metadata.Namespace, metadata.Name, err)
+		}
+	}
+	for _, plugin := range plugins() {
+		if plugin.Validate != nil {
+			if err := plugin.Validate(attrs); err != nil {
+				return &AdmissionError{Plugin: plugin.Name, Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+func (k *Kind) admit(operation string, obj, old apiObject.Object) error {
+	return Admit(&AdmissionAttributes{
+		Operation:  operation,
+		Kind:       k.Kind,
+		Namespaced: k.Namespaced,
+		Object:     obj,
+		OldObject:  old,
+	}, k.Validate)
+}
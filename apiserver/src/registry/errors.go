@@ -0,0 +1,56 @@
+package registry
+
+import (
+	"fmt"
+	"minik8s/apiserver/src/etcd"
+	"minik8s/entity"
+	"minik8s/util/httputil"
+	"net/http"
+)
+
+// NewNotFound is returned when the object to read or modify does not exist
+func NewNotFound(kind, namespace, name string) error {
+	return httputil.NewNotFound(kind, namespace, name)
+}
+
+// NewAlreadyExists is returned when creating an object that exists
+func NewAlreadyExists(kind, namespace, name string) error {
+	return httputil.NewAlreadyExists(kind, namespace, name)
+}
+
+func IsNotFound(err error) bool {
+	return httputil.IsNotFound(err)
+}
+
+func IsAlreadyExists(err error) bool {
+	return httputil.IsAlreadyExists(err)
+}
+
+// AdmissionError is returned when an admission plugin rejects a write
+type AdmissionError struct {
+	Plugin string
+	Err    error
+}
+
+func (e *AdmissionError) Error() string {
+	return fmt.Sprintf("admission plugin %s denied the request: %s", e.Plugin, e.Err.Error())
+}
+
+// Status keeps the status of the error of the plugin, if it has one, and
+// otherwise tells the object is invalid.
+func (e *AdmissionError) Status() entity.Status {
+	status := entity.Status{Code: http.StatusUnprocessableEntity, Reason: entity.StatusReasonInvalid}
+	if cause, ok := e.Err.(httputil.APIStatus); ok {
+		status = cause.Status()
+	}
+	status.Message = e.Error()
+	return status
+}
+
+// StatusOf returns the Status err is answered with
+func StatusOf(err error) entity.Status {
+	if err == etcd.ErrConflict {
+		return httputil.NewConflict(err.Error()).Status()
+	}
+	return httputil.StatusOf(err)
+}
@@ -0,0 +1,161 @@
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"minik8s/apiserver/src/etcd"
+	"minik8s/apiserver/src/url"
+	"minik8s/util/httputil"
+	"minik8s/util/selectorutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ListOptions narrows a list or a watch down to the objects whose labels
+// match ?labelSelector= and whose fields match ?fieldSelector=, and splits a
+// list into pages of ?limit=N objects, the next one being asked for by
+// ?continue= with the token of the previous one.
+type ListOptions struct {
+	LabelSelector selectorutil.Selector
+	FieldSelector selectorutil.Selector
+	// Limit is the most objects a page holds, 0 meaning no limit
+	Limit    int64
+	Continue string
+}
+
+// ListMeta goes with a page: the revision the whole list is consistent with,
+// and the token of the next page, if any.
+type ListMeta struct {
+	ResourceVersion int64
+	Continue        string
+}
+
+// continueToken is where the next page starts. All pages are read at the
+// revision of the first one, so that together they are a consistent list.
+type continueToken struct {
+	Revision int64  `json:"rv"`
+	Start    string `json:"start"`
+}
+
+// ListOptionsFrom parses the selectors and the page of a list request, a
+// malformed one is a BadRequest.
+func ListOptionsFrom(c *gin.Context) (opts ListOptions, err error) {
+	if opts.LabelSelector, err = selectorutil.Parse(c.Query("labelSelector")); err != nil {
+		return opts, httputil.NewBadRequest(fmt.Sprintf("labelSelector: %s", err.Error()))
+	}
+	if opts.FieldSelector, err = selectorutil.Parse(c.Query("fieldSelector")); err != nil {
+		return opts, httputil.NewBadRequest(fmt.Sprintf("fieldSelector: %s", err.Error()))
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if opts.Limit, err = strconv.ParseInt(limit, 10, 64); err != nil || opts.Limit < 0 {
+			return opts, httputil.NewBadRequest(fmt.Sprintf("invalid limit %s", limit))
+		}
+	}
+	opts.Continue = c.Query("continue")
+	return opts, nil
+}
+
+// Everything tells whether the selectors let every object through
+func (opts ListOptions) Everything() bool {
+	return opts.LabelSelector.Empty() && opts.FieldSelector.Empty()
+}
+
+// Matches tells whether obj, an api object or a status, passes both selectors
+func (opts ListOptions) Matches(obj interface{}) bool {
+	if !opts.LabelSelector.Empty() && !opts.LabelSelector.Matches(selectorutil.LabelsOf(obj)) {
+		return false
+	}
+	if !opts.FieldSelector.Empty() && !opts.FieldSelector.Matches(selectorutil.FieldsOf(obj)) {
+		return false
+	}
+	return true
+}
+
+// Selected returns how ServeWatch tells whether a stored value is selected,
+// decoding it into a new object. It is nil if nothing is filtered out.
+func (opts ListOptions) Selected(newObject func() interface{}) func(value string) bool {
+	if opts.Everything() {
+		return nil
+	}
+	return func(value string) bool {
+		obj := newObject()
+		if err := json.Unmarshal([]byte(value), obj); err != nil {
+			return false
+		}
+		return opts.Matches(obj)
+	}
+}
+
+// ListPage reads the page opts asks for of the objects under prefix. decode
+// turns a stored value into an object, or tells that the key is to be
+// skipped, e.g. because it is not an object of the kind; objects that do not
+// match the selectors are skipped too. A page holds fewer than Limit objects
+// only if it is the last one.
+func ListPage(prefix string, opts ListOptions, decode func(kv etcd.KeyValue) (interface{}, bool)) (objs []interface{}, meta ListMeta, err error) {
+	start := prefix
+	if opts.Continue != "" {
+		var token continueToken
+		if token, err = decodeContinue(opts.Continue, prefix); err != nil {
+			return nil, meta, err
+		}
+		start, meta.ResourceVersion = token.Start, token.Revision
+	}
+
+	for {
+		kvs, revision, more, err := etcd.ListPage(prefix, start, meta.ResourceVersion, opts.Limit)
+		if err == etcd.ErrCompacted {
+			return nil, meta, httputil.NewExpired("the list has changed too much since the continue token was issued, list again from the start")
+		} else if err != nil {
+			return nil, meta, err
+		}
+		meta.ResourceVersion = revision
+
+		for i, kv := range kvs {
+			obj, ok := decode(kv)
+			if !ok || !opts.Matches(obj) {
+				continue
+			}
+			objs = append(objs, obj)
+			if opts.Limit > 0 && int64(len(objs)) == opts.Limit {
+				if more || i < len(kvs)-1 {
+					meta.Continue = encodeContinue(continueToken{Revision: revision, Start: kv.Key + "\x00"})
+				}
+				return objs, meta, nil
+			}
+		}
+		if !more || len(kvs) == 0 {
+			return objs, meta, nil
+		}
+		// Some were filtered out, read on to fill the page
+		start = kvs[len(kvs)-1].Key + "\x00"
+	}
+}
+
+// WritePage answers a list request with objs as a JSON array, along with the
+// X-Resource-Version and X-Continue headers of meta.
+func WritePage(c *gin.Context, objs interface{}, meta ListMeta) {
+	c.Header(url.ResourceVersionHeader, strconv.FormatInt(meta.ResourceVersion, 10))
+	if meta.Continue != "" {
+		c.Header(url.ContinueHeader, meta.Continue)
+	}
+	c.JSON(http.StatusOK, objs)
+}
+
+func encodeContinue(token continueToken) string {
+	raw, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeContinue(encoded, prefix string) (token continueToken, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err == nil {
+		err = json.Unmarshal(raw, &token)
+	}
+	if err != nil || token.Revision <= 0 || !strings.HasPrefix(token.Start, prefix) {
+		return token, httputil.NewBadRequest(fmt.Sprintf("invalid continue token %s", encoded))
+	}
+	return token, nil
+}
@@ -0,0 +1,31 @@
+package registry
+
+import (
+	"github.com/stretchr/testify/assert"
+	"minik8s/entity"
+	"minik8s/util/httputil"
+	"minik8s/util/selectorutil"
+	"testing"
+)
+
+func TestListOptionsMatches(t *testing.T) {
+	fieldSelector, _ := selectorutil.Parse("spec.nodeName=node1,status.phase in (Running)")
+	opts := ListOptions{FieldSelector: fieldSelector}
+	assert.True(t, opts.Matches(&entity.PodStatus{Name: "a", Node: "node1", Lifecycle: entity.PodRunning}))
+	assert.False(t, opts.Matches(&entity.PodStatus{Name: "b", Node: "node2", Lifecycle: entity.PodRunning}))
+	assert.False(t, opts.Matches(&entity.PodStatus{Name: "c", Node: "node1", Lifecycle: entity.PodError}))
+}
+
+func TestContinueToken(t *testing.T) {
+	prefix := "/api/v1/replicaSets/"
+	token := continueToken{Revision: 42, Start: prefix + "default/rs\x00"}
+	decoded, err := decodeContinue(encodeContinue(token), prefix)
+	assert.Nil(t, err)
+	assert.Equal(t, token, decoded)
+
+	// A token of another list, or garbage, is rejected
+	_, err = decodeContinue(encodeContinue(token), "/api/v1/service/")
+	assert.True(t, httputil.IsBadRequest(err))
+	_, err = decodeContinue("not a token", prefix)
+	assert.True(t, httputil.IsBadRequest(err))
+}
@@ -0,0 +1,184 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/contentType"
+	"minik8s/apiserver/src/etcd"
+	"minik8s/util/httputil"
+	"minik8s/util/patchutil"
+)
+
+// maxPatchAttempts is how many times a patch is applied again to the latest
+// object when another write comes in between.
+const maxPatchAttempts = 3
+
+// ApplyPatch applies a patch of the given content type to the JSON of an
+// object, a malformed or failing patch being a BadRequest.
+func ApplyPatch(patchType string, original, patch []byte) ([]byte, error) {
+	var patched []byte
+	var err error
+	switch patchType {
+	case contentType.MergePatch:
+		patched, err = patchutil.MergePatch(original, patch)
+	case contentType.JsonPatch:
+		patched, err = patchutil.JSONPatch(original, patch)
+	case contentType.ApplyPatch:
+		patched, err = applyConfiguration(original, patch)
+	default:
+		return nil, httputil.NewUnsupportedMediaType(patchType)
+	}
+	if err != nil {
+		return nil, httputil.NewBadRequest(err.Error())
+	}
+	return patched, nil
+}
+
+// lastApplied returns what the last-applied annotation records of config:
+// the fields it sets, without the annotation itself.
+func lastApplied(config []byte) ([]byte, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(config, &fields); err != nil {
+		return nil, err
+	}
+	if metadata, ok := fields["Metadata"].(map[string]interface{}); ok {
+		if annotations, ok := metadata["Annotations"].(map[string]interface{}); ok {
+			delete(annotations, apiObject.LastAppliedConfigAnnotation)
+		}
+	}
+	raw, _ := json.Marshal(fields)
+	return patchutil.OmitEmpty(raw)
+}
+
+// applyConfiguration makes the object current match config with a three-way
+// merge against the configuration applied last time, and records config in
+// the last-applied annotation.
+func applyConfiguration(current, config []byte) ([]byte, error) {
+	modified, err := lastApplied(config)
+	if err != nil {
+		return nil, err
+	}
+	var stored apiObject.Base
+	if err = json.Unmarshal(current, &stored); err != nil {
+		return nil, err
+	}
+	original := []byte(stored.Metadata.Annotations[apiObject.LastAppliedConfigAnnotation])
+
+	var patch, patched []byte
+	if patch, err = patchutil.ThreeWayMergePatch(original, modified, current); err != nil {
+		return nil, err
+	}
+	if patched, err = patchutil.MergePatch(current, patch); err != nil {
+		return nil, err
+	}
+	annotation, _ := json.Marshal(map[string]interface{}{
+		"Metadata": map[string]interface{}{
+			"Annotations": map[string]string{apiObject.LastAppliedConfigAnnotation: string(modified)},
+		},
+	})
+	return patchutil.MergePatch(patched, annotation)
+}
+
+// SetLastApplied records config in the last-applied annotation of obj, which
+// is about to be created from it.
+func SetLastApplied(obj apiObject.Object, config []byte) error {
+	modified, err := lastApplied(config)
+	if err != nil {
+		return httputil.NewBadRequest(err.Error())
+	}
+	metadata := obj.Meta()
+	if metadata.Annotations == nil {
+		metadata.Annotations = make(apiObject.Annotations)
+	}
+	metadata.Annotations[apiObject.LastAppliedConfigAnnotation] = string(modified)
+	return nil
+}
+
+// Patch applies a patch to the JSON of a stored object, as served by Get, and
+// updates the object with the result through Update. resourceVersion, or a
+// resourceVersion set by the patch, makes it fail with a Conflict if the
+// object has changed since; without either, the patch is applied again to
+// the latest object if another write came in between. A patch that changes
+// nothing does not update the object. The patch and the object returned are
+// of the version served.
+func (k *Kind) Patch(namespace, name, patchType string, patch []byte, resourceVersion string) (obj apiObject.Object, err error) {
+	for attempt := 1; ; attempt++ {
+		var old, hub apiObject.Object
+		if old, err = k.Get(namespace, name); err != nil {
+			return nil, err
+		}
+		if resourceVersion != "" && resourceVersion != old.Meta().ResourceVersion {
+			return nil, etcd.ErrConflict
+		}
+		if old, err = k.fromHub(old); err != nil {
+			return nil, err
+		}
+
+		var original, patched, updated []byte
+		if original, err = json.Marshal(old); err != nil {
+			return nil, err
+		}
+		if patched, err = ApplyPatch(patchType, original, patch); err != nil {
+			return nil, err
+		}
+		if err = k.checkApiVersion(patched); err != nil {
+			return nil, err
+		}
+		obj = k.newServed()
+		if err = json.Unmarshal(patched, obj); err != nil {
+			return nil, httputil.NewBadRequest(err.Error())
+		}
+		// The URL names the object, whatever the patch says
+		obj.Meta().Namespace = namespace
+		obj.Meta().Name = name
+		if updated, _ = json.Marshal(obj); bytes.Equal(updated, original) {
+			return old, nil
+		}
+
+		pinned := resourceVersion != "" || obj.Meta().ResourceVersion != old.Meta().ResourceVersion
+		if hub, err = k.toHub(obj); err != nil {
+			return nil, err
+		}
+		if err = k.Update(hub); err != etcd.ErrConflict || pinned || attempt == maxPatchAttempts {
+			if err != nil {
+				return nil, err
+			}
+			return k.fromHub(hub)
+		}
+	}
+}
+
+// Apply serves kubectl apply: it updates the object namespace/name to match
+// config if it exists, as a patch of type contentType.ApplyPatch, or else
+// creates it from config. config and the object returned are of the version
+// served.
+func (k *Kind) Apply(namespace, name string, config []byte) (obj apiObject.Object, created bool, err error) {
+	if obj, err = k.Patch(namespace, name, contentType.ApplyPatch, config, ""); !IsNotFound(err) {
+		return obj, false, err
+	}
+
+	if err = k.checkApiVersion(config); err != nil {
+		return nil, false, err
+	}
+	obj = k.newServed()
+	if err = json.Unmarshal(config, obj); err != nil {
+		return nil, false, httputil.NewBadRequest(err.Error())
+	}
+	if err = SetLastApplied(obj, config); err != nil {
+		return nil, false, err
+	}
+	obj.Meta().Namespace = namespace
+	obj.Meta().Name = name
+	var hub apiObject.Object
+	if hub, err = k.toHub(obj); err != nil {
+		return nil, false, err
+	}
+	if err = k.Create(hub); err != nil {
+		return nil, false, err
+	}
+	if obj, err = k.fromHub(hub); err != nil {
+		return nil, false, err
+	}
+	return obj, true, nil
+}
@@ -0,0 +1,41 @@
+package registry
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/contentType"
+	"testing"
+)
+
+func TestApplyConfiguration(t *testing.T) {
+	rs := apiObject.ReplicaSet{}
+	rs.Metadata.Name = "rs"
+	rs.Metadata.Labels = apiObject.Labels{"app": "foo", "tier": "db"}
+	rs.Spec.Replicas = 3
+	config, _ := json.Marshal(rs)
+	assert.Nil(t, SetLastApplied(&rs, config))
+
+	// The server and other clients change the stored object
+	rs.Metadata.UID = "1234"
+	rs.Metadata.Labels["owner"] = "hpa"
+	current, _ := json.Marshal(rs)
+
+	modified := apiObject.ReplicaSet{}
+	modified.Metadata.Name = "rs"
+	modified.Metadata.Labels = apiObject.Labels{"app": "bar"}
+	modified.Spec.Replicas = 5
+	config, _ = json.Marshal(modified)
+
+	patched, err := ApplyPatch(contentType.ApplyPatch, current, config)
+	assert.Nil(t, err)
+	applied := apiObject.ReplicaSet{}
+	assert.Nil(t, json.Unmarshal(patched, &applied))
+	assert.Equal(t, "1234", applied.Metadata.UID)
+	assert.Equal(t, apiObject.Labels{"app": "bar", "owner": "hpa"}, applied.Metadata.Labels)
+	assert.Equal(t, 5, applied.Spec.Replicas)
+	assert.JSONEq(t, `{"Metadata":{"Name":"rs","Labels":{"app":"bar"}},"Spec":{"Replicas":5}}`, applied.Metadata.Annotations[apiObject.LastAppliedConfigAnnotation])
+
+	_, err = ApplyPatch("text/plain", current, config)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,170 @@
+package registry
+
+import (
+	"github.com/gin-gonic/gin"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Hooks customize the generic storage of a kind. Every hook is optional.
+// Unlike those of Validate, the errors of the hooks are returned as they are,
+// an error without a Status being an InternalError, so the mistakes of the
+// client must be returned as e.g. httputil.NewInvalid.
+type Hooks struct {
+	// PrepareForCreate defaults the fields owned by the api-server before the
+	// object is admitted. It must have no side effects, the object may still
+	// be rejected.
+	PrepareForCreate func(obj apiObject.Object) error
+	// BeforeCreate takes what the object is stored with once it is admitted,
+	// e.g. a cluster ip or the nginx of a dns.
+	BeforeCreate func(obj apiObject.Object) error
+	// AbortCreate undoes what BeforeCreate took when the object is not stored
+	// after all, e.g. it lost the race with another create of the same name,
+	// or BeforeCreate itself failed halfway.
+	AbortCreate func(obj apiObject.Object)
+	// PrepareForUpdate carries over what the client must not change.
+	PrepareForUpdate func(obj, old apiObject.Object) error
+	// Validate rejects an object before it is stored
+	Validate func(obj apiObject.Object) error
+	// AuthorizeWrite rejects an object the sender of a request may not write,
+	// e.g. a role granting more than they are allowed themselves. It runs
+	// along with Validate for the writes sent to the REST handlers.
+	AuthorizeWrite func(c *gin.Context, obj apiObject.Object) error
+	// AfterCreate, AfterUpdate and AfterDelete run the side effects of a
+	// successful write, e.g. publishing a ReplicaSetUpdate.
+	AfterCreate func(obj apiObject.Object)
+	AfterUpdate func(obj, old apiObject.Object)
+	AfterDelete func(obj apiObject.Object)
+	// BeforeDelete may keep the object instead of deleting it right away,
+	// e.g. until what it contains is gone. It then has to remove the object
+	// itself later on.
+	BeforeDelete func(obj apiObject.Object) (keep bool, err error)
+}
+
+// Kind describes how the objects of a kind are stored and served. Objects
+// live in etcd under Prefix/namespace/name, or Prefix/name if the kind is
+// not namespaced, and are served under the same URLs.
+type Kind struct {
+	Kind       string
+	Prefix     string
+	Namespaced bool
+	New        func() apiObject.Object
+	Hooks
+
+	// ApiVersion is the apiVersion of the hub version of the kind, the type
+	// New returns, which is served under Prefix.
+	ApiVersion string
+	// Versions are the other versions the kind is served in, converting to
+	// and from the hub version.
+	Versions []*Version
+	// StorageVersion is the apiVersion the objects are stored in, ApiVersion
+	// if it is empty. The objects stored in another version are read all the
+	// same, Migrate rewrites them in the storage version.
+	StorageVersion string
+
+	// Group, Version and Plural are only set for the kinds registered by a
+	// CustomResourceDefinition, which are served under /apis/group/version/plural/.
+	Group   string
+	Version string
+	Plural  string
+
+	// served is the version the kind returned by InVersion serves, nil for
+	// the hub version.
+	served *Version
+}
+
+var (
+	lock  sync.RWMutex
+	kinds = make(map[string]*Kind)
+)
+
+// Register adds a kind to the registry, replacing any kind of the same name
+func Register(kind *Kind) {
+	lock.Lock()
+	defer lock.Unlock()
+	kinds[strings.ToLower(kind.Kind)] = kind
+}
+
+// Unregister removes a kind from the registry
+func Unregister(kindName string) {
+	lock.Lock()
+	defer lock.Unlock()
+	delete(kinds, strings.ToLower(kindName))
+}
+
+// Lookup finds a kind by its name, case-insensitively
+func Lookup(kindName string) *Kind {
+	lock.RLock()
+	defer lock.RUnlock()
+	return kinds[strings.ToLower(kindName)]
+}
+
+// LookupResource finds a custom kind by its group and plural name
+func LookupResource(group, plural string) *Kind {
+	lock.RLock()
+	defer lock.RUnlock()
+	for _, kind := range kinds {
+		if kind.Group != "" && kind.Group == group && kind.Plural == plural {
+			return kind
+		}
+	}
+	return nil
+}
+
+// Kinds returns all registered kinds, sorted by name
+func Kinds() []*Kind {
+	lock.RLock()
+	defer lock.RUnlock()
+	var all []*Kind
+	for _, kind := range kinds {
+		all = append(all, kind)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Kind < all[j].Kind
+	})
+	return all
+}
+
+// Key returns the etcd key of an object
+func (k *Kind) Key(namespace, name string) string {
+	if k.Namespaced {
+		return path.Join(k.Prefix, namespace, name)
+	}
+	return path.Join(k.Prefix, name)
+}
+
+// SelfLink returns the URL of an object, which differs from its key for the
+// custom kinds.
+func (k *Kind) SelfLink(namespace, name string) string {
+	if k.Group == "" {
+		return k.Key(namespace, name)
+	}
+	resourceURL := path.Join(url.CustomResourceURL, k.Group, k.Version, k.Plural)
+	if k.Namespaced {
+		return path.Join(resourceURL, namespace, name)
+	}
+	return path.Join(resourceURL, name)
+}
+
+// ItemURL returns the route of a single object, e.g. /api/v1/hpa/:namespace/:name
+func (k *Kind) ItemURL() string {
+	if k.Namespaced {
+		return path.Join(k.Prefix, ":namespace", ":name")
+	}
+	return path.Join(k.Prefix, ":name")
+}
+
+// isObjectKey tells whether key, which is under the prefix of the kind, is an
+// object of the kind. Other keys, like statuses, share the prefix but live
+// at a different depth.
+func (k *Kind) isObjectKey(key string) bool {
+	depth := 1
+	if k.Namespaced {
+		depth = 2
+	}
+	return len(strings.Split(strings.TrimPrefix(key, k.Prefix), "/")) == depth
+}
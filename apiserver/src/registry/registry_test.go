@@ -0,0 +1,41 @@
+package registry
+
+import (
+	"github.com/stretchr/testify/assert"
+	"minik8s/apiObject"
+	"testing"
+)
+
+func TestKindKeys(t *testing.T) {
+	rs := &Kind{Kind: "ReplicaSet", Prefix: "/api/v1/replicaSets/", Namespaced: true}
+	assert.Equal(t, "/api/v1/replicaSets/default/rs", rs.Key("default", "rs"))
+	assert.Equal(t, "/api/v1/replicaSets/:namespace/:name", rs.ItemURL())
+	assert.True(t, rs.isObjectKey("/api/v1/replicaSets/default/rs"))
+	assert.False(t, rs.isObjectKey("/api/v1/replicaSets/status/default/rs"))
+
+	// the selector indexes of services are not services
+	service := &Kind{Kind: "Service", Prefix: "/api/v1/service/", Namespaced: true}
+	assert.False(t, service.isObjectKey("/api/v1/service/app/nginx/8a7b"))
+
+	fn := &Kind{Kind: "Function", Prefix: "/api/v1/func/"}
+	assert.Equal(t, "/api/v1/func/f", fn.Key("default", "f"))
+	assert.Equal(t, "/api/v1/func/:name", fn.ItemURL())
+	assert.True(t, fn.isObjectKey("/api/v1/func/f"))
+	assert.False(t, fn.isObjectKey("/api/v1/func/f/pods"))
+}
+
+func TestRegister(t *testing.T) {
+	kind := &Kind{
+		Kind:   "TestKind",
+		Prefix: "/api/v1/test/",
+		New:    func() apiObject.Object { return &apiObject.Node{} },
+	}
+	Register(kind)
+	defer Unregister(kind.Kind)
+
+	assert.Equal(t, kind, Lookup("testkind"))
+	assert.Contains(t, Kinds(), kind)
+
+	Unregister("TESTKIND")
+	assert.Nil(t, Lookup(kind.Kind))
+}
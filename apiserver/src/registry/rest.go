@@ -0,0 +1,184 @@
+package registry
+
+import (
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/contentType"
+	"minik8s/apiserver/src/etcd"
+	"minik8s/util/httputil"
+	"minik8s/util/logger"
+	"net/http"
+)
+
+// WriteError answers a failed request with the Status of err, e.g. 409
+// Conflict for a stale write so that clients know they should re-read and
+// retry.
+func WriteError(c *gin.Context, err error) {
+	status := StatusOf(err)
+	c.JSON(status.Code, status)
+}
+
+// forRequest returns the kind as it stores the writes of the request c, which
+// are authorized by AuthorizeWrite along with Validate.
+func (k *Kind) forRequest(c *gin.Context) *Kind {
+	if k.AuthorizeWrite == nil {
+		return k
+	}
+	kind := *k
+	kind.Validate = func(obj apiObject.Object) error {
+		if k.Validate != nil {
+			if err := k.Validate(obj); err != nil {
+				return err
+			}
+		}
+		return k.AuthorizeWrite(c, obj)
+	}
+	return &kind
+}
+
+// HandleCreate serves POST Prefix, e.g. kubectl apply -f rs.yaml, optionally
+// with ?fieldValidation=Strict
+func HandleCreate(kind *Kind) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		kind := kind.forRequest(c)
+		obj, err := kind.decodeBody(c)
+		if err != nil {
+			WriteError(c, err)
+			return
+		}
+		if err = kind.Create(obj); err != nil {
+			WriteError(c, err)
+			return
+		}
+		c.String(http.StatusOK, "ok")
+	}
+}
+
+// HandleGet serves GET ItemURL
+func HandleGet(kind *Kind) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		obj, err := kind.Get(c.Param("namespace"), c.Param("name"))
+		if err == nil {
+			obj, err = kind.fromHub(obj)
+		}
+		if err != nil {
+			WriteError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, obj)
+	}
+}
+
+// HandleList serves GET Prefix, optionally with ?labelSelector=,
+// ?fieldSelector= and ?limit=N&continue=<token>. A plain list gets the
+// revision it is consistent with in the X-Resource-Version header, so that
+// clients can list and then ?watch=true from there without missing anything.
+func HandleList(kind *Kind) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, err := ListOptionsFrom(c)
+		if err != nil {
+			WriteError(c, err)
+			return
+		}
+		if IsWatch(c) {
+			ServeWatch(c, kind.Prefix, kind.isObjectKey, kind.ServedValue, opts.Selected(func() interface{} { return kind.newServed() }))
+			return
+		}
+		objs, meta, err := ListPage(kind.Prefix, opts, func(kv etcd.KeyValue) (interface{}, bool) {
+			obj, ok := kind.decode(kv)
+			if !ok {
+				return nil, false
+			}
+			served, err := kind.fromHub(obj)
+			if err != nil {
+				logger.Error(err.Error())
+				return nil, false
+			}
+			return served, true
+		})
+		if err != nil {
+			WriteError(c, err)
+			return
+		}
+		if objs == nil {
+			objs = []interface{}{}
+		}
+		WritePage(c, objs, meta)
+	}
+}
+
+// HandleUpdate serves PUT ItemURL with the whole new object as body,
+// optionally with ?fieldValidation=Strict
+func HandleUpdate(kind *Kind) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		kind := kind.forRequest(c)
+		obj, err := kind.decodeBody(c)
+		if err != nil {
+			WriteError(c, err)
+			return
+		}
+		// The URL names the object, whatever the body says
+		obj.Meta().Namespace = c.Param("namespace")
+		obj.Meta().Name = c.Param("name")
+		if err = kind.Update(obj); err != nil {
+			WriteError(c, err)
+			return
+		}
+		c.String(http.StatusOK, "ok")
+	}
+}
+
+// HandlePatch serves PATCH ItemURL with a JSON merge patch or a JSON patch of
+// the object, as told by the Content-Type, optionally with ?resourceVersion=N
+// and ?fieldValidation=Strict. A configuration sent by kubectl apply creates
+// the object if need be, which is answered with 201 Created. It answers the
+// patched object.
+func HandlePatch(kind *Kind) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		kind := kind.forRequest(c)
+		patch, err := ioutil.ReadAll(c.Request.Body)
+		if err != nil {
+			WriteError(c, httputil.NewBadRequest(err.Error()))
+			return
+		}
+		namespace, name := c.Param("namespace"), c.Param("name")
+		obj := kind.newServed()
+		obj.Meta().Namespace, obj.Meta().Name = namespace, name
+		if err = CheckUnknownPatchFields(c, kind.Kind, obj, patch); err != nil {
+			WriteError(c, err)
+			return
+		}
+		var created bool
+		if c.ContentType() == contentType.ApplyPatch {
+			obj, created, err = kind.Apply(namespace, name, patch)
+		} else {
+			obj, err = kind.Patch(namespace, name, c.ContentType(), patch, c.Query("resourceVersion"))
+		}
+		if err != nil {
+			WriteError(c, err)
+			return
+		}
+		if created {
+			c.JSON(http.StatusCreated, obj)
+			return
+		}
+		c.JSON(http.StatusOK, obj)
+	}
+}
+
+// HandleDelete serves DELETE ItemURL, optionally with ?resourceVersion=N and
+// ?propagationPolicy=Background|Foreground|Orphan
+func HandleDelete(kind *Kind) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts := DeleteOptions{
+			ResourceVersion:   c.Query("resourceVersion"),
+			PropagationPolicy: c.Query("propagationPolicy"),
+		}
+		if _, err := kind.Delete(c.Param("namespace"), c.Param("name"), opts); err != nil {
+			WriteError(c, err)
+			return
+		}
+		c.String(http.StatusOK, "ok")
+	}
+}
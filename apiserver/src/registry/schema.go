@@ -0,0 +1,193 @@
+package registry
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/contentType"
+	"minik8s/entity"
+	"minik8s/util/httputil"
+	"minik8s/util/patchutil"
+	"minik8s/util/schemautil"
+	"reflect"
+)
+
+// ?fieldValidation=Strict makes a write fail if its body has fields the kind
+// does not have, e.g. a misspelled one. Otherwise they are ignored.
+const (
+	FieldValidationParam  = "fieldValidation"
+	FieldValidationStrict = "Strict"
+)
+
+// ValidateSchema validates obj of kind against the schema generated from its
+// type, and its name against nameFormat unless it is "". All the invalid
+// fields are returned at once, as an Invalid error with a cause for each.
+// The fields are named as in the yaml of the object, e.g.
+// spec.containers[0].image.
+func ValidateSchema(kind string, obj apiObject.Object, nameFormat string) error {
+	errs, err := schemaErrors(obj, nameFormat)
+	if err != nil {
+		return err
+	}
+	metadata := obj.Meta()
+	return fieldsInvalid(kind, metadata.Namespace, metadata.Name, errs)
+}
+
+// ValidateSchemaUpdate is ValidateSchema for an update of old to obj, which
+// only fails with the errors old does not have already. An object stored
+// before it was validated, e.g. with a name it could not be created with
+// today, can still be updated as long as the update leaves its invalid
+// fields as they are.
+func ValidateSchemaUpdate(kind string, obj, old apiObject.Object, nameFormat string) error {
+	errs, err := schemaErrors(obj, nameFormat)
+	if err != nil || len(errs) == 0 {
+		return err
+	}
+	oldErrs, err := schemaErrors(old, nameFormat)
+	if err != nil {
+		return err
+	}
+	existing := make(map[schemautil.FieldError]bool, len(oldErrs))
+	for _, e := range oldErrs {
+		existing[*e] = true
+	}
+	var newErrs schemautil.FieldErrors
+	for _, e := range errs {
+		if !existing[*e] {
+			newErrs = append(newErrs, e)
+		}
+	}
+	metadata := obj.Meta()
+	return fieldsInvalid(kind, metadata.Namespace, metadata.Name, newErrs)
+}
+
+func schemaErrors(obj apiObject.Object, nameFormat string) (schemautil.FieldErrors, error) {
+	schema := schemautil.Generate(reflect.TypeOf(obj), schemautil.YAML)
+	var errs schemautil.FieldErrors
+	if len(schema) > 0 {
+		doc, err := schemaDocument(obj)
+		if err != nil {
+			return nil, err
+		}
+		errs = schemautil.Validate(schema, doc)
+	}
+	errs = append(errs, nameErrors("metadata.name", obj.Meta().Name, nameFormat)...)
+	return errs, nil
+}
+
+// ValidateName validates the name of an object of kind that has no schema,
+// e.g. a Function, against format unless it is "".
+func ValidateName(kind, namespace, name, format string) error {
+	return fieldsInvalid(kind, namespace, name, nameErrors("name", name, format))
+}
+
+// nameErrors are the errors of name, the field named field
+func nameErrors(field, name, format string) schemautil.FieldErrors {
+	if name == "" {
+		return schemautil.FieldErrors{{Field: field, Message: "is required"}}
+	}
+	var errs schemautil.FieldErrors
+	if format != "" {
+		for _, err := range schemautil.Validate(schemautil.Schema{"format": format}, name) {
+			errs = append(errs, &schemautil.FieldError{Field: field, Message: err.Message})
+		}
+	}
+	return errs
+}
+
+// schemaDocument returns obj as its yaml decodes, without its empty fields
+func schemaDocument(obj apiObject.Object) (interface{}, error) {
+	raw, err := yaml.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err = yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	if raw, err = json.Marshal(doc); err != nil {
+		return nil, err
+	}
+	if raw, err = patchutil.OmitEmpty(raw); err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(raw, &doc)
+	return doc, err
+}
+
+func fieldsInvalid(kind, namespace, name string, errs schemautil.FieldErrors) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	causes := make([]entity.StatusCause, len(errs))
+	for i, err := range errs {
+		causes[i] = entity.StatusCause{Field: err.Field, Message: err.Message}
+	}
+	return httputil.NewFieldsInvalid(kind, namespace, name, causes)
+}
+
+// IsStrict tells whether the request asks for ?fieldValidation=Strict
+func IsStrict(c *gin.Context) bool {
+	return c.Query(FieldValidationParam) == FieldValidationStrict
+}
+
+// CheckUnknownFields fails with all the fields of body, a JSON write of obj,
+// that obj does not have, if the request is strict.
+func CheckUnknownFields(c *gin.Context, kind string, obj apiObject.Object, body []byte) error {
+	if !IsStrict(c) {
+		return nil
+	}
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return httputil.NewBadRequest(err.Error())
+	}
+	metadata := obj.Meta()
+	schema := schemautil.Generate(reflect.TypeOf(obj), schemautil.JSON)
+	return fieldsInvalid(kind, metadata.Namespace, metadata.Name, schemautil.UnknownFields(schema, doc))
+}
+
+// CheckUnknownPatchFields is CheckUnknownFields for a patch of obj, of which
+// only a merge patch or an applied configuration looks like the object.
+func CheckUnknownPatchFields(c *gin.Context, kind string, obj apiObject.Object, patch []byte) error {
+	switch c.ContentType() {
+	case contentType.MergePatch, contentType.ApplyPatch:
+		return CheckUnknownFields(c, kind, obj, patch)
+	}
+	return nil
+}
+
+// DecodeBody reads the JSON body of a write into obj, checking it has no
+// unknown fields if the request is strict.
+func DecodeBody(c *gin.Context, kind string, obj apiObject.Object) error {
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		return httputil.NewBadRequest(err.Error())
+	}
+	return decode(c, kind, obj, body)
+}
+
+func decode(c *gin.Context, kind string, obj apiObject.Object, body []byte) error {
+	if err := json.Unmarshal(body, obj); err != nil {
+		return httputil.NewBadRequest(err.Error())
+	}
+	return CheckUnknownFields(c, kind, obj, body)
+}
+
+// decodeBody is DecodeBody for an object of the version served, which it
+// returns in the hub version.
+func (k *Kind) decodeBody(c *gin.Context) (apiObject.Object, error) {
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, httputil.NewBadRequest(err.Error())
+	}
+	if err = k.checkApiVersion(body); err != nil {
+		return nil, err
+	}
+	obj := k.newServed()
+	if err = decode(c, k.Kind, obj, body); err != nil {
+		return nil, err
+	}
+	return k.toHub(obj)
+}
@@ -0,0 +1,337 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/etcd"
+	"minik8s/util/httputil"
+	"minik8s/util/logger"
+	"minik8s/util/uidutil"
+	"strconv"
+	"time"
+)
+
+var log = logger.Log("Registry")
+
+// GetObject reads the object stored at key into obj and fills in its
+// resourceVersion. The returned revision is 0 if there is no such key.
+func GetObject(key string, obj apiObject.Object) (revision int64, err error) {
+	var raw string
+	if raw, revision, err = etcd.GetWithRevision(key); err != nil || revision == 0 {
+		return
+	}
+	if err = json.Unmarshal([]byte(raw), obj); err != nil {
+		return 0, err
+	}
+	obj.Meta().ResourceVersion = strconv.FormatInt(revision, 10)
+	return
+}
+
+// PutObject stores obj at key only if the key has not been modified since
+// revision, 0 meaning the key must not exist yet. The resourceVersion is
+// never persisted, it is derived from the etcd revision on every read.
+func PutObject(key string, obj apiObject.Object, revision int64) error {
+	return putObject(key, obj, revision, func(obj apiObject.Object) ([]byte, error) {
+		return json.Marshal(obj)
+	})
+}
+
+func putObject(key string, obj apiObject.Object, revision int64, encode func(obj apiObject.Object) ([]byte, error)) error {
+	obj.Meta().ResourceVersion = ""
+	objJson, err := encode(obj)
+	if err != nil {
+		return err
+	}
+	var newRevision int64
+	if newRevision, err = etcd.CompareAndPut(key, string(objJson), revision); err != nil {
+		return err
+	}
+	obj.Meta().ResourceVersion = strconv.FormatInt(newRevision, 10)
+	return nil
+}
+
+// CheckResourceVersion returns etcd.ErrConflict if the client sent a
+// resourceVersion that does not match the stored revision.
+func CheckResourceVersion(resourceVersion string, revision int64) error {
+	if resourceVersion != "" && resourceVersion != strconv.FormatInt(revision, 10) {
+		return etcd.ErrConflict
+	}
+	return nil
+}
+
+// getObject reads the object of the kind stored at key, see GetObject
+func (k *Kind) getObject(key string) (obj apiObject.Object, revision int64, err error) {
+	var raw string
+	if raw, revision, err = etcd.GetWithRevision(key); err != nil || revision == 0 {
+		return nil, revision, err
+	}
+	if obj, err = k.decodeStored([]byte(raw)); err != nil {
+		return nil, 0, err
+	}
+	obj.Meta().ResourceVersion = strconv.FormatInt(revision, 10)
+	return obj, revision, nil
+}
+
+// putObject stores obj, see PutObject, in the storage version of the kind
+func (k *Kind) putObject(key string, obj apiObject.Object, revision int64) error {
+	return putObject(key, obj, revision, k.encodeStored)
+}
+
+func (k *Kind) objectKey(obj apiObject.Object) string {
+	return k.Key(obj.Meta().Namespace, obj.Meta().Name)
+}
+
+// Get returns the object namespace/name, or a NotFound error
+func (k *Kind) Get(namespace, name string) (apiObject.Object, error) {
+	obj, revision, err := k.getObject(k.Key(namespace, name))
+	if err != nil {
+		return nil, err
+	} else if revision == 0 {
+		return nil, NewNotFound(k.Kind, namespace, name)
+	}
+	return obj, nil
+}
+
+// List returns all objects of the kind, and the revision the list is
+// consistent with.
+func (k *Kind) List() (objs []apiObject.Object, revision int64, err error) {
+	var kvs []etcd.KeyValue
+	if kvs, revision, err = etcd.List(k.Prefix); err != nil {
+		return nil, 0, err
+	}
+	objs = make([]apiObject.Object, 0, len(kvs))
+	for _, kv := range kvs {
+		if obj, ok := k.decode(kv); ok {
+			objs = append(objs, obj)
+		}
+	}
+	return objs, revision, nil
+}
+
+// decode reads the object stored at kv, if it is one of the kind
+func (k *Kind) decode(kv etcd.KeyValue) (apiObject.Object, bool) {
+	if !k.isObjectKey(kv.Key) {
+		return nil, false
+	}
+	obj, err := k.decodeStored([]byte(kv.Value))
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, false
+	}
+	obj.Meta().ResourceVersion = strconv.FormatInt(kv.ModRevision, 10)
+	return obj, true
+}
+
+// Create stores a new object with a new UID. It runs PrepareForCreate and
+// the admission chain, then BeforeCreate once the object is admitted, and
+// AfterCreate once the object is stored, or AbortCreate if it is not.
+func (k *Kind) Create(obj apiObject.Object) (err error) {
+	metadata := obj.Meta()
+	if k.Namespaced && metadata.Namespace == "" {
+		// The key depends on it, the admission chain comes too late
+		metadata.Namespace = apiObject.DefaultNamespace
+	}
+
+	key := k.objectKey(obj)
+	if _, revision, err := etcd.GetWithRevision(key); err != nil {
+		return err
+	} else if revision != 0 {
+		return NewAlreadyExists(k.Kind, metadata.Namespace, metadata.Name)
+	}
+
+	metadata.UID = uidutil.New()
+	metadata.DeletionTimestamp = nil
+	if k.PrepareForCreate != nil {
+		if err = k.PrepareForCreate(obj); err != nil {
+			return
+		}
+	}
+	if err = k.admit(OperationCreate, obj, nil); err != nil {
+		return
+	}
+	if k.BeforeCreate != nil {
+		if err = k.BeforeCreate(obj); err != nil {
+			k.abortCreate(obj)
+			return
+		}
+	}
+
+	log("create %s %s[ID = %v]", k.Kind, key, metadata.UID)
+	if err = k.putObject(key, obj, 0); err != nil {
+		if err == etcd.ErrConflict {
+			err = NewAlreadyExists(k.Kind, metadata.Namespace, metadata.Name)
+		}
+		k.abortCreate(obj)
+		return
+	}
+
+	if k.AfterCreate != nil {
+		k.AfterCreate(obj)
+	}
+	return nil
+}
+
+func (k *Kind) abortCreate(obj apiObject.Object) {
+	if k.AbortCreate != nil {
+		log("abort creating %s %s", k.Kind, k.objectKey(obj))
+		k.AbortCreate(obj)
+	}
+}
+
+// Update replaces a stored object. If obj carries a resourceVersion, the
+// update only succeeds if the object has not changed since.
+func (k *Kind) Update(obj apiObject.Object) (err error) {
+	key := k.objectKey(obj)
+	metadata := obj.Meta()
+	old, revision, err := k.getObject(key)
+	if err != nil {
+		return
+	} else if revision == 0 {
+		return NewNotFound(k.Kind, metadata.Namespace, metadata.Name)
+	}
+	if err = CheckResourceVersion(metadata.ResourceVersion, revision); err != nil {
+		return
+	}
+
+	metadata.UID = old.Meta().UID
+	metadata.DeletionTimestamp = old.Meta().DeletionTimestamp
+	if k.PrepareForUpdate != nil {
+		if err = k.PrepareForUpdate(obj, old); err != nil {
+			return
+		}
+	}
+	if err = k.admit(OperationUpdate, obj, old); err != nil {
+		return
+	}
+
+	if metadata.DeletionTimestamp != nil && len(metadata.Finalizers) == 0 {
+		// The last finalizer is done
+		return k.remove(key, obj, revision)
+	}
+
+	log("update %s %s", k.Kind, key)
+	if err = k.putObject(key, obj, revision); err != nil {
+		return
+	}
+
+	if k.AfterUpdate != nil {
+		k.AfterUpdate(obj, old)
+	}
+	return nil
+}
+
+// DeleteOptions tell how to delete an object
+type DeleteOptions struct {
+	// ResourceVersion, if not empty, must match the stored object
+	ResourceVersion string
+	// PropagationPolicy is one of the apiObject.DeletePropagation policies,
+	// Background if empty.
+	PropagationPolicy string
+}
+
+func finalizerOf(propagationPolicy string) (string, error) {
+	switch propagationPolicy {
+	case "", apiObject.DeletePropagationBackground:
+		return "", nil
+	case apiObject.DeletePropagationForeground:
+		return apiObject.FinalizerForegroundDeletion, nil
+	case apiObject.DeletePropagationOrphan:
+		return apiObject.FinalizerOrphanDependents, nil
+	}
+	return "", httputil.NewBadRequest(fmt.Sprintf("invalid propagationPolicy %s", propagationPolicy))
+}
+
+// Delete removes the object namespace/name and returns it. An object with
+// finalizers, which a Foreground or Orphan deletion adds, is only marked with
+// a deletionTimestamp, it is removed by the Update that clears the last
+// finalizer. BeforeDelete may keep the object as well.
+func (k *Kind) Delete(namespace, name string, opts DeleteOptions) (obj apiObject.Object, err error) {
+	var finalizer string
+	if finalizer, err = finalizerOf(opts.PropagationPolicy); err != nil {
+		return nil, err
+	}
+
+	key := k.Key(namespace, name)
+	var revision int64
+	if obj, revision, err = k.getObject(key); err != nil {
+		return nil, err
+	} else if revision == 0 {
+		return nil, NewNotFound(k.Kind, namespace, name)
+	}
+	if err = CheckResourceVersion(opts.ResourceVersion, revision); err != nil {
+		return nil, err
+	}
+	if k.BeforeDelete != nil {
+		var keep bool
+		if keep, err = k.BeforeDelete(obj); err != nil {
+			return nil, err
+		} else if keep {
+			return obj, nil
+		}
+	}
+
+	metadata := obj.Meta()
+	if finalizer != "" && !metadata.HasFinalizer(finalizer) {
+		metadata.Finalizers = append(metadata.Finalizers, finalizer)
+	}
+	if len(metadata.Finalizers) > 0 {
+		if metadata.DeletionTimestamp != nil && finalizer == "" {
+			return obj, nil
+		}
+		return obj, k.markDeleted(key, obj, revision)
+	}
+
+	return obj, k.remove(key, obj, revision)
+}
+
+// markDeleted stores obj with a deletionTimestamp, the controllers learn about
+// it by AfterUpdate and should stop working on it.
+func (k *Kind) markDeleted(key string, obj apiObject.Object, revision int64) error {
+	old, _, err := k.getObject(key)
+	if err != nil {
+		return err
+	}
+	metadata := obj.Meta()
+	if metadata.DeletionTimestamp == nil {
+		now := time.Now()
+		metadata.DeletionTimestamp = &now
+	}
+
+	log("delete %s %s, waiting for %v", k.Kind, key, metadata.Finalizers)
+	if err := k.putObject(key, obj, revision); err != nil {
+		return err
+	}
+	if k.AfterUpdate != nil {
+		k.AfterUpdate(obj, old)
+	}
+	return nil
+}
+
+func (k *Kind) remove(key string, obj apiObject.Object, revision int64) error {
+	log("delete %s %s", k.Kind, key)
+	if err := etcd.CompareAndDelete(key, revision); err != nil {
+		return err
+	}
+	if k.AfterDelete != nil {
+		k.AfterDelete(obj)
+	}
+	return nil
+}
+
+// CheckNamespace returns an error unless namespace exists and is Active, so
+// that nothing is created in a namespace that is being deleted.
+func CheckNamespace(namespace string) error {
+	kind := Lookup("Namespace")
+	if kind == nil {
+		return nil
+	}
+	obj, err := kind.Get("", namespace)
+	if err != nil {
+		return err
+	}
+	if ns := obj.(*apiObject.Namespace); ns.Phase() != apiObject.NamespaceActive {
+		return httputil.NewForbidden(fmt.Sprintf("namespace %s is %s", namespace, ns.Phase()))
+	}
+	return nil
+}
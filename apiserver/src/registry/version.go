@@ -0,0 +1,261 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/etcd"
+	"minik8s/apiserver/src/url"
+	"minik8s/util/httputil"
+	"minik8s/util/logger"
+	"path"
+	"strings"
+)
+
+// Version is a version a kind is served in besides its hub version, the
+// apiObject type New returns, which is what the rest of the cluster works
+// with. Its objects are served under /apis/group/version/resource/, the
+// resource being the same as in the URLs of the hub version, e.g.
+// /apis/autoscaling/v2/hpa/, so that the roles allowing it cover every
+// version.
+type Version struct {
+	// ApiVersion is group/version, e.g. autoscaling/v2
+	ApiVersion string
+	New        func() apiObject.Convertible
+}
+
+// resource names the objects of the kind in its URLs, e.g. hpa
+func (k *Kind) resource() string {
+	return path.Base(k.Prefix)
+}
+
+// VersionURL returns where the objects of version are served
+func (k *Kind) VersionURL(version *Version) string {
+	return path.Join(url.CustomResourceURL, version.ApiVersion, k.resource()) + "/"
+}
+
+// version returns the version of the kind named apiVersion, or nil for the
+// hub version and the apiVersions the kind does not have.
+func (k *Kind) version(apiVersion string) *Version {
+	for _, version := range k.Versions {
+		if version.ApiVersion == apiVersion {
+			return version
+		}
+	}
+	return nil
+}
+
+// StorageApiVersion returns the apiVersion the objects are stored in
+func (k *Kind) StorageApiVersion() string {
+	if k.StorageVersion != "" {
+		return k.StorageVersion
+	}
+	return k.ApiVersion
+}
+
+// InVersion returns the kind as served in version, one of its Versions. Its
+// handlers take and answer objects of that version, which it stores as any
+// other object of the kind.
+func (k *Kind) InVersion(version *Version) *Kind {
+	served := *k
+	served.served = version
+	return &served
+}
+
+func (k *Kind) servedApiVersion() string {
+	if k.served != nil {
+		return k.served.ApiVersion
+	}
+	return k.ApiVersion
+}
+
+// newServed returns a new object of the version served
+func (k *Kind) newServed() apiObject.Object {
+	if k.served != nil {
+		return k.served.New()
+	}
+	return k.New()
+}
+
+// setHubVersion sets the apiVersion of obj, an object of the hub version,
+// which clients may have left empty or set as they liked before it was
+// checked.
+func (k *Kind) setHubVersion(obj apiObject.Object) {
+	if k.ApiVersion != "" {
+		obj.SetApiVersion(k.ApiVersion)
+	}
+}
+
+// checkApiVersion rejects raw, an object sent to the kind, if it is of
+// another version than the one served, which it would be mistaken for.
+// Another apiVersion the kind does not have is taken for the one served,
+// since clients set it as they liked before it was checked.
+func (k *Kind) checkApiVersion(raw []byte) error {
+	var base apiObject.Base
+	if err := json.Unmarshal(raw, &base); err != nil {
+		return httputil.NewBadRequest(err.Error())
+	}
+	apiVersion := base.ApiVersion
+	if apiVersion == "" || apiVersion == k.servedApiVersion() {
+		return nil
+	}
+	if version := k.version(apiVersion); version != nil {
+		return httputil.NewBadRequest(fmt.Sprintf("%s %s is served under %s", apiVersion, k.Kind, k.VersionURL(version)))
+	}
+	if k.served != nil && apiVersion == k.ApiVersion {
+		return httputil.NewBadRequest(fmt.Sprintf("%s %s is served under %s", apiVersion, k.Kind, k.Prefix))
+	}
+	return nil
+}
+
+// toHub converts obj, an object of the version served, to the hub version
+func (k *Kind) toHub(obj apiObject.Object) (apiObject.Object, error) {
+	if k.served == nil {
+		k.setHubVersion(obj)
+		return obj, nil
+	}
+	hub := k.New()
+	if err := obj.(apiObject.Convertible).ConvertTo(hub); err != nil {
+		metadata := obj.Meta()
+		return nil, httputil.NewInvalid(k.Kind, metadata.Namespace, metadata.Name, err)
+	}
+	k.setHubVersion(hub)
+	return hub, nil
+}
+
+// fromHub converts hub to the version served
+func (k *Kind) fromHub(hub apiObject.Object) (apiObject.Object, error) {
+	if k.served == nil {
+		return hub, nil
+	}
+	obj := k.served.New()
+	if err := obj.ConvertFrom(hub); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// decodeStored reads an object as stored, in whichever version it is, into
+// an object of the hub version.
+func (k *Kind) decodeStored(raw []byte) (apiObject.Object, error) {
+	var base apiObject.Base
+	if err := json.Unmarshal(raw, &base); err != nil {
+		return nil, err
+	}
+	obj := k.New()
+	if version := k.version(base.ApiVersion); version != nil {
+		stored := version.New()
+		if err := json.Unmarshal(raw, stored); err != nil {
+			return nil, err
+		}
+		if err := stored.ConvertTo(obj); err != nil {
+			return nil, err
+		}
+	} else if err := json.Unmarshal(raw, obj); err != nil {
+		return nil, err
+	}
+	k.setHubVersion(obj)
+	return obj, nil
+}
+
+// encodeStored returns obj, an object of the hub version, as it is stored:
+// in the storage version.
+func (k *Kind) encodeStored(obj apiObject.Object) ([]byte, error) {
+	k.setHubVersion(obj)
+	if version := k.version(k.StorageVersion); version != nil {
+		stored := version.New()
+		if err := stored.ConvertFrom(obj); err != nil {
+			return nil, err
+		}
+		return json.Marshal(stored)
+	}
+	return json.Marshal(obj)
+}
+
+// ServedValue turns an object of the kind as stored into its JSON in the
+// version served.
+func (k *Kind) ServedValue(value string) (string, error) {
+	hub, err := k.decodeStored([]byte(value))
+	if err != nil {
+		return "", err
+	}
+	obj, err := k.fromHub(hub)
+	if err != nil {
+		return "", err
+	}
+	raw, err := json.Marshal(obj)
+	return string(raw), err
+}
+
+// LookupVersion finds the kind served under /apis/group/version/resource/:
+// a custom kind, or a built-in kind in one of its Versions.
+func LookupVersion(group, version, resource string) *Kind {
+	lock.RLock()
+	defer lock.RUnlock()
+	apiVersion := group + "/" + version
+	for _, kind := range kinds {
+		if kind.Group != "" {
+			if kind.Group == group && kind.Version == version && kind.Plural == resource {
+				return kind
+			}
+			continue
+		}
+		if v := kind.version(apiVersion); v != nil && kind.resource() == resource {
+			return kind.InVersion(v)
+		}
+	}
+	return nil
+}
+
+// LookupPrefix finds the built-in kind stored and served under prefix
+func LookupPrefix(prefix string) *Kind {
+	lock.RLock()
+	defer lock.RUnlock()
+	for _, kind := range kinds {
+		if kind.Group == "" && kind.Prefix == prefix {
+			return kind
+		}
+	}
+	return nil
+}
+
+// Migrate rewrites the objects of the kind that are stored in another
+// version than the storage version, e.g. since it changed, and returns how
+// many it rewrote. An object written in between is stored in the storage
+// version already, it is left alone.
+func (k *Kind) Migrate() (migrated int, err error) {
+	storageVersion := k.StorageApiVersion()
+	if storageVersion == "" {
+		return 0, nil
+	}
+	var kvs []etcd.KeyValue
+	if kvs, _, err = etcd.List(k.Prefix); err != nil {
+		return 0, err
+	}
+	for _, kv := range kvs {
+		if !k.isObjectKey(kv.Key) {
+			continue
+		}
+		var base apiObject.Base
+		if err = json.Unmarshal([]byte(kv.Value), &base); err == nil && base.ApiVersion == storageVersion {
+			continue
+		}
+		var obj apiObject.Object
+		var raw []byte
+		if obj, err = k.decodeStored([]byte(kv.Value)); err == nil {
+			raw, err = k.encodeStored(obj)
+		}
+		if err != nil {
+			logger.Error(fmt.Sprintf("cannot migrate %s: %s", kv.Key, err.Error()))
+			continue
+		}
+		if _, err = etcd.CompareAndPut(kv.Key, string(raw), kv.ModRevision); err == etcd.ErrConflict {
+			continue
+		} else if err != nil {
+			return migrated, err
+		}
+		log("migrate %s %s to %s", k.Kind, strings.TrimPrefix(kv.Key, k.Prefix), storageVersion)
+		migrated++
+	}
+	return migrated, nil
+}
@@ -0,0 +1,92 @@
+package registry
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"minik8s/apiObject"
+	autoscalingv2 "minik8s/apiObject/autoscaling/v2"
+	"minik8s/util/httputil"
+	"testing"
+)
+
+func newHPAKind() *Kind {
+	return &Kind{
+		Kind:       "HorizontalPodAutoscaler",
+		Prefix:     "/api/v1/hpa/",
+		Namespaced: true,
+		New:        func() apiObject.Object { return &apiObject.HorizontalPodAutoscaler{} },
+		ApiVersion: "autoscaling/v1",
+		Versions: []*Version{{
+			ApiVersion: autoscalingv2.GroupVersion,
+			New:        func() apiObject.Convertible { return &autoscalingv2.HorizontalPodAutoscaler{} },
+		}},
+		StorageVersion: autoscalingv2.GroupVersion,
+	}
+}
+
+func TestStorageVersion(t *testing.T) {
+	kind := newHPAKind()
+	hpa := &apiObject.HorizontalPodAutoscaler{}
+	hpa.Metadata.Name = "hpa"
+	hpa.Spec.MaxReplicas = 3
+	hpa.Spec.Metrics.CPUUtilizationPercentage = 80
+
+	raw, err := kind.encodeStored(hpa)
+	assert.Nil(t, err)
+	stored := autoscalingv2.HorizontalPodAutoscaler{}
+	assert.Nil(t, json.Unmarshal(raw, &stored))
+	assert.Equal(t, autoscalingv2.GroupVersion, stored.ApiVersion)
+	assert.Equal(t, autoscalingv2.ResourceCPU, stored.Spec.Metrics[0].Resource.Name)
+
+	obj, err := kind.decodeStored(raw)
+	assert.Nil(t, err)
+	decoded := obj.(*apiObject.HorizontalPodAutoscaler)
+	assert.Equal(t, "autoscaling/v1", decoded.ApiVersion)
+	assert.Equal(t, 3, decoded.Spec.MaxReplicas)
+	assert.Equal(t, 80.0, decoded.Spec.Metrics.CPUUtilizationPercentage)
+
+	// an object stored before the storage version was set is read all the same
+	obj, err = kind.decodeStored([]byte(`{"ApiVersion":"v1","Kind":"HorizontalPodAutoscaler","Spec":{"MaxReplicas":2}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "autoscaling/v1", obj.GetApiVersion())
+	assert.Equal(t, 2, obj.(*apiObject.HorizontalPodAutoscaler).Spec.MaxReplicas)
+
+	served, err := kind.InVersion(kind.Versions[0]).ServedValue(string(raw))
+	assert.Nil(t, err)
+	assert.Contains(t, served, `"ApiVersion":"autoscaling/v2"`)
+	served, err = kind.ServedValue(string(raw))
+	assert.Nil(t, err)
+	assert.Contains(t, served, `"ApiVersion":"autoscaling/v1"`)
+}
+
+func TestCheckApiVersion(t *testing.T) {
+	kind := newHPAKind()
+	v2 := kind.InVersion(kind.Versions[0])
+
+	assert.Nil(t, kind.checkApiVersion([]byte(`{"ApiVersion":"autoscaling/v1"}`)))
+	assert.Nil(t, kind.checkApiVersion([]byte(`{}`)))
+	err := kind.checkApiVersion([]byte(`{"ApiVersion":"autoscaling/v2"}`))
+	assert.True(t, httputil.IsBadRequest(err))
+	assert.Contains(t, err.Error(), "/apis/autoscaling/v2/hpa/")
+
+	assert.Nil(t, v2.checkApiVersion([]byte(`{"ApiVersion":"autoscaling/v2"}`)))
+	assert.True(t, httputil.IsBadRequest(v2.checkApiVersion([]byte(`{"ApiVersion":"autoscaling/v1"}`))))
+
+	// a metric the hub cannot hold makes the object invalid
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+	hpa.Spec.Metrics = []autoscalingv2.MetricSpec{{Type: "Pods"}}
+	_, err = v2.toHub(hpa)
+	assert.True(t, httputil.IsInvalid(err))
+}
+
+func TestLookupVersion(t *testing.T) {
+	kind := newHPAKind()
+	Register(kind)
+	defer Unregister(kind.Kind)
+
+	served := LookupVersion("autoscaling", "v2", "hpa")
+	assert.NotNil(t, served)
+	assert.Equal(t, autoscalingv2.GroupVersion, served.servedApiVersion())
+	assert.Nil(t, LookupVersion("autoscaling", "v3", "hpa"))
+	assert.Equal(t, kind, LookupPrefix("/api/v1/hpa/"))
+}
@@ -0,0 +1,120 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"minik8s/apiserver/src/contentType"
+	"minik8s/apiserver/src/etcd"
+	"minik8s/entity"
+	"minik8s/util/httputil"
+	"net/http"
+	"strconv"
+)
+
+// IsWatch tells whether a list request asks for ?watch=true
+func IsWatch(c *gin.Context) bool {
+	watch, _ := strconv.ParseBool(c.Query("watch"))
+	return watch
+}
+
+// ServeWatch streams the etcd events under prefix whose keys pass matches,
+// starting after ?resourceVersion=N, as JSON lines of entity.WatchEvent.
+// If encode is not nil, it turns the stored objects into those served, e.g.
+// of another version. If selected is not nil, the watch only sees the
+// objects it selects.
+func ServeWatch(c *gin.Context, prefix string, matches func(key string) bool, encode func(value string) (string, error), selected func(value string) bool) {
+	var revision int64
+	if resourceVersion := c.Query("resourceVersion"); resourceVersion != "" {
+		var err error
+		if revision, err = strconv.ParseInt(resourceVersion, 10, 64); err != nil {
+			WriteError(c, httputil.NewBadRequest(fmt.Sprintf("invalid resourceVersion %s", resourceVersion)))
+			return
+		}
+	}
+
+	log("watch %s from resourceVersion %d", prefix, revision)
+	c.Header("Content-Type", contentType.Json)
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	encoder := json.NewEncoder(c.Writer)
+	for event := range etcd.Watch(c.Request.Context(), prefix, revision) {
+		if event.Type != etcd.Error && !matches(event.Key) {
+			continue
+		}
+		if encode != nil && event.Type != etcd.Error {
+			var err error
+			if event, err = encodeEvent(event, encode); err != nil {
+				log("cannot encode %s: %s", event.Key, err.Error())
+				continue
+			}
+		}
+		var ok bool
+		if event, ok = selectEvent(event, selected); !ok {
+			continue
+		}
+		if err := encoder.Encode(toWatchEvent(event)); err != nil {
+			return
+		}
+		c.Writer.Flush()
+		if event.Type == etcd.Error {
+			return
+		}
+	}
+}
+
+// encodeEvent encodes the objects of event, before and after the change
+func encodeEvent(event etcd.WatchEvent, encode func(value string) (string, error)) (etcd.WatchEvent, error) {
+	var err error
+	if event.Value, err = encode(event.Value); err != nil {
+		return event, err
+	}
+	if event.PrevValue != "" {
+		event.PrevValue, err = encode(event.PrevValue)
+	}
+	return event, err
+}
+
+// selectEvent turns the events of the objects selected before or after the
+// change into what the client of a filtered watch sees: an object that starts
+// being selected is ADDED, one that stops being selected is DELETED.
+func selectEvent(event etcd.WatchEvent, selected func(value string) bool) (etcd.WatchEvent, bool) {
+	if selected == nil || event.Type == etcd.Error {
+		return event, true
+	}
+	now := selected(event.Value)
+	if event.Type != etcd.Modified {
+		return event, now
+	}
+	before := selected(event.PrevValue)
+	switch {
+	case now && !before:
+		event.Type = etcd.Added
+	case !now && before:
+		event.Type = etcd.Deleted
+	}
+	return event, now || before
+}
+
+// toWatchEvent fills in the resourceVersion of the object, which is not
+// persisted in etcd.
+func toWatchEvent(event etcd.WatchEvent) *entity.WatchEvent {
+	if event.Type == etcd.Error {
+		msg, _ := json.Marshal(event.Err.Error())
+		return &entity.WatchEvent{Type: entity.WatchError, Object: msg}
+	}
+
+	object := json.RawMessage(event.Value)
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(object, &fields); err == nil {
+		if metadata, ok := fields["Metadata"].(map[string]interface{}); ok {
+			metadata["ResourceVersion"] = strconv.FormatInt(event.Revision, 10)
+			object, _ = json.Marshal(fields)
+		}
+	}
+	return &entity.WatchEvent{
+		Type:   entity.WatchEventType(event.Type),
+		Object: object,
+	}
+}
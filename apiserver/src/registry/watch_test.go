@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/etcd"
+	"minik8s/entity"
+	"minik8s/util/selectorutil"
+	"testing"
+)
+
+func TestToWatchEvent(t *testing.T) {
+	rs := apiObject.ReplicaSet{}
+	rs.Metadata.Name = "example"
+	raw, _ := json.Marshal(rs)
+
+	event := toWatchEvent(etcd.WatchEvent{
+		Type:     etcd.Modified,
+		Key:      "/api/v1/replicaSets/default/example",
+		Value:    string(raw),
+		Revision: 42,
+	})
+	assert.Equal(t, entity.WatchModified, event.Type)
+
+	got := apiObject.ReplicaSet{}
+	assert.Nil(t, json.Unmarshal(event.Object, &got))
+	assert.Equal(t, "example", got.Metadata.Name)
+	assert.Equal(t, "42", got.Metadata.ResourceVersion)
+}
+
+func TestSelectEvent(t *testing.T) {
+	opts := ListOptions{LabelSelector: selectorutil.FromSet(map[string]string{"app": "foo"})}
+	selected := opts.Selected(func() interface{} { return &apiObject.ReplicaSet{} })
+	encode := func(app string) string {
+		rs := apiObject.ReplicaSet{}
+		rs.Metadata.Labels = apiObject.Labels{"app": app}
+		raw, _ := json.Marshal(rs)
+		return string(raw)
+	}
+
+	event, ok := selectEvent(etcd.WatchEvent{Type: etcd.Added, Value: encode("bar")}, selected)
+	assert.False(t, ok)
+
+	event, ok = selectEvent(etcd.WatchEvent{Type: etcd.Modified, Value: encode("foo"), PrevValue: encode("bar")}, selected)
+	assert.True(t, ok)
+	assert.Equal(t, etcd.Added, event.Type)
+
+	event, ok = selectEvent(etcd.WatchEvent{Type: etcd.Modified, Value: encode("bar"), PrevValue: encode("foo")}, selected)
+	assert.True(t, ok)
+	assert.Equal(t, etcd.Deleted, event.Type)
+
+	event, ok = selectEvent(etcd.WatchEvent{Type: etcd.Deleted, Value: encode("foo")}, selected)
+	assert.True(t, ok)
+	assert.Equal(t, etcd.Deleted, event.Type)
+
+	_, ok = selectEvent(etcd.WatchEvent{Type: etcd.Added, Value: encode("bar")}, nil)
+	assert.True(t, ok)
+}
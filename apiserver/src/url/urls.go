@@ -1,13 +1,20 @@
 package url
 
-import "minik8s/global"
-
+// The api-server is only served over HttpsScheme, HttpScheme is for the rest,
+// like the gpu file servers.
 const (
-	HttpScheme = "http://"
-	Hostname   = global.Host
-	Port       = ":8080"
-	Prefix     = HttpScheme + Hostname + Port
+	HttpScheme  = "http://"
+	HttpsScheme = "https://"
+)
 
+// Hostname and Prefix tell where the api-server is, configutil.Load points
+// them at the api-server of the config.
+var (
+	Hostname = "localhost"
+	Prefix   = HttpsScheme + Hostname + ":8080"
+)
+
+const (
 	PodURL                             = "/api/v1/pods/"
 	PodURLWithSpecifiedNode            = "/api/v1/pods/nodes/:node"
 	PodDescriptionURL                  = "/api/v1/pods/description/"
@@ -24,19 +31,20 @@ const (
 	ReplicaSetURL                        = "/api/v1/replicaSets/"
 	ReplicaSetURLWithSpecifiedName       = "/api/v1/replicaSets/:namespace/:name"
 	ReplicaSetStatusURLWithSpecifiedName = "/api/v1/replicaSets/status/:namespace/:name"
+	ReplicaSetScaleURLWithSpecifiedName  = "/api/v1/replicaSets/:namespace/:name/scale"
+
+	DeploymentURL                  = "/api/v1/deployments/"
+	DeploymentURLWithSpecifiedName = "/api/v1/deployments/:namespace/:name"
 
 	HPAURL                        = "/api/v1/hpa/"
 	HPAURLWithSpecifiedName       = "/api/v1/hpa/:namespace/:name"
+	HPAV2URL                      = "/apis/autoscaling/v2/hpa/"
 	HPAStatusURLWithSpecifiedName = "/api/v1/hpa/status/:namespace/:name"
 	AutoscaleURL                  = "/autoscaling/v1/"
 	AutoscaleURLWithSpecifiedName = "/autoscaling/v1/:namespace/:name"
 
-	PodIpURL      = "/generator/ip/pod"
-	ServiceIpURL  = "/generator/ip/service"
-	PodIpBase     = "10.44.0.1"
-	ServiceIpBase = "10.44.127.1"
-	MaskStr       = "/16"
-	Mask          = 16
+	PodIpURL     = "/generator/ip/pod"
+	ServiceIpURL = "/generator/ip/service"
 
 	ServiceURL                  = "/api/v1/service/"
 	ServiceURLWithSpecifiedName = "/api/v1/service/:namespace/:name"
@@ -49,16 +57,61 @@ const (
 	GpuURLWithSpecifiedName       = "/api/v1/gpu/:namespace/:name"
 	GpuStatusURLWithSpecifiedName = "/api/v1/gpu/status/:namespace/:name"
 
-	FuncURL                      = "/api/v1/func/"
-	FuncURLWithSpecifiedName     = "/api/v1/func/:name"
-	FuncPodsURLWithSpecifiedName = "/api/v1/func/:name/pods/"
+	FuncURL                  = "/api/v1/func/"
+	FuncURLWithSpecifiedName = "/api/v1/func/:name"
 
 	WorkflowURL                        = "/api/v1/workflow/"
 	WorkflowURLWithSpecifiedName       = "/api/v1/workflow/:namespace/:name"
 	WorkflowResultURLWithSpecifiedName = "/api/v1/workflow/result/:namespace/:name"
 
+	NamespaceURL                  = "/api/v1/namespaces/"
+	NamespaceURLWithSpecifiedName = "/api/v1/namespaces/:name"
+
+	MutatingWebhookURL                    = "/api/v1/mutatingwebhookconfigurations/"
+	MutatingWebhookURLWithSpecifiedName   = "/api/v1/mutatingwebhookconfigurations/:name"
+	ValidatingWebhookURL                  = "/api/v1/validatingwebhookconfigurations/"
+	ValidatingWebhookURLWithSpecifiedName = "/api/v1/validatingwebhookconfigurations/:name"
+
+	RoleURL                                = "/api/v1/roles/"
+	RoleURLWithSpecifiedName               = "/api/v1/roles/:namespace/:name"
+	ClusterRoleURL                         = "/api/v1/clusterroles/"
+	ClusterRoleURLWithSpecifiedName        = "/api/v1/clusterroles/:name"
+	RoleBindingURL                         = "/api/v1/rolebindings/"
+	RoleBindingURLWithSpecifiedName        = "/api/v1/rolebindings/:namespace/:name"
+	ClusterRoleBindingURL                  = "/api/v1/clusterrolebindings/"
+	ClusterRoleBindingURLWithSpecifiedName = "/api/v1/clusterrolebindings/:name"
+
+	EventURL                  = "/api/v1/events/"
+	EventURLWithSpecifiedName = "/api/v1/events/:namespace/:name"
+
+	LeaseURL                  = "/api/v1/leases/"
+	LeaseURLWithSpecifiedName = "/api/v1/leases/:name"
+
+	CSRURL                  = "/api/v1/certificatesigningrequests/"
+	CSRURLWithSpecifiedName = "/api/v1/certificatesigningrequests/:name"
+	// CSRApprovalURLWithSpecifiedName approves or denies a request, it is not
+	// a resource url so that only the admin may do it unless a role allows it
+	CSRApprovalURLWithSpecifiedName = "/certificates/approval/:name"
+
+	CRDURL                  = "/api/v1/crd/"
+	CRDURLWithSpecifiedName = "/api/v1/crd/:name"
+
+	// CustomResourceURL is followed by group/version/plural/[namespace/]name
+	CustomResourceURL                  = "/apis/"
+	CustomResourceURLWithSpecifiedPath = "/apis/:group/:version/:plural/*path"
+
+	// ObjectMetaURL lists the metadata of all objects, for the garbage collector
+	ObjectMetaURL = "/api/v1/objectmeta/"
+
 	ResetURL = "/reset"
 
+	// BackupURL takes a backup of every key by GET, and restores one by POST
+	BackupURL = "/backup"
+
+	// MigrateURL rewrites the objects stored in another version than the
+	// storage version of their kind, by POST
+	MigrateURL = "/migrate"
+
 	DNSIp            = "10.44.0.9"
 	DNSDirPath       = "/etc/kube/dns"
 	DNSFileName      = "Corefile"
@@ -66,3 +119,12 @@ const (
 	NginxDirPath     = "/etc/nginx"
 	NginxFileName    = "nginx.conf"
 )
+
+const (
+	// ResourceVersionHeader carries the etcd revision a list response is
+	// consistent with, a following watch should start from it.
+	ResourceVersionHeader = "X-Resource-Version"
+	// ContinueHeader carries the token of the next page of a list response
+	// asked for with ?limit=N, it is absent on the last page.
+	ContinueHeader = "X-Continue"
+)
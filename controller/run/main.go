@@ -1,10 +1,30 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"minik8s/controller/src/controller"
+	"minik8s/listwatch"
+	"minik8s/util/configutil"
+	"minik8s/util/httputil"
+	"minik8s/util/leaderelection"
+	"os"
 )
 
 func main() {
-	cm := controller.NewControllerManager()
+	listwatch.SetIdentity("controller-manager")
+	leaderElection := leaderelection.DefaultConfig("kube-controller-manager")
+	leaderElection.AddFlags(flag.CommandLine)
+	configutil.AddFlags(flag.CommandLine)
+	flag.Parse()
+	if err := configutil.Load(flag.CommandLine); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(-1)
+	}
+	if err := httputil.LoadCredentials(httputil.CredentialsFile("kube-controller-manager")); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(-1)
+	}
+	cm := controller.NewControllerManager(leaderElection)
 	cm.Start()
 }
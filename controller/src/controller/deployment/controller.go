@@ -0,0 +1,254 @@
+package deployment
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/contentType"
+	"minik8s/apiserver/src/url"
+	"minik8s/entity"
+	"minik8s/listwatch"
+	"minik8s/util/apiutil"
+	"minik8s/util/configutil"
+	"minik8s/util/eventutil"
+	"minik8s/util/httputil"
+	"minik8s/util/logger"
+	"minik8s/util/topicutil"
+	"minik8s/util/wait"
+	netURL "net/url"
+	"path"
+	"strconv"
+	"sync"
+)
+
+var log = logger.Log("Deployment")
+
+// Controller rolls out the deployments: it runs the pod template of each in
+// a replicaSet of its own, and scales the replicaSets of the former templates
+// down as that one scales up. It is synced by the updates of the deployments
+// and the statuses of their replicaSets.
+type Controller interface {
+	Run()
+}
+
+type controller struct {
+	// lock syncs one deployment at a time
+	lock     sync.Mutex
+	recorder eventutil.Recorder
+}
+
+func (c *controller) Run() {
+	go listwatch.Watch(topicutil.DeploymentUpdateTopic(), c.parseDeploymentUpdate)
+	// the cache manager of the controller manager watches the statuses too
+	go listwatch.WatchAs(topicutil.ReplicaSetStatusTopic(), "deployment-controller", c.parseReplicaSetStatus)
+	// all the deployments are synced now and then, in case an update was missed
+	resyncPeriod := configutil.Current().SyncPeriods.DeploymentResync
+	wait.Period(resyncPeriod, resyncPeriod, c.syncAll)
+}
+
+func (c *controller) parseDeploymentUpdate(msg *redis.Message) {
+	deploymentUpdate := &entity.DeploymentUpdate{}
+	if err := json.Unmarshal([]byte(msg.Payload), deploymentUpdate); err != nil {
+		log(err.Error())
+		return
+	}
+	// The replicaSets of a deleted deployment go with it, by the garbage
+	// collector
+	if deploymentUpdate.Action != entity.DeleteAction {
+		c.sync(deploymentUpdate.Target.Namespace(), deploymentUpdate.Target.Name())
+	}
+}
+
+func (c *controller) parseReplicaSetStatus(msg *redis.Message) {
+	status := &entity.ReplicaSetStatus{}
+	if err := json.Unmarshal([]byte(msg.Payload), status); err != nil {
+		log(err.Error())
+		return
+	}
+	if name, ok := status.Labels[apiObject.DeploymentLabel]; ok {
+		c.sync(status.Namespace, name)
+	}
+}
+
+func (c *controller) syncAll() {
+	var deployments []apiObject.Deployment
+	if _, err := apiutil.List(url.Prefix+url.DeploymentURL, &deployments); err != nil {
+		logger.Error(err.Error())
+		return
+	}
+	for _, d := range deployments {
+		c.sync(d.Namespace(), d.Name())
+	}
+}
+
+// sync takes one step of the rollout of the deployment namespace/name, and
+// updates its status.
+func (c *controller) sync(namespace, name string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	d := &apiObject.Deployment{}
+	if err := httputil.GetAndUnmarshal(url.Prefix+path.Join(url.DeploymentURL, namespace, name), d); err != nil {
+		if !httputil.IsNotFound(err) {
+			logger.Error(err.Error())
+		}
+		return
+	}
+	if d.Metadata.DeletionTimestamp != nil {
+		return
+	}
+	if err := c.rollout(d); err != nil {
+		logger.Error(fmt.Sprintf("deployment %s/%s: %s", namespace, name, err.Error()))
+	}
+}
+
+func (c *controller) rollout(d *apiObject.Deployment) error {
+	rss, err := listReplicaSets(d)
+	if err != nil {
+		return err
+	}
+	newRS, olds := splitReplicaSets(rss, d.TemplateHash())
+
+	// A paused deployment keeps its replicaSets as they are
+	if !d.Spec.Paused {
+		if newRS == nil {
+			rs := newReplicaSet(d, maxRevision(olds)+1)
+			log("roll out %s/%s to %s", d.Namespace(), d.Name(), rs.Name())
+			if err = createReplicaSet(rs); err != nil {
+				return err
+			}
+			newRS = &replicaSet{ReplicaSet: rs}
+		} else if revision := maxRevision(olds) + 1; revisionOf(newRS.ReplicaSet) < revision {
+			// Rolled back to a former template, which is the latest revision now
+			log("roll %s/%s back to %s", d.Namespace(), d.Name(), newRS.Name())
+			if err = setRevision(newRS.ReplicaSet, revision); err != nil {
+				return err
+			}
+		}
+
+		newReplicas, oldReplicas, err := plan(d, newRS, olds)
+		if err != nil {
+			return err
+		}
+		if err = c.scaleReplicaSet(d, newRS.ReplicaSet, newReplicas); err != nil {
+			return err
+		}
+		for i, old := range olds {
+			if err = c.scaleReplicaSet(d, old.ReplicaSet, oldReplicas[i]); err != nil {
+				return err
+			}
+		}
+		for _, old := range historyToDelete(d, olds) {
+			if err = deleteReplicaSet(old.ReplicaSet); err != nil {
+				return err
+			}
+		}
+	}
+
+	if status := statusOf(d, newRS, olds); status != d.Status {
+		return updateStatus(d, status)
+	}
+	return nil
+}
+
+// listReplicaSets gets the replicaSets the deployment owns, along with how
+// many of their pods are ready.
+func listReplicaSets(d *apiObject.Deployment) ([]*replicaSet, error) {
+	query := netURL.Values{}
+	query.Set("labelSelector", apiObject.DeploymentLabel+"="+d.Name())
+	query.Set("fieldSelector", "metadata.namespace="+d.Namespace())
+	var statuses []entity.ReplicaSetStatus
+	if _, err := apiutil.List(url.Prefix+url.ReplicaSetURL+"?"+query.Encode(), &statuses); err != nil {
+		return nil, err
+	}
+
+	var rss []*replicaSet
+	for _, status := range statuses {
+		rs := &apiObject.ReplicaSet{}
+		if err := httputil.GetAndUnmarshal(url.Prefix+path.Join(url.ReplicaSetURL, status.Namespace, status.Name), rs); err != nil {
+			if httputil.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if ownedBy(rs, d) {
+			rss = append(rss, &replicaSet{ReplicaSet: rs, ready: status.NumReady})
+		}
+	}
+	return rss, nil
+}
+
+func ownedBy(rs *apiObject.ReplicaSet, d *apiObject.Deployment) bool {
+	for _, ref := range rs.Metadata.OwnerReferences {
+		if ref.UID == d.UID() {
+			return true
+		}
+	}
+	return false
+}
+
+func createReplicaSet(rs *apiObject.ReplicaSet) error {
+	resp, err := httputil.PostJson(url.Prefix+url.ReplicaSetURL, rs)
+	if err == nil {
+		_, err = httputil.ReadResponse(resp)
+	}
+	return err
+}
+
+func (c *controller) scaleReplicaSet(d *apiObject.Deployment, rs *apiObject.ReplicaSet, replicas int) error {
+	if rs.Replicas() == replicas {
+		return nil
+	}
+	log("scale %s/%s from %d to %d", rs.Namespace(), rs.Name(), rs.Replicas(), replicas)
+	patch := fmt.Sprintf(`{"Spec":{"Replicas":%d}}`, replicas)
+	if _, err := httputil.Patch(replicaSetURL(rs), contentType.MergePatch, []byte(patch)); err != nil {
+		return err
+	}
+	direction := "up"
+	if replicas < rs.Replicas() {
+		direction = "down"
+	}
+	c.recorder.Eventf(apiObject.ReferenceTo("Deployment", d), apiObject.EventTypeNormal, "ScalingReplicaSet",
+		"Scaled %s replica set %s to %d", direction, rs.Name(), replicas)
+	rs.SetReplicas(replicas)
+	return nil
+}
+
+func setRevision(rs *apiObject.ReplicaSet, revision int) error {
+	patch, _ := json.Marshal(map[string]interface{}{
+		"Metadata": map[string]interface{}{
+			"Annotations": map[string]string{apiObject.RevisionAnnotation: strconv.Itoa(revision)},
+		},
+	})
+	if _, err := httputil.Patch(replicaSetURL(rs), contentType.MergePatch, patch); err != nil {
+		return err
+	}
+	if rs.Metadata.Annotations == nil {
+		rs.Metadata.Annotations = make(apiObject.Annotations)
+	}
+	rs.Metadata.Annotations[apiObject.RevisionAnnotation] = strconv.Itoa(revision)
+	return nil
+}
+
+func deleteReplicaSet(rs *apiObject.ReplicaSet) error {
+	log("delete %s/%s, beyond the revision history limit", rs.Namespace(), rs.Name())
+	if _, err := httputil.Delete(replicaSetURL(rs)); err != nil && !httputil.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func updateStatus(d *apiObject.Deployment, status apiObject.DeploymentStatus) error {
+	patch, _ := json.Marshal(map[string]interface{}{"Status": status})
+	_, err := httputil.Patch(url.Prefix+path.Join(url.DeploymentURL, d.Namespace(), d.Name()), contentType.MergePatch, patch)
+	return err
+}
+
+func replicaSetURL(rs *apiObject.ReplicaSet) string {
+	return url.Prefix + path.Join(url.ReplicaSetURL, rs.Namespace(), rs.Name())
+}
+
+func NewController() Controller {
+	return &controller{recorder: eventutil.NewRecorder("deployment-controller")}
+}
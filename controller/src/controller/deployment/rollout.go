@@ -0,0 +1,185 @@
+package deployment
+
+import (
+	"minik8s/apiObject"
+	"sort"
+	"strconv"
+)
+
+// replicaSet is a replicaSet of a deployment, along with how many of its pods
+// are ready as its status tells.
+type replicaSet struct {
+	*apiObject.ReplicaSet
+	ready int
+}
+
+// available is how many of the pods the replicaSet keeps are ready. The
+// status lags behind the spec, a replicaSet scaled down may not have
+// deleted its pods yet.
+func (rs *replicaSet) available() int {
+	if rs.ready < rs.Replicas() {
+		return rs.ready
+	}
+	return rs.Replicas()
+}
+
+// revisionOf reads the revision annotation of rs, 0 if it has none
+func revisionOf(rs *apiObject.ReplicaSet) int {
+	revision, _ := strconv.Atoi(rs.Metadata.Annotations[apiObject.RevisionAnnotation])
+	return revision
+}
+
+func maxRevision(rss []*replicaSet) int {
+	max := 0
+	for _, rs := range rss {
+		if revision := revisionOf(rs.ReplicaSet); revision > max {
+			max = revision
+		}
+	}
+	return max
+}
+
+// splitReplicaSets picks the replicaSet of the template hash out of rss, and
+// returns the others from the oldest revision to the latest.
+func splitReplicaSets(rss []*replicaSet, hash string) (newRS *replicaSet, olds []*replicaSet) {
+	for _, rs := range rss {
+		if rs.Labels()[apiObject.PodTemplateHashLabel] == hash {
+			newRS = rs
+		} else {
+			olds = append(olds, rs)
+		}
+	}
+	sort.SliceStable(olds, func(i, j int) bool {
+		return revisionOf(olds[i].ReplicaSet) < revisionOf(olds[j].ReplicaSet)
+	})
+	return
+}
+
+// newReplicaSet makes the replicaSet that runs the current template of d,
+// with no replicas yet. It is named and labelled after the template hash.
+func newReplicaSet(d *apiObject.Deployment, revision int) *apiObject.ReplicaSet {
+	hash := d.TemplateHash()
+	labels := make(apiObject.Labels)
+	for key, value := range d.Spec.Template.Metadata.Labels {
+		labels[key] = value
+	}
+	labels[apiObject.DeploymentLabel] = d.Name()
+	labels[apiObject.PodTemplateHashLabel] = hash
+
+	return &apiObject.ReplicaSet{
+		Base: apiObject.Base{
+			ApiVersion: "v1",
+			Kind:       "ReplicaSet",
+			Metadata: apiObject.Metadata{
+				Name:            d.Name() + "-" + hash,
+				Namespace:       d.Namespace(),
+				Labels:          labels,
+				Annotations:     apiObject.Annotations{apiObject.RevisionAnnotation: strconv.Itoa(revision)},
+				OwnerReferences: []apiObject.OwnerReference{d.OwnerReference()},
+			},
+		},
+		Spec: apiObject.ReplicaSetSpec{
+			Replicas: 0,
+			Selector: d.Spec.Selector,
+			Template: d.Spec.Template,
+		},
+	}
+}
+
+// plan is one step of the rollout of d: the replicas the new replicaSet and
+// each of the old ones are to be scaled to.
+func plan(d *apiObject.Deployment, newRS *replicaSet, olds []*replicaSet) (newReplicas int, oldReplicas []int, err error) {
+	if d.Spec.Strategy.Type == apiObject.RecreateDeploymentStrategyType {
+		newReplicas, oldReplicas = planRecreate(d, newRS, olds)
+		return
+	}
+	return planRollingUpdate(d, newRS, olds)
+}
+
+// planRecreate scales the old replicaSets down to nothing, and the new one
+// up only once all the old pods are gone.
+func planRecreate(d *apiObject.Deployment, newRS *replicaSet, olds []*replicaSet) (newReplicas int, oldReplicas []int) {
+	oldReplicas = make([]int, len(olds))
+	for _, old := range olds {
+		if old.Replicas() > 0 || old.ready > 0 {
+			return newRS.Replicas(), oldReplicas
+		}
+	}
+	return d.Replicas(), oldReplicas
+}
+
+// planRollingUpdate scales the new replicaSet up as long as there are no more
+// than maxSurge pods above the replicas, and the old ones down as long as no
+// more than maxUnavailable of the replicas are unavailable. The pods that are
+// not ready count for nothing, so the old ones are scaled down first.
+func planRollingUpdate(d *apiObject.Deployment, newRS *replicaSet, olds []*replicaSet) (newReplicas int, oldReplicas []int, err error) {
+	maxSurge, maxUnavailable, err := d.MaxSurgeAndUnavailable()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	total := newRS.Replicas()
+	available := newRS.available()
+	for _, old := range olds {
+		total += old.Replicas()
+		available += old.available()
+	}
+
+	newReplicas = newRS.Replicas()
+	if newReplicas > d.Replicas() {
+		newReplicas = d.Replicas()
+	} else if up := d.Replicas() + maxSurge - total; up > 0 {
+		if up > d.Replicas()-newReplicas {
+			up = d.Replicas() - newReplicas
+		}
+		newReplicas += up
+	}
+
+	canScaleDown := available - (d.Replicas() - maxUnavailable)
+	oldReplicas = make([]int, len(olds))
+	for i, old := range olds {
+		replicas := old.available()
+		if canScaleDown > 0 {
+			down := canScaleDown
+			if down > replicas {
+				down = replicas
+			}
+			replicas -= down
+			canScaleDown -= down
+		}
+		oldReplicas[i] = replicas
+	}
+	return newReplicas, oldReplicas, nil
+}
+
+// historyToDelete returns the old replicaSets, scaled down to nothing, that
+// are beyond the revision history limit of d, the oldest ones.
+func historyToDelete(d *apiObject.Deployment, olds []*replicaSet) []*replicaSet {
+	var idle []*replicaSet
+	for _, old := range olds {
+		if old.Replicas() == 0 && old.ready == 0 {
+			idle = append(idle, old)
+		}
+	}
+	if excess := len(idle) - d.RevisionHistoryLimit(); excess > 0 {
+		return idle[:excess]
+	}
+	return nil
+}
+
+// statusOf sums up the replicaSets of d, newRS being nil if the current
+// template has not been rolled out yet.
+func statusOf(d *apiObject.Deployment, newRS *replicaSet, olds []*replicaSet) apiObject.DeploymentStatus {
+	status := apiObject.DeploymentStatus{TemplateHash: d.TemplateHash()}
+	if newRS != nil {
+		status.Revision = revisionOf(newRS.ReplicaSet)
+		status.Replicas = newRS.Replicas()
+		status.UpdatedReplicas = newRS.Replicas()
+		status.AvailableReplicas = newRS.available()
+	}
+	for _, old := range olds {
+		status.Replicas += old.Replicas()
+		status.AvailableReplicas += old.available()
+	}
+	return status
+}
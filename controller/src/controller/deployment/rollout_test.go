@@ -0,0 +1,109 @@
+package deployment
+
+import (
+	"github.com/stretchr/testify/assert"
+	"minik8s/apiObject"
+	"strconv"
+	"testing"
+)
+
+func deployment(replicas int, maxSurge, maxUnavailable string) *apiObject.Deployment {
+	d := &apiObject.Deployment{}
+	d.Metadata.Name = "nginx"
+	d.Spec.Replicas = replicas
+	d.Spec.Strategy.Type = apiObject.RollingUpdateDeploymentStrategyType
+	d.Spec.Strategy.RollingUpdate = apiObject.RollingUpdateDeployment{MaxSurge: maxSurge, MaxUnavailable: maxUnavailable}
+	return d
+}
+
+func rs(revision, replicas, ready int) *replicaSet {
+	r := &apiObject.ReplicaSet{}
+	r.Metadata.Annotations = apiObject.Annotations{apiObject.RevisionAnnotation: strconv.Itoa(revision)}
+	r.Spec.Replicas = replicas
+	return &replicaSet{ReplicaSet: r, ready: ready}
+}
+
+func TestMaxSurgeAndUnavailable(t *testing.T) {
+	surge, unavailable, err := deployment(10, "", "").MaxSurgeAndUnavailable()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, surge)
+	assert.Equal(t, 2, unavailable)
+
+	surge, unavailable, err = deployment(3, "0", "0%").MaxSurgeAndUnavailable()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, surge)
+	assert.Equal(t, 1, unavailable)
+
+	_, _, err = deployment(3, "one", "").MaxSurgeAndUnavailable()
+	assert.NotNil(t, err)
+}
+
+func TestPlanRollingUpdate(t *testing.T) {
+	d := deployment(3, "1", "0")
+
+	// The new replicaSet surges first
+	newReplicas, oldReplicas, err := plan(d, rs(2, 0, 0), []*replicaSet{rs(1, 3, 3)})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, newReplicas)
+	assert.Equal(t, []int{3}, oldReplicas)
+
+	// The old one is scaled down once the new pod is ready
+	newReplicas, oldReplicas, _ = plan(d, rs(2, 1, 1), []*replicaSet{rs(1, 3, 3)})
+	assert.Equal(t, 1, newReplicas)
+	assert.Equal(t, []int{2}, oldReplicas)
+
+	newReplicas, oldReplicas, _ = plan(d, rs(2, 1, 1), []*replicaSet{rs(1, 2, 2)})
+	assert.Equal(t, 2, newReplicas)
+	assert.Equal(t, []int{2}, oldReplicas)
+
+	newReplicas, oldReplicas, _ = plan(d, rs(2, 3, 3), []*replicaSet{rs(1, 1, 1)})
+	assert.Equal(t, 3, newReplicas)
+	assert.Equal(t, []int{0}, oldReplicas)
+
+	// The pods that are not ready go first, the oldest replicaSets first
+	d = deployment(4, "0", "1")
+	newReplicas, oldReplicas, _ = plan(d, rs(3, 0, 0), []*replicaSet{rs(1, 2, 2), rs(2, 2, 1)})
+	assert.Equal(t, 0, newReplicas)
+	assert.Equal(t, []int{2, 1}, oldReplicas)
+	newReplicas, oldReplicas, _ = plan(d, rs(3, 0, 0), []*replicaSet{rs(1, 2, 2), rs(2, 1, 1)})
+	assert.Equal(t, 1, newReplicas)
+	assert.Equal(t, []int{2, 1}, oldReplicas)
+}
+
+func TestPlanRecreate(t *testing.T) {
+	d := deployment(3, "", "")
+	d.Spec.Strategy.Type = apiObject.RecreateDeploymentStrategyType
+
+	newReplicas, oldReplicas, _ := plan(d, rs(2, 0, 0), []*replicaSet{rs(1, 3, 3)})
+	assert.Equal(t, 0, newReplicas)
+	assert.Equal(t, []int{0}, oldReplicas)
+
+	// The old pods are still there
+	newReplicas, _, _ = plan(d, rs(2, 0, 0), []*replicaSet{rs(1, 0, 2)})
+	assert.Equal(t, 0, newReplicas)
+
+	newReplicas, _, _ = plan(d, rs(2, 0, 0), []*replicaSet{rs(1, 0, 0)})
+	assert.Equal(t, 3, newReplicas)
+}
+
+func TestSplitReplicaSets(t *testing.T) {
+	d := deployment(1, "", "")
+	current := &replicaSet{ReplicaSet: newReplicaSet(d, 3)}
+	older, old := rs(1, 0, 0), rs(2, 1, 1)
+
+	newRS, olds := splitReplicaSets([]*replicaSet{old, current, older}, d.TemplateHash())
+	assert.Equal(t, current, newRS)
+	assert.Equal(t, []*replicaSet{older, old}, olds)
+	assert.Equal(t, 3, maxRevision([]*replicaSet{old, current, older}))
+	assert.Equal(t, "nginx", current.Labels()[apiObject.DeploymentLabel])
+}
+
+func TestHistoryToDelete(t *testing.T) {
+	limit := 1
+	d := deployment(1, "", "")
+	d.Spec.RevisionHistoryLimit = &limit
+
+	olds := []*replicaSet{rs(1, 0, 0), rs(2, 0, 0), rs(3, 1, 1)}
+	assert.Equal(t, olds[:1], historyToDelete(d, olds))
+	assert.Nil(t, historyToDelete(deployment(1, "", ""), olds))
+}
@@ -0,0 +1,78 @@
+package gc
+
+import (
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/url"
+	"minik8s/entity"
+	"minik8s/kubelet/src/runtime/runtime"
+	"minik8s/util/httputil"
+	"minik8s/util/logger"
+	"path"
+)
+
+// legacyOwners finds the owners of the pods created before the owner
+// references were recorded, the way the controllers used to find them: a
+// replicaSet by the uid label of its pods, a gpu job by the name of its pod.
+type legacyOwners struct {
+	replicaSets map[string]*entity.ObjectMeta
+	gpuJobs     map[string]*entity.ObjectMeta
+}
+
+func newLegacyOwners(metas []*entity.ObjectMeta) *legacyOwners {
+	o := &legacyOwners{
+		replicaSets: make(map[string]*entity.ObjectMeta),
+		gpuJobs:     make(map[string]*entity.ObjectMeta),
+	}
+	for _, meta := range metas {
+		switch meta.Kind {
+		case "ReplicaSet":
+			o.replicaSets[meta.Metadata.UID] = meta
+		case "GpuJob":
+			o.gpuJobs[path.Join(meta.Metadata.Namespace, meta.Metadata.Name)] = meta
+		}
+	}
+	return o
+}
+
+// ownerOf returns the owner pod should have, or nil if it has its owner
+// references already or has no owner. An owner being deleted adopts nothing,
+// its dependents may have just been orphaned.
+func (o *legacyOwners) ownerOf(pod *entity.ObjectMeta) *entity.ObjectMeta {
+	if pod.Kind != "Pod" || len(pod.Metadata.OwnerReferences) > 0 {
+		return nil
+	}
+	owner := o.replicaSets[pod.Metadata.Labels[runtime.KubernetesReplicaSetUIDLabel]]
+	if owner == nil {
+		owner = o.gpuJobs[path.Join(pod.Metadata.Namespace, pod.Metadata.Name)]
+	}
+	if owner == nil || owner.Metadata.DeletionTimestamp != nil {
+		return nil
+	}
+	return owner
+}
+
+// adopt backfills the owner references of the legacy pods, so that the
+// garbage collector deletes them along with their owners
+func (c *controller) adopt(metas []*entity.ObjectMeta) {
+	owners := newLegacyOwners(metas)
+	for _, meta := range metas {
+		owner := owners.ownerOf(meta)
+		if owner == nil {
+			continue
+		}
+		base := apiObject.Base{}
+		if err := httputil.GetAndUnmarshal(url.Prefix+owner.SelfLink, &base); err != nil {
+			logger.Error(err.Error())
+			continue
+		}
+		log("adopt %s %s by %s %s", meta.Kind, meta.SelfLink, owner.Kind, owner.SelfLink)
+		err := updateMetadata(meta, func(metadata *apiObject.Metadata) {
+			if len(metadata.OwnerReferences) == 0 {
+				metadata.OwnerReferences = []apiObject.OwnerReference{base.OwnerReference()}
+			}
+		})
+		if err != nil {
+			logger.Error(err.Error())
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package gc
+
+import (
+	"github.com/stretchr/testify/assert"
+	"minik8s/apiObject"
+	"minik8s/entity"
+	"minik8s/kubelet/src/runtime/runtime"
+	"testing"
+	"time"
+)
+
+func TestLegacyOwners(t *testing.T) {
+	rs := &entity.ObjectMeta{Kind: "ReplicaSet", Metadata: apiObject.Metadata{UID: "rs"}}
+	job := &entity.ObjectMeta{Kind: "GpuJob", Metadata: apiObject.Metadata{UID: "job", Namespace: "default", Name: "train"}}
+	now := time.Now()
+	deleting := &entity.ObjectMeta{Kind: "ReplicaSet", Metadata: apiObject.Metadata{UID: "deleting", DeletionTimestamp: &now}}
+	o := newLegacyOwners([]*entity.ObjectMeta{rs, job, deleting})
+
+	pod := func(namespace, name, rsUID string, owners ...string) *entity.ObjectMeta {
+		m := meta(name, owners...)
+		m.Kind = "Pod"
+		m.Metadata.Namespace, m.Metadata.Name = namespace, name
+		if rsUID != "" {
+			m.Metadata.Labels = apiObject.Labels{runtime.KubernetesReplicaSetUIDLabel: rsUID}
+		}
+		return m
+	}
+
+	assert.Equal(t, rs, o.ownerOf(pod("default", "web-1", "rs")))
+	assert.Equal(t, job, o.ownerOf(pod("default", "train", "")))
+	assert.Nil(t, o.ownerOf(pod("default", "web-2", "rs", "rs")))
+	assert.Nil(t, o.ownerOf(pod("default", "web-3", "deleting")))
+	assert.Nil(t, o.ownerOf(pod("default", "web-4", "gone")))
+	assert.Nil(t, o.ownerOf(pod("other", "train", "")))
+	assert.Nil(t, o.ownerOf(job))
+}
@@ -0,0 +1,181 @@
+package gc
+
+import (
+	"encoding/json"
+	"fmt"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/url"
+	"minik8s/entity"
+	"minik8s/util/configutil"
+	"minik8s/util/httputil"
+	"minik8s/util/logger"
+	"minik8s/util/wait"
+)
+
+var log = logger.Log("Garbage Collector")
+
+// Controller deletes the objects whose owners are gone, and runs the
+// finalizers of the Foreground and Orphan deletions.
+type Controller interface {
+	Run()
+}
+
+type controller struct{}
+
+func (c *controller) Run() {
+	collectPeriod := configutil.Current().SyncPeriods.GarbageCollect
+	wait.Period(collectPeriod, collectPeriod, c.collect)
+}
+
+func (c *controller) collect() {
+	metas, err := listObjectMetas()
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+	c.adopt(metas)
+
+	g := newGraph(metas)
+	for _, meta := range metas {
+		if meta.Metadata.DeletionTimestamp != nil {
+			c.finalize(g, meta)
+		}
+	}
+
+	var orphans []*entity.ObjectMeta
+	for _, meta := range metas {
+		if len(meta.Metadata.OwnerReferences) > 0 && !g.hasOwner(meta) {
+			orphans = append(orphans, meta)
+		}
+	}
+	if len(orphans) == 0 {
+		return
+	}
+
+	// The kinds are listed one after another, an owner created meanwhile
+	// may be missing although its dependent is not. A second list has it.
+	if metas, err = listObjectMetas(); err != nil {
+		logger.Error(err.Error())
+		return
+	}
+	g = newGraph(metas)
+	for _, orphan := range orphans {
+		if !g.hasOwner(orphan) {
+			log("the owners of %s %s are gone", orphan.Kind, orphan.SelfLink)
+			deleteObject(orphan, apiObject.DeletePropagationBackground)
+		}
+	}
+}
+
+// finalize runs the finalizer of the garbage collector that owner waits for
+func (c *controller) finalize(g *graph, owner *entity.ObjectMeta) {
+	uid := owner.Metadata.UID
+	dependents := g.dependents[uid]
+	switch {
+	case owner.Metadata.HasFinalizer(apiObject.FinalizerOrphanDependents):
+		for _, dependent := range dependents {
+			log("orphan %s %s", dependent.Kind, dependent.SelfLink)
+			err := updateMetadata(dependent, func(metadata *apiObject.Metadata) {
+				var refs []apiObject.OwnerReference
+				for _, ref := range metadata.OwnerReferences {
+					if ref.UID != uid {
+						refs = append(refs, ref)
+					}
+				}
+				metadata.OwnerReferences = refs
+			})
+			if err != nil {
+				logger.Error(err.Error())
+				return
+			}
+		}
+		removeFinalizer(owner, apiObject.FinalizerOrphanDependents)
+
+	case owner.Metadata.HasFinalizer(apiObject.FinalizerForegroundDeletion):
+		blocking := 0
+		for _, dependent := range dependents {
+			if dependent.Metadata.DeletionTimestamp == nil {
+				deleteObject(dependent, apiObject.DeletePropagationForeground)
+			}
+			if blocksOwnerDeletion(dependent, uid) {
+				blocking++
+			}
+		}
+		if blocking == 0 {
+			removeFinalizer(owner, apiObject.FinalizerForegroundDeletion)
+		}
+	}
+}
+
+func blocksOwnerDeletion(dependent *entity.ObjectMeta, uid string) bool {
+	for _, ref := range dependent.Metadata.OwnerReferences {
+		if ref.UID == uid {
+			return ref.BlockOwnerDeletion
+		}
+	}
+	return false
+}
+
+func removeFinalizer(meta *entity.ObjectMeta, finalizer string) {
+	log("%s of %s %s is done", finalizer, meta.Kind, meta.SelfLink)
+	err := updateMetadata(meta, func(metadata *apiObject.Metadata) {
+		var finalizers []string
+		for _, f := range metadata.Finalizers {
+			if f != finalizer {
+				finalizers = append(finalizers, f)
+			}
+		}
+		metadata.Finalizers = finalizers
+	})
+	if err != nil {
+		logger.Error(err.Error())
+	}
+}
+
+func listObjectMetas() (metas []*entity.ObjectMeta, err error) {
+	err = httputil.GetAndUnmarshal(url.Prefix+url.ObjectMetaURL, &metas)
+	return
+}
+
+func deleteObject(meta *entity.ObjectMeta, propagationPolicy string) {
+	URL := fmt.Sprintf("%s%s?propagationPolicy=%s", url.Prefix, meta.SelfLink, propagationPolicy)
+	if _, err := httputil.Delete(URL); err != nil && !httputil.IsNotFound(err) {
+		logger.Error(err.Error())
+		return
+	}
+	log("delete %s %s", meta.Kind, meta.SelfLink)
+}
+
+// updateMetadata reads the object, lets update change its metadata and writes
+// it back. The resourceVersion it was read with makes the write fail rather
+// than lose a change made in between, the next collection retries.
+func updateMetadata(meta *entity.ObjectMeta, update func(metadata *apiObject.Metadata)) error {
+	URL := url.Prefix + meta.SelfLink
+	var obj map[string]json.RawMessage
+	if err := httputil.GetAndUnmarshal(URL, &obj); err != nil {
+		return err
+	}
+	if obj == nil {
+		return fmt.Errorf("%s %s is gone", meta.Kind, meta.SelfLink)
+	}
+
+	metadata := apiObject.Metadata{}
+	if err := json.Unmarshal(obj["Metadata"], &metadata); err != nil {
+		return err
+	}
+	update(&metadata)
+	obj["Metadata"], _ = json.Marshal(metadata)
+
+	resp, err := httputil.PutJson(URL, obj)
+	if err != nil {
+		return err
+	}
+	if _, err = httputil.ReadResponse(resp); err != nil {
+		return fmt.Errorf("update %s %s: %s", meta.Kind, meta.SelfLink, err.Error())
+	}
+	return nil
+}
+
+func NewController() Controller {
+	return &controller{}
+}
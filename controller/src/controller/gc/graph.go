@@ -0,0 +1,33 @@
+package gc
+
+import "minik8s/entity"
+
+// graph links the objects to their dependents by the owner references
+type graph struct {
+	uids       map[string]bool
+	dependents map[string][]*entity.ObjectMeta
+}
+
+func newGraph(metas []*entity.ObjectMeta) *graph {
+	g := &graph{
+		uids:       make(map[string]bool, len(metas)),
+		dependents: make(map[string][]*entity.ObjectMeta),
+	}
+	for _, meta := range metas {
+		g.uids[meta.Metadata.UID] = true
+		for _, ref := range meta.Metadata.OwnerReferences {
+			g.dependents[ref.UID] = append(g.dependents[ref.UID], meta)
+		}
+	}
+	return g
+}
+
+// hasOwner tells whether any of the owners of meta still exists
+func (g *graph) hasOwner(meta *entity.ObjectMeta) bool {
+	for _, ref := range meta.Metadata.OwnerReferences {
+		if g.uids[ref.UID] {
+			return true
+		}
+	}
+	return false
+}
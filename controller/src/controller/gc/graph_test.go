@@ -0,0 +1,29 @@
+package gc
+
+import (
+	"github.com/stretchr/testify/assert"
+	"minik8s/apiObject"
+	"minik8s/entity"
+	"testing"
+)
+
+func meta(uid string, owners ...string) *entity.ObjectMeta {
+	m := &entity.ObjectMeta{Metadata: apiObject.Metadata{UID: uid}}
+	for _, owner := range owners {
+		m.Metadata.OwnerReferences = append(m.Metadata.OwnerReferences, apiObject.OwnerReference{UID: owner})
+	}
+	return m
+}
+
+func TestGraph(t *testing.T) {
+	rs := meta("rs")
+	pod1, pod2 := meta("pod1", "rs"), meta("pod2", "rs", "gone")
+	orphan := meta("pod3", "gone")
+	g := newGraph([]*entity.ObjectMeta{rs, pod1, pod2, orphan})
+
+	assert.ElementsMatch(t, []*entity.ObjectMeta{pod1, pod2}, g.dependents["rs"])
+	assert.True(t, g.hasOwner(pod1))
+	assert.True(t, g.hasOwner(pod2))
+	assert.False(t, g.hasOwner(orphan))
+	assert.False(t, g.hasOwner(rs))
+}
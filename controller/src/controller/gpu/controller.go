@@ -4,15 +4,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/go-redis/redis/v8"
-	"io/ioutil"
 	"minik8s/apiObject"
 	"minik8s/apiserver/src/url"
 	"minik8s/entity"
 	"minik8s/listwatch"
+	"minik8s/util/configutil"
 	"minik8s/util/httputil"
 	"minik8s/util/logger"
 	"minik8s/util/topicutil"
-	"path"
 )
 
 var log = logger.Log("Gpu")
@@ -45,20 +44,20 @@ func (c *controller) dispatchGpuJob(gpuJob *apiObject.GpuJob) {
 		fmt.Sprintf("--compile=%s", gpuJob.CompileScripts()),
 	}
 
-	podNamePrefix := gpuJob.Namespace() + "-" + gpuJob.Name()
 	pod := apiObject.Pod{
 		Base: apiObject.Base{
 			ApiVersion: "v1",
 			Kind:       "Pod",
 			Metadata: apiObject.Metadata{
-				Name:      gpuJob.Name(),
-				Namespace: gpuJob.Namespace(),
+				Name:            gpuJob.Name(),
+				Namespace:       gpuJob.Namespace(),
+				OwnerReferences: []apiObject.OwnerReference{gpuJob.OwnerReference()},
 			},
 		},
 		Spec: apiObject.PodSpec{
 			Containers: []apiObject.Container{
 				{
-					Name:  podNamePrefix + "-file-server",
+					Name:  "file-server",
 					Image: "dplsming/nginx-fileserver:1.0",
 					Ports: []apiObject.ContainerPort{
 						{
@@ -73,9 +72,13 @@ func (c *controller) dispatchGpuJob(gpuJob *apiObject.GpuJob) {
 					},
 				},
 				{
-					Name:    podNamePrefix + "-gpu-server",
+					Name:    "gpu-server",
 					Image:   minik8sGpuServerImage,
 					Command: gpuServerCommands,
+					// the gpu server reports the job status to redis
+					Env: []apiObject.EnvVar{
+						{Name: "MINIK8S_REDIS", Value: configutil.Current().Redis},
+					},
 					VolumeMounts: []apiObject.VolumeMount{
 						{
 							Name:      "volume",
@@ -98,22 +101,15 @@ func (c *controller) dispatchGpuJob(gpuJob *apiObject.GpuJob) {
 	}
 
 	URL := url.Prefix + url.PodURL
-	if resp, err := httputil.PostJson(URL, pod); err == nil {
-		content, _ := ioutil.ReadAll(resp.Body)
-		defer resp.Body.Close()
-		log("Apply pod and got resp: %s", content)
-	} else {
+	resp, err := httputil.PostJson(URL, pod)
+	if err == nil {
+		_, err = httputil.ReadResponse(resp)
+	}
+	if err != nil {
 		logger.Error(err.Error())
 	}
 }
 
-func (c *controller) deleteGpuJobPod(gpuJob *apiObject.GpuJob) {
-	log("delete gpu job pod %s/%s", gpuJob.Namespace(), gpuJob.Name())
-	namespace, name := gpuJob.Namespace(), gpuJob.Name()
-	URL := url.Prefix + path.Join(url.PodURL, namespace, name)
-	httputil.DeleteWithoutBody(URL)
-}
-
 func (c *controller) handleGpuJobUpdate(msg *redis.Message) {
 	gpuJobUpdate := &entity.GpuUpdate{}
 	if err := json.Unmarshal([]byte(msg.Payload), gpuJobUpdate); err != nil {
@@ -121,11 +117,9 @@ func (c *controller) handleGpuJobUpdate(msg *redis.Message) {
 	}
 	log("received gpu %s update: %+v", gpuJobUpdate.Action.String(), gpuJobUpdate)
 	gpuJob := gpuJobUpdate.Target
-	switch gpuJobUpdate.Action {
-	case entity.CreateAction:
+	// The pod of a deleted job is owned by it, the garbage collector deletes it
+	if gpuJobUpdate.Action == entity.CreateAction {
 		c.dispatchGpuJob(&gpuJob)
-	case entity.DeleteAction:
-		c.deleteGpuJobPod(&gpuJob)
 	}
 }
 
@@ -18,6 +18,8 @@ import (
 
 var logWorker = logger.Log("HPA worker")
 
+const maxUpdateRetries = 3
+
 type Worker interface {
 	Run()
 	SetTarget(rs *apiObject.HorizontalPodAutoscaler)
@@ -43,12 +45,34 @@ func (w *worker) getReplicaSetStatus() *entity.ReplicaSetStatus {
 	return w.cacheManager.GetReplicaSetStatus(UID)
 }
 
+// updateReplicaSetToApiServer sets the replicas of the rs, based on the
+// resourceVersion it has just read. If someone else (e.g. kubectl) modifies
+// the rs in between, the api-server answers 409 Conflict, and we re-read it
+// and try again.
 func (w *worker) updateReplicaSetToApiServer(namespace, name string, numReplicas int) {
 	URL := url.Prefix + path.Join(url.ReplicaSetURL, namespace, name)
-	resp := httputil.PutForm(URL, map[string]string{
-		"replicas": strconv.Itoa(numReplicas),
-	})
-	logWorker("update rs and get resp: %s", resp)
+	for i := 0; i < maxUpdateRetries; i++ {
+		rs := &apiObject.ReplicaSet{}
+		if err := httputil.GetAndUnmarshal(URL, &rs); err != nil || rs == nil {
+			logWorker("get rs %s/%s failed", namespace, name)
+			return
+		}
+
+		resp, err := httputil.PutJson(URL+"/scale", map[string]string{
+			"replicas":        strconv.Itoa(numReplicas),
+			"resourceVersion": rs.Metadata.ResourceVersion,
+		})
+		if err != nil {
+			logger.Error(err.Error())
+			return
+		}
+		body, err := httputil.ReadResponse(resp)
+		if !httputil.IsConflict(err) {
+			logWorker("update rs and get resp: %s %v", body, err)
+			return
+		}
+		logWorker("update rs conflicts, retry: %s", err.Error())
+	}
 }
 
 func (w *worker) updateReplicaSet(namespace, name string, numReplicas int) {
@@ -2,10 +2,13 @@ package controller
 
 import (
 	"minik8s/controller/src/cache"
+	"minik8s/controller/src/controller/deployment"
+	"minik8s/controller/src/controller/gc"
 	"minik8s/controller/src/controller/gpu"
 	"minik8s/controller/src/controller/hpa"
 	"minik8s/controller/src/controller/node"
 	"minik8s/controller/src/controller/replicaSet"
+	"minik8s/util/leaderelection"
 	"minik8s/util/wait"
 )
 
@@ -14,29 +17,42 @@ type Manager interface {
 }
 
 type manager struct {
+	leaderElection       leaderelection.Config
 	cacheManager         cache.Manager
 	hpaController        hpa.Controller
 	replicaSetController replicaSet.Controller
+	deploymentController deployment.Controller
 	nodeController       node.Controller
 	gpuController        gpu.Controller
+	gcController         gc.Controller
 }
 
 func (m *manager) Start() {
+	leaderelection.Run(m.leaderElection, m.run)
+}
+
+func (m *manager) run() {
 	m.cacheManager.Start()
 	go m.replicaSetController.Run()
+	go m.deploymentController.Run()
 	go m.hpaController.Run()
 	go m.nodeController.Run()
 	go m.gpuController.Run()
+	go m.gcController.Run()
 	wait.Forever()
 }
 
-func NewControllerManager() Manager {
-	m := &manager{}
+// NewControllerManager returns a controller manager, which only runs the
+// controllers while it leads the replicas elected by leaderElection.
+func NewControllerManager(leaderElection leaderelection.Config) Manager {
+	m := &manager{leaderElection: leaderElection}
 	m.cacheManager = cache.NewManager()
 	m.replicaSetController = replicaSet.NewController(m.cacheManager)
+	m.deploymentController = deployment.NewController()
 	m.hpaController = hpa.NewController(m.cacheManager)
 	m.nodeController = node.NewController(m.cacheManager)
 	m.gpuController = gpu.NewController()
+	m.gcController = gc.NewController()
 
 	m.cacheManager.SetPodStatusUpdateHook(m.replicaSetController.Sync)
 	m.cacheManager.SetReplicaSetFullSyncAddHook(m.replicaSetController.AddReplicaSet)
@@ -4,6 +4,7 @@ import (
 	"minik8s/apiserver/src/url"
 	"minik8s/controller/src/cache"
 	"minik8s/entity"
+	"minik8s/util/configutil"
 	"minik8s/util/httputil"
 	"minik8s/util/logger"
 	"minik8s/util/wait"
@@ -13,7 +14,6 @@ import (
 	"time"
 )
 
-const syncPeriod = time.Second * 5
 const unhealthyTime = time.Second * 40
 const unknownTime = time.Minute * 2
 const deleteTime = time.Minute * 4
@@ -91,6 +91,7 @@ func (c *controller) syncLoopIteration() {
 }
 
 func (c *controller) syncLoop() {
+	syncPeriod := configutil.Current().SyncPeriods.NodeMonitor
 	wait.Period(syncPeriod, syncPeriod, c.syncLoopIteration)
 }
 
@@ -49,20 +49,12 @@ func (c *controller) AddReplicaSet(rs *apiObject.ReplicaSet) {
 	}
 }
 
-func (c *controller) deleteReplicaSetPods(rs *apiObject.ReplicaSet) {
-	logManager("Now delete the pods of rs: %s-%s", rs.FullName(), rs.UID())
-	podStatuses := c.cacheManager.GetReplicaSetPodStatuses(rs.UID())
-	for _, podStatus := range podStatuses {
-		deletePodToApiServer(podStatus.Namespace, podStatus.Name)
-	}
-}
-
 func (c *controller) DeleteReplicaSet(rs *apiObject.ReplicaSet) {
 	UID := rs.UID()
 	logManager("Delete replicaSet: %s_%s", rs.FullName(), UID)
 	if worker, stillWorking := c.workers[UID]; stillWorking {
+		// The pods are deleted or orphaned by the garbage collector
 		close(worker.SyncChannel())
-		c.deleteReplicaSetPods(rs)
 		worker.Done()
 		delete(c.workers, UID)
 	}
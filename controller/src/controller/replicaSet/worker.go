@@ -6,6 +6,7 @@ import (
 	"minik8s/controller/src/cache"
 	"minik8s/entity"
 	"minik8s/kubelet/src/runtime/runtime"
+	"minik8s/util/eventutil"
 	"minik8s/util/httputil"
 	"minik8s/util/logger"
 	"minik8s/util/uidutil"
@@ -14,6 +15,8 @@ import (
 
 var logWorker = logger.Log("ReplicaSet Worker")
 
+var recorder = eventutil.NewRecorder("replicaset-controller")
+
 const timeoutSeconds = 30
 const workChanSize = 5
 
@@ -47,14 +50,23 @@ func (w *worker) SyncChannel() chan<- struct{} {
 func (w *worker) addPodToApiServer() {
 	podTemplate := w.target.Template()
 	pod := podTemplate.ToPod()
-	pod.Metadata.Name = "ReplicaSet-" + w.target.Name() + "-" + uidutil.New()
+	pod.Metadata.Name = w.target.Name() + "-" + uidutil.New()
 	pod.Metadata.Namespace = w.target.Namespace()
 	pod.AddLabel(runtime.KubernetesReplicaSetUIDLabel, w.target.UID())
+	pod.Metadata.OwnerReferences = []apiObject.OwnerReference{w.target.OwnerReference()}
 
 	URL := url.Prefix + url.PodURL
-	if _, err := httputil.PostJson(URL, pod); err != nil {
+	resp, err := httputil.PostJson(URL, pod)
+	if err == nil {
+		_, err = httputil.ReadResponse(resp)
+	}
+	ref := apiObject.ReferenceTo("ReplicaSet", w.target)
+	if err != nil {
 		logger.Error(err.Error())
+		recorder.Eventf(ref, apiObject.EventTypeWarning, "FailedCreate", "Error creating pod: %s", err.Error())
+		return
 	}
+	recorder.Eventf(ref, apiObject.EventTypeNormal, "SuccessfulCreate", "Created pod: %s", pod.Name())
 }
 
 func (w *worker) addPod() {
@@ -63,6 +75,7 @@ func (w *worker) addPod() {
 
 func (w *worker) deletePod(namespace, name string) {
 	deletePodToApiServer(namespace, name)
+	recorder.Eventf(apiObject.ReferenceTo("ReplicaSet", w.target), apiObject.EventTypeNormal, "SuccessfulDelete", "Deleted pod: %s", name)
 }
 
 func (w *worker) numRunningPods(podStatuses []*entity.PodStatus) int {
@@ -85,13 +98,15 @@ func (w *worker) syncLoopIteration() bool {
 	diff := numPods - numReplicas
 	logWorker("Syn result: diff = %d", diff)
 	cpu, mem := w.calcMetrics(podStatuses)
-	if diff == 0 {
+	if diff == 0 && numRunningPods == numReplicas {
 		w.ready(cpu, mem)
+	} else if diff == 0 {
+		w.scaling(numRunningPods, cpu, mem)
 	} else if diff > 0 {
 		podToDelete := podStatuses[0]
 		w.scaling(numRunningPods, cpu, mem)
 		go w.deletePod(podToDelete.Namespace, podToDelete.Name)
-	} else {
+	} else if w.target.Metadata.DeletionTimestamp == nil {
 		w.scaling(numRunningPods, cpu, mem)
 		go w.addPod()
 	}
@@ -9,6 +9,7 @@ import (
 	"minik8s/controller/src/controller"
 	"minik8s/entity"
 	"minik8s/listwatch"
+	"minik8s/util/leaderelection"
 	"minik8s/util/topicutil"
 	"minik8s/util/uidutil"
 	"testing"
@@ -59,6 +60,6 @@ func TestControllerManager(t *testing.T) {
 		listwatch.Publish(topic, msg)
 	}()
 
-	cm := controller.NewControllerManager()
+	cm := controller.NewControllerManager(leaderelection.Config{})
 	cm.Start()
 }
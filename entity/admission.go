@@ -0,0 +1,32 @@
+package entity
+
+import "encoding/json"
+
+// AdmissionReview is sent to an admission webhook with Request set, and
+// comes back with Response set.
+type AdmissionReview struct {
+	Request  *AdmissionRequest
+	Response *AdmissionResponse
+}
+
+// AdmissionRequest carries the object to admit, OldObject is only set for an
+// update.
+type AdmissionRequest struct {
+	UID       string
+	Kind      string
+	Namespace string
+	Name      string
+	Operation string
+	Object    json.RawMessage
+	OldObject json.RawMessage `json:",omitempty"`
+}
+
+// AdmissionResponse answers the request of the same UID. A mutating webhook
+// may return the whole changed object in Object, the name, namespace and uid
+// of which cannot change.
+type AdmissionResponse struct {
+	UID     string
+	Allowed bool
+	Message string          `json:",omitempty"`
+	Object  json.RawMessage `json:",omitempty"`
+}
@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// Backup is a snapshot of every key of the api-server, taken at Revision
+type Backup struct {
+	Revision int64
+	Time     time.Time
+	Keys     []BackupKey
+}
+
+// BackupKey is a key of a backup, with the revision it was last modified at
+type BackupKey struct {
+	Key         string
+	Value       string
+	ModRevision int64
+}
@@ -0,0 +1,8 @@
+package entity
+
+import "minik8s/apiObject"
+
+type DeploymentUpdate struct {
+	Action ApiObjectUpdateAction
+	Target apiObject.Deployment
+}
@@ -37,8 +37,22 @@ type FunctionTriggerResult struct {
 	FinishedAll       bool
 }
 
+func (ftr *FunctionTriggerResult) Fields() map[string]string {
+	return map[string]string{
+		"metadata.name":      ftr.WorkflowName,
+		"metadata.namespace": ftr.WorkflowNamespace,
+		"status.phase":       string(ftr.Status),
+	}
+}
+
 type FunctionStatus struct {
 	Name      string
 	Instances int
 	CodePath  string
 }
+
+func (fs *FunctionStatus) Fields() map[string]string {
+	return map[string]string{
+		"metadata.name": fs.Name,
+	}
+}
@@ -0,0 +1,11 @@
+package entity
+
+import "minik8s/apiObject"
+
+// ObjectMeta is what the garbage collector knows about an object. SelfLink is
+// the URL that serves GET, PUT and DELETE of the object.
+type ObjectMeta struct {
+	Kind     string
+	SelfLink string
+	Metadata apiObject.Metadata
+}
@@ -16,3 +16,11 @@ type GpuJobStatus struct {
 	State        string
 	LastSyncTime time.Time
 }
+
+func (gs *GpuJobStatus) Fields() map[string]string {
+	return map[string]string{
+		"metadata.name":      gs.Name,
+		"metadata.namespace": gs.Namespace,
+		"status.phase":       gs.State,
+	}
+}
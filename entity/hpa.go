@@ -54,3 +54,12 @@ type HPAStatus struct {
 	Error       string
 	SyncTime    time.Time
 }
+
+func (hs *HPAStatus) Fields() map[string]string {
+	return map[string]string{
+		"metadata.name":      hs.Name,
+		"metadata.namespace": hs.Namespace,
+		"metadata.uid":       hs.ID,
+		"status.phase":       hs.Lifecycle.String(),
+	}
+}
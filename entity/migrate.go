@@ -0,0 +1,9 @@
+package entity
+
+// StorageMigration is how many objects of Kind were rewritten in its
+// StorageVersion by a migration.
+type StorageMigration struct {
+	Kind           string
+	StorageVersion string
+	Migrated       int
+}
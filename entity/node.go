@@ -42,3 +42,12 @@ type NodeStatus struct {
 	NumPods    int
 	SyncTime   time.Time
 }
+
+func (ns *NodeStatus) Fields() map[string]string {
+	return map[string]string{
+		"metadata.name":      ns.Hostname,
+		"metadata.namespace": ns.Namespace,
+		"status.phase":       ns.Lifecycle.String(),
+		"status.address":     ns.Ip,
+	}
+}
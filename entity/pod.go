@@ -52,6 +52,18 @@ type PodStatus struct {
 	SyncTime     time.Time
 }
 
+// Fields are what a field selector of the pod list is matched against
+func (ps *PodStatus) Fields() map[string]string {
+	return map[string]string{
+		"metadata.name":      ps.Name,
+		"metadata.namespace": ps.Namespace,
+		"metadata.uid":       ps.ID,
+		"spec.nodeName":      ps.Node,
+		"status.phase":       ps.Lifecycle.String(),
+		"status.podIP":       ps.Ip,
+	}
+}
+
 type PodStatusLogEntry struct {
 	Status PodLifecycle
 	Time   time.Time
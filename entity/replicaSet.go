@@ -52,3 +52,12 @@ type ReplicaSetStatus struct {
 func (rss *ReplicaSetStatus) FullName() string {
 	return rss.Name + "_" + rss.Namespace
 }
+
+func (rss *ReplicaSetStatus) Fields() map[string]string {
+	return map[string]string{
+		"metadata.name":      rss.Name,
+		"metadata.namespace": rss.Namespace,
+		"metadata.uid":       rss.ID,
+		"status.phase":       rss.Lifecycle.String(),
+	}
+}
@@ -0,0 +1,41 @@
+package entity
+
+// The reasons of a failed request, which tell the clients what went wrong
+// without looking at the message.
+const (
+	StatusReasonBadRequest           = "BadRequest"
+	StatusReasonUnauthorized         = "Unauthorized"
+	StatusReasonForbidden            = "Forbidden"
+	StatusReasonNotFound             = "NotFound"
+	StatusReasonMethodNotAllowed     = "MethodNotAllowed"
+	StatusReasonUnsupportedMediaType = "UnsupportedMediaType"
+	StatusReasonAlreadyExists        = "AlreadyExists"
+	StatusReasonConflict             = "Conflict"
+	StatusReasonInvalid              = "Invalid"
+	StatusReasonExpired              = "Expired"
+	StatusReasonInternalError        = "InternalError"
+)
+
+// Status is the body of every failed request to the api-server, Code being
+// its http status code.
+type Status struct {
+	Code    int
+	Reason  string
+	Message string
+	Details *StatusDetails `json:",omitempty"`
+}
+
+// StatusDetails names the object the request was about, and for an invalid
+// one what is wrong with each of its fields.
+type StatusDetails struct {
+	Kind      string
+	Namespace string `json:",omitempty"`
+	Name      string
+	Causes    []StatusCause `json:",omitempty"`
+}
+
+// StatusCause is an invalid field, e.g. spec.containers[0].image
+type StatusCause struct {
+	Field   string
+	Message string
+}
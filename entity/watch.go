@@ -0,0 +1,20 @@
+package entity
+
+import "encoding/json"
+
+type WatchEventType string
+
+const (
+	WatchAdded    WatchEventType = "ADDED"
+	WatchModified WatchEventType = "MODIFIED"
+	WatchDeleted  WatchEventType = "DELETED"
+	WatchError    WatchEventType = "ERROR"
+)
+
+// WatchEvent is a single line of the chunked response of a watch request,
+// i.e. GET /api/v1/<kind>/?watch=true&resourceVersion=N.
+// Object is the stored api object, or the error message for WatchError.
+type WatchEvent struct {
+	Type   WatchEventType
+	Object json.RawMessage
+}
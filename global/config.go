@@ -1,5 +1,6 @@
 package global
 
-//const Host = "10.119.11.101"
-
-const Host = "localhost"
+// CredentialsDir holds the token file of the api-server and the credentials
+// of the components, which the api-server generates on its first start. The
+// local cluster keeps them in its data directory instead.
+var CredentialsDir = "/etc/minik8s"
@@ -13,9 +13,9 @@ require (
 	github.com/rodaine/table v1.0.1
 	github.com/satori/go.uuid v1.2.0
 	github.com/spf13/cobra v1.4.0
-	github.com/stretchr/testify v1.7.1
+	github.com/stretchr/testify v1.8.1
 	go.etcd.io/etcd v3.3.27+incompatible
-	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -29,13 +29,17 @@ require (
 	github.com/opencontainers/image-spec v1.0.2 // indirect
 	github.com/shirou/gopsutil v3.21.11+incompatible
 	github.com/spf13/cast v1.5.0
+	github.com/spf13/pflag v1.0.5
 	github.com/tidwall/gjson v1.14.1
 	github.com/tklauser/go-sysconf v0.3.10 // indirect
 	github.com/yusufpapurcu/wmi v1.2.2 // indirect
 	golang.org/x/net v0.0.0-20220513224357-95641704303c // indirect
-	golang.org/x/sys v0.0.0-20220513210249-45d2b4557a2a // indirect
 	google.golang.org/genproto v0.0.0-20220505152158-f39f71e6c8f3 // indirect
 	google.golang.org/grpc v1.46.2 // indirect
 )
 
 replace google.golang.org/grpc => google.golang.org/grpc v1.26.0
+
+// the embedded etcd imports bbolt by its former path, and needs a version
+// with Options.OpenFile
+replace github.com/coreos/bbolt => go.etcd.io/bbolt v1.3.8
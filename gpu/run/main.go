@@ -4,6 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"minik8s/gpu/src/gpu"
+	"minik8s/util/configutil"
+	"os"
 )
 
 var (
@@ -23,7 +25,12 @@ func main() {
 	flag.StringVar(&args.RunScripts, "run", "", "run scripts")
 	flag.StringVar(&args.Username, "username", "", "username")
 	flag.StringVar(&args.Password, "password", "", "password")
+	configutil.AddFlags(flag.CommandLine)
 	flag.Parse()
+	if err := configutil.Load(flag.CommandLine); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(-1)
+	}
 
 	fmt.Printf("Read args: jobName = %v, output = %v, error = %v, n = %v\n", args.JobName, args.Output, args.Error, args.NumProcess)
 	fmt.Printf("Read args: numTasksPerNode = %v, cpusPerTask = %v, gpuResources = %v\n", args.NumTasksPerNode, args.CpusPerTask, args.GpuResources)
@@ -10,6 +10,7 @@ import (
 	"minik8s/apiObject/types"
 	"minik8s/entity"
 	"minik8s/gpu/src/ssh"
+	"minik8s/util/configutil"
 	"minik8s/util/logger"
 	"minik8s/util/recoverutil"
 	"minik8s/util/topicutil"
@@ -37,16 +38,11 @@ type JobArgs struct {
 	Password        string
 }
 
-const pollPeriod = time.Minute * 1
 const DefaultJobURL = "./usr/local/jobs"
 
 var (
-	client = redis.NewClient(&redis.Options{
-		Addr:     "10.119.11.101:6379",
-		Password: "",
-		DB:       0,
-	})
-	ctx = context.Background()
+	client *redis.Client
+	ctx    = context.Background()
 )
 
 type Server interface {
@@ -222,6 +218,7 @@ func (s *server) Run() {
 		logger.Error("submit: " + err.Error())
 		return
 	}
+	pollPeriod := configutil.Current().SyncPeriods.GpuJobPoll
 	wait.PeriodWithCondition(pollPeriod, pollPeriod, s.poll)
 	fmt.Println("Job finished, now download the result")
 	s.downloadResult()
@@ -230,6 +227,11 @@ func (s *server) Run() {
 }
 
 func NewServer(args JobArgs, jobsURL string) Server {
+	client = redis.NewClient(&redis.Options{
+		Addr:     configutil.Current().Redis,
+		Password: "",
+		DB:       0,
+	})
 	return &server{
 		cli:     ssh.NewClient(args.Username, args.Password),
 		args:    args,
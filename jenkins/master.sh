@@ -13,6 +13,9 @@ go env -w GOROOT="/usr/local/lib/go"
 
 log_dir=$WORKSPACE/logs
 
+# the worker nodes pull the function images from the registry of the master
+export MINIK8S_REGISTRY=10.119.11.101:5000
+
 rm -f /root/gpu/matrix_op*
 
 kill -9 $(ps -e | grep api-server | awk '{print $1}')
@@ -13,6 +13,11 @@ go env -w GOROOT="/usr/local/go"
 
 log_dir=$WORKSPACE/logs
 
+# the control plane runs on the master node
+export MINIK8S_API_SERVER=10.119.11.101:8080
+export MINIK8S_REDIS=10.119.11.101:6379
+export MINIK8S_REGISTRY=10.119.11.101:5000
+
 kill -9 $(ps -e | grep kubelet | awk '{print $1}')
 kill -9 $(ps -e | grep kubeproxy | awk '{print $1}')
 
@@ -1,13 +1,20 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 	"minik8s/apiObject"
+	autoscalingv2 "minik8s/apiObject/autoscaling/v2"
+	"minik8s/apiserver/src/contentType"
 	"minik8s/apiserver/src/url"
 	"minik8s/kubectl/src/util"
-	"minik8s/util/apiutil"
+	"minik8s/util/httputil"
+	"net/http"
+	"path"
+	"strings"
 )
 
 var applyCmd = &cobra.Command{
@@ -17,6 +24,60 @@ var applyCmd = &cobra.Command{
 	Run:   apply,
 }
 
+// objectURL is the URL of the object named by metadata, whose kind is served
+// under URL. An object of a namespaced kind goes to the default namespace if
+// it names none.
+func objectURL(URL string, metadata *apiObject.Metadata, namespaced bool) string {
+	if !namespaced {
+		return url.Prefix + URL + metadata.Name
+	}
+	namespace := metadata.Namespace
+	if namespace == "" {
+		namespace = apiObject.DefaultNamespace
+	}
+	return url.Prefix + URL + path.Join(namespace, metadata.Name)
+}
+
+// applyObject sends the configuration obj to the URL of the object, the
+// api-server creates the object or updates it to match the configuration.
+func applyObject(kind, URL string, obj apiObject.Object) {
+	config, _ := json.Marshal(obj)
+	req, err := httputil.NewRequest(http.MethodPatch, URL, bytes.NewReader(config))
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	req.Header.Set("Content-Type", contentType.ApplyPatch)
+
+	resp, err := httputil.Do(req)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	defer resp.Body.Close()
+	if err = httputil.CheckResponse(resp); err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	name := obj.Meta().Name
+	if obj.Meta().Namespace != "" {
+		name = path.Join(obj.Meta().Namespace, name)
+	}
+	if resp.StatusCode == http.StatusCreated {
+		fmt.Printf("%s %s created\n", strings.ToLower(kind), name)
+	} else {
+		fmt.Printf("%s %s configured\n", strings.ToLower(kind), name)
+	}
+}
+
+// decodeConfig decodes the yaml configuration content into obj, failing on
+// the fields obj does not have unless --validate=false.
+func decodeConfig(content []byte, obj interface{}) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(validate)
+	return decoder.Decode(obj)
+}
+
 func apply(cmd *cobra.Command, args []string) {
 	content, err := util.LoadContent(filePath)
 	if err != nil {
@@ -33,63 +94,129 @@ func apply(cmd *cobra.Command, args []string) {
 	switch tp {
 	case util.Node:
 		node := apiObject.Node{}
-		if err = yaml.Unmarshal(content, &node); err != nil {
+		if err = decodeConfig(content, &node); err != nil {
 			fmt.Println(err.Error())
 			return
 		}
-		URL := url.Prefix + url.NodeURL
-		apiutil.ApplyApiObjectToApiServer(URL, node)
+		applyObject(tp.String(), objectURL(url.NodeURL, &node.Metadata, true), &node)
 	case util.Pod:
 		pod := apiObject.Pod{}
-		if err = yaml.Unmarshal(content, &pod); err != nil {
+		if err = decodeConfig(content, &pod); err != nil {
 			fmt.Println(err.Error())
 			return
 		}
-		URL := url.Prefix + url.PodURL
-		apiutil.ApplyApiObjectToApiServer(URL, pod)
+		applyObject(tp.String(), objectURL(url.PodURL, &pod.Metadata, true), &pod)
 	case util.ReplicaSet:
 		rs := apiObject.ReplicaSet{}
-		if err = yaml.Unmarshal(content, &rs); err != nil {
+		if err = decodeConfig(content, &rs); err != nil {
 			fmt.Println(err.Error())
 			return
 		}
-		URL := url.Prefix + url.ReplicaSetURL
-		apiutil.ApplyApiObjectToApiServer(URL, rs)
-	case util.HorizontalPodAutoscaler:
-		hpa := apiObject.HorizontalPodAutoscaler{}
-		if err = yaml.Unmarshal(content, &hpa); err != nil {
+		applyObject(tp.String(), objectURL(url.ReplicaSetURL, &rs.Metadata, true), &rs)
+	case util.Deployment:
+		deployment := apiObject.Deployment{}
+		if err = decodeConfig(content, &deployment); err != nil {
 			fmt.Println(err.Error())
 			return
 		}
-		if hpa.MinReplicas() > hpa.MaxReplicas() {
-			fmt.Println("Minimum number of replicas should be less than Maximum one!")
+		applyObject(tp.String(), objectURL(url.DeploymentURL, &deployment.Metadata, true), &deployment)
+	case util.HorizontalPodAutoscaler:
+		version := apiObject.Base{}
+		if err = yaml.Unmarshal(content, &version); err == nil && version.ApiVersion == autoscalingv2.GroupVersion {
+			hpa := autoscalingv2.HorizontalPodAutoscaler{}
+			if err = decodeConfig(content, &hpa); err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			applyObject(tp.String(), objectURL(url.HPAV2URL, &hpa.Metadata, true), &hpa)
 			return
 		}
-		URL := url.Prefix + url.HPAURL
-		apiutil.ApplyApiObjectToApiServer(URL, hpa)
+		hpa := apiObject.HorizontalPodAutoscaler{}
+		if err = decodeConfig(content, &hpa); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		applyObject(tp.String(), objectURL(url.HPAURL, &hpa.Metadata, true), &hpa)
 	case util.Service:
 		service := apiObject.Service{}
-		if err = yaml.Unmarshal(content, &service); err != nil {
+		if err = decodeConfig(content, &service); err != nil {
 			fmt.Println(err.Error())
 			return
 		}
-		URL := url.Prefix + url.ServiceURL
-		apiutil.ApplyApiObjectToApiServer(URL, service)
+		applyObject(tp.String(), objectURL(url.ServiceURL, &service.Metadata, true), &service)
 	case util.DNS:
 		dns := apiObject.Dns{}
-		if err = yaml.Unmarshal(content, &dns); err != nil {
+		if err = decodeConfig(content, &dns); err != nil {
 			fmt.Println(err.Error())
 			return
 		}
-		URL := url.Prefix + url.DNSURL
-		apiutil.ApplyApiObjectToApiServer(URL, dns)
+		applyObject(tp.String(), objectURL(url.DNSURL, &dns.Metadata, true), &dns)
 	case util.GpuJob:
 		gpu := apiObject.GpuJob{}
-		if err = yaml.Unmarshal(content, &gpu); err != nil {
+		if err = decodeConfig(content, &gpu); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		applyObject(tp.String(), objectURL(url.GpuURL, &gpu.Metadata, true), &gpu)
+	case util.Namespace:
+		ns := apiObject.Namespace{}
+		if err = decodeConfig(content, &ns); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		applyObject(tp.String(), objectURL(url.NamespaceURL, &ns.Metadata, false), &ns)
+	case util.MutatingWebhookConfiguration:
+		config := apiObject.MutatingWebhookConfiguration{}
+		if err = decodeConfig(content, &config); err != nil {
 			fmt.Println(err.Error())
 			return
 		}
-		URL := url.Prefix + url.GpuURL
-		apiutil.ApplyApiObjectToApiServer(URL, gpu)
+		applyObject(tp.String(), objectURL(url.MutatingWebhookURL, &config.Metadata, false), &config)
+	case util.ValidatingWebhookConfiguration:
+		config := apiObject.ValidatingWebhookConfiguration{}
+		if err = decodeConfig(content, &config); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		applyObject(tp.String(), objectURL(url.ValidatingWebhookURL, &config.Metadata, false), &config)
+	case util.Role:
+		role := apiObject.Role{}
+		if err = decodeConfig(content, &role); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		applyObject(tp.String(), objectURL(url.RoleURL, &role.Metadata, true), &role)
+	case util.ClusterRole:
+		role := apiObject.ClusterRole{}
+		if err = decodeConfig(content, &role); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		applyObject(tp.String(), objectURL(url.ClusterRoleURL, &role.Metadata, false), &role)
+	case util.RoleBinding:
+		binding := apiObject.RoleBinding{}
+		if err = decodeConfig(content, &binding); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		applyObject(tp.String(), objectURL(url.RoleBindingURL, &binding.Metadata, true), &binding)
+	case util.ClusterRoleBinding:
+		binding := apiObject.ClusterRoleBinding{}
+		if err = decodeConfig(content, &binding); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		applyObject(tp.String(), objectURL(url.ClusterRoleBindingURL, &binding.Metadata, false), &binding)
+	case util.CustomResourceDefinition:
+		crd := apiObject.CustomResourceDefinition{}
+		if err = decodeConfig(content, &crd); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		applyObject(tp.String(), objectURL(url.CRDURL, &crd.Metadata, false), &crd)
+	default:
+		if err = applyCustomObject(content); err != nil {
+			fmt.Println(err.Error())
+		}
 	}
 }
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io/ioutil"
+	"minik8s/apiserver/src/url"
+	"minik8s/entity"
+	"minik8s/util/httputil"
+	"strings"
+	"time"
+)
+
+var (
+	backupOutput string
+	backupFile   string
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Kubectl backup is used to back up and restore the state of minik8s",
+	Long: `Kubectl backup is used to back up and restore the state of minik8s, the operation is create or restore.
+For example: kubectl backup create -o minik8s.backup; kubectl backup restore -f minik8s.backup`,
+	Args: cobra.ExactArgs(1),
+	Run:  backup,
+}
+
+func createBackup(output string) error {
+	if output == "" {
+		return fmt.Errorf("the file to write the backup to is required, by -o")
+	}
+	backup := entity.Backup{}
+	if err := httputil.GetAndUnmarshal(url.Prefix+url.BackupURL, &backup); err != nil {
+		return err
+	}
+	content, _ := json.Marshal(backup)
+	if err := ioutil.WriteFile(output, content, 0600); err != nil {
+		return err
+	}
+	fmt.Printf("backed up %d keys at revision %d to %s\n", len(backup.Keys), backup.Revision, output)
+	return nil
+}
+
+func restoreBackup(file string) error {
+	if file == "" {
+		return fmt.Errorf("the backup file is required, by -f")
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	backup := entity.Backup{}
+	if err = json.Unmarshal(content, &backup); err != nil {
+		return fmt.Errorf("%s is not a backup: %s", file, err.Error())
+	}
+	resp, err := httputil.PostJson(url.Prefix+url.BackupURL, backup)
+	if err == nil {
+		_, err = httputil.ReadResponse(resp)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("restored %d keys of the backup taken at %s\n", len(backup.Keys), backup.Time.Format(time.RFC3339))
+	return nil
+}
+
+func backup(cmd *cobra.Command, args []string) {
+	op := strings.ToLower(args[0])
+	var err error
+	switch op {
+	case "create":
+		err = createBackup(backupOutput)
+	case "restore":
+		err = restoreBackup(backupFile)
+	default:
+		err = fmt.Errorf("invalid operation \"%s\", acceptable operation is create or restore", op)
+	}
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/url"
+	"minik8s/util/certutil"
+	"minik8s/util/httputil"
+	"path"
+	"strings"
+)
+
+var (
+	certsHosts     []string
+	certsGroups    []string
+	certsOutputDir string
+)
+
+var certsCmd = &cobra.Command{
+	Use:   "certs",
+	Short: "Kubectl certs is used to manage the cluster CA and the certificates it signs",
+	Long: `Kubectl certs is used to manage the cluster CA and the certificates it signs, the operation is init, issue, list, approve or deny.
+For example: kubectl certs init --hosts 10.119.11.101; kubectl certs issue alice --groups dev; kubectl certs approve node-worker1`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  certs,
+}
+
+// initCerts creates the cluster CA unless it exists, and issues the serving
+// certificate of the api-server again, on the master node.
+func initCerts(hosts []string) error {
+	if err := certutil.Bootstrap(hosts, true); err != nil {
+		return err
+	}
+	fmt.Printf("the cluster CA is %s, copy it to the same place on the worker nodes\n", certutil.CertFile(certutil.CA))
+	fmt.Printf("issued the certificate of the api-server, restart it to serve it\n")
+	return nil
+}
+
+// issueCert signs a client certificate of user in groups by the CA on this
+// node, and writes it with its key to dir.
+func issueCert(user string, groups []string, dir string) error {
+	ca, err := certutil.ReadCA()
+	if err != nil {
+		return err
+	}
+	kp, err := ca.IssueClient(user, groups)
+	if err != nil {
+		return err
+	}
+	name := strings.ReplaceAll(user, ":", "-")
+	certFile, keyFile := path.Join(dir, name+".crt"), path.Join(dir, name+".key")
+	if err = kp.Write(certFile, keyFile); err != nil {
+		return err
+	}
+	fmt.Printf("issued %s and %s, use them by clientCertificate and clientKey in the credentials of %s\n", certFile, keyFile, user)
+	return nil
+}
+
+func csrTbl() table.Table {
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	tbl := table.New("Name", "Requestor", "Subject", "Groups", "Condition")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	return tbl
+}
+
+func listCSRs() error {
+	var csrs []apiObject.CertificateSigningRequest
+	if err := getList(url.Prefix+url.CSRURL, &csrs); err != nil {
+		return err
+	}
+
+	tbl := csrTbl()
+	for i := range csrs {
+		csr := &csrs[i]
+		subject, groups := csrSubject(csr)
+		condition := csr.Condition()
+		if condition == "" {
+			condition = "Pending"
+		}
+		tbl.AddRow(csr.Name(), csr.Spec.Username, subject, groups, condition)
+	}
+	tbl.Print()
+	return nil
+}
+
+// csrSubject returns the user and groups csr asks a certificate for, read
+// from the request itself, which is what gets signed. A subject recorded
+// otherwise by the api-server is shown along with it.
+func csrSubject(csr *apiObject.CertificateSigningRequest) (subject, groups string) {
+	request, err := certutil.ParseRequest([]byte(csr.Spec.Request))
+	if err != nil {
+		return "<invalid>", ""
+	}
+	subject, groups = request.Subject.CommonName, strings.Join(request.Subject.Organization, ",")
+	recordedGroups := strings.Join(csr.Spec.Organization, ",")
+	if csr.Spec.CommonName != "" && (csr.Spec.CommonName != subject || recordedGroups != groups) {
+		subject = fmt.Sprintf("%s (recorded as %s in [%s])", subject, csr.Spec.CommonName, recordedGroups)
+	}
+	return subject, groups
+}
+
+func setCSRCondition(name, conditionType string) error {
+	condition := apiObject.CertificateSigningRequestCondition{
+		Type:   conditionType,
+		Reason: "KubectlCerts",
+	}
+	URL := url.Prefix + strings.Replace(url.CSRApprovalURLWithSpecifiedName, ":name", name, 1)
+	resp, err := httputil.PutJson(URL, condition)
+	var content string
+	if err == nil {
+		content, err = httputil.ReadResponse(resp)
+	}
+	if err != nil {
+		return err
+	}
+	csr := apiObject.CertificateSigningRequest{}
+	if err = json.Unmarshal([]byte(content), &csr); err != nil {
+		return err
+	}
+	subject, groups := csrSubject(&csr)
+	fmt.Printf("certificate signing request %s of %s for %s in [%s] %s\n", name, csr.Spec.Username, subject, groups, strings.ToLower(conditionType))
+	return nil
+}
+
+func certs(cmd *cobra.Command, args []string) {
+	op := strings.ToLower(args[0])
+	var name string
+	if len(args) > 1 {
+		name = args[1]
+	}
+	if name == "" && (op == "issue" || op == "approve" || op == "deny") {
+		fmt.Printf("kubectl certs %s needs a name\n", op)
+		return
+	}
+
+	var err error
+	switch op {
+	case "init":
+		err = initCerts(certsHosts)
+	case "issue":
+		err = issueCert(name, certsGroups, certsOutputDir)
+	case "list":
+		err = listCSRs()
+	case "approve":
+		err = setCSRCondition(name, apiObject.CertificateApproved)
+	case "deny":
+		err = setCSRCondition(name, apiObject.CertificateDenied)
+	default:
+		err = fmt.Errorf("invalid operation \"%s\", acceptable operation is init, issue, list, approve or deny", op)
+	}
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"gopkg.in/yaml.v3"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/url"
+	"minik8s/util/httputil"
+	"path"
+	"strings"
+)
+
+// Kinds registered by a CustomResourceDefinition have no code in kubectl, they
+// are found by the kind, plural or singular name in their definition.
+
+func crdTbl() table.Table {
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	tbl := table.New("Name", "Kind", "Group", "Version", "Scope")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	return tbl
+}
+
+func customObjectTbl() table.Table {
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	tbl := table.New("Name", "UID", "Resource Version")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	return tbl
+}
+
+func getCRDsFromApiServer() (crds []*apiObject.CustomResourceDefinition, err error) {
+	err = getList(url.Prefix+url.CRDURL, &crds)
+	return
+}
+
+// findCRD returns the definition whose kind, plural or singular is name, or
+// nil if there is none.
+func findCRD(name string) (*apiObject.CustomResourceDefinition, error) {
+	crds, err := getCRDsFromApiServer()
+	if err != nil {
+		return nil, err
+	}
+	for _, crd := range crds {
+		names := crd.Spec.Names
+		if strings.EqualFold(name, names.Kind) || strings.EqualFold(name, names.Plural) || strings.EqualFold(name, names.Singular) {
+			return crd, nil
+		}
+	}
+	return nil, nil
+}
+
+func customResourceURL(crd *apiObject.CustomResourceDefinition) string {
+	return url.Prefix + path.Join(url.CustomResourceURL, crd.Spec.Group, crd.Spec.Version, crd.Spec.Names.Plural) + "/"
+}
+
+func customObjectURL(crd *apiObject.CustomResourceDefinition, fullName string) string {
+	namespace, name := parseName(fullName)
+	if !crd.Namespaced() {
+		return customResourceURL(crd) + name
+	}
+	return customResourceURL(crd) + path.Join(namespace, name)
+}
+
+func customObjectName(obj *apiObject.CustomObject) string {
+	return path.Join(obj.Metadata.Namespace, obj.Metadata.Name)
+}
+
+func applyCustomObject(content []byte) error {
+	obj := apiObject.CustomObject{}
+	if err := yaml.Unmarshal(content, &obj); err != nil {
+		return err
+	}
+	crd, err := findCRD(obj.Kind)
+	if err != nil {
+		return err
+	}
+	if crd == nil {
+		return fmt.Errorf("unknown kind \"%s\", apply its CustomResourceDefinition first", obj.Kind)
+	}
+	fullName := obj.Metadata.Name
+	if obj.Metadata.Namespace != "" {
+		fullName = path.Join(obj.Metadata.Namespace, fullName)
+	}
+	applyObject(obj.Kind, customObjectURL(crd, fullName), &obj)
+	return nil
+}
+
+func printCRDs() error {
+	crds, err := getCRDsFromApiServer()
+	if err != nil {
+		return err
+	}
+
+	tbl := crdTbl()
+	for _, crd := range crds {
+		tbl.AddRow(crd.Name(), crd.Spec.Names.Kind, crd.Spec.Group, crd.Spec.Version, crd.Spec.Scope)
+	}
+	tbl.Print()
+	return nil
+}
+
+func printCustomObjects(crd *apiObject.CustomResourceDefinition) error {
+	var objs []*apiObject.CustomObject
+	if err := getList(customResourceURL(crd), &objs); err != nil {
+		return err
+	}
+
+	tbl := customObjectTbl()
+	for _, obj := range objs {
+		tbl.AddRow(customObjectName(obj), obj.Metadata.UID, obj.Metadata.ResourceVersion)
+	}
+	tbl.Print()
+	return nil
+}
+
+func printSpecifiedCustomObject(crd *apiObject.CustomResourceDefinition, fullName string) error {
+	var obj *apiObject.CustomObject
+	if err := httputil.GetAndUnmarshal(customObjectURL(crd, fullName), &obj); err != nil {
+		return err
+	}
+	if obj == nil {
+		return fmt.Errorf("no such %s", crd.Spec.Names.Kind)
+	}
+
+	tbl := customObjectTbl()
+	tbl.AddRow(customObjectName(obj), obj.Metadata.UID, obj.Metadata.ResourceVersion)
+	tbl.Print()
+	fields, _ := yaml.Marshal(obj.Fields)
+	fmt.Print(string(fields))
+	return nil
+}
+
+// printCustomResource serves kubectl get for a custom kind, it returns false
+// if there is no such kind.
+func printCustomResource(apiObjectType, name string) (bool, error) {
+	crd, err := findCRD(apiObjectType)
+	if err != nil || crd == nil {
+		return false, err
+	}
+	if name == "" {
+		return true, printCustomObjects(crd)
+	}
+	return true, printSpecifiedCustomObject(crd, name)
+}
+
+func deleteSpecifiedCRD(name string) error {
+	return deleteWithPropagationPolicy(url.Prefix + path.Join(url.CRDURL, name))
+}
+
+// deleteCustomObject serves kubectl delete for a custom kind, it returns
+// false if there is no such kind.
+func deleteCustomObject(apiObjectType, fullName string) (bool, error) {
+	crd, err := findCRD(apiObjectType)
+	if err != nil || crd == nil {
+		return false, err
+	}
+	return true, deleteWithPropagationPolicy(customObjectURL(crd, fullName))
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"github.com/spf13/cobra"
+	"minik8s/apiObject"
 	"minik8s/apiserver/src/url"
 	"minik8s/util/httputil"
 	"path"
@@ -17,12 +18,43 @@ var deleteCmd = &cobra.Command{
 	Run:   del,
 }
 
-func deleteSpecifiedNode(namespace, name string) error {
-	resp := httputil.DeleteWithoutBody(url.Prefix + path.Join(url.NodeURL, namespace, name))
+var cascade string
+
+// withPropagationPolicy tells the api-server what to do with the dependents of
+// the object at URL, as chosen by --cascade.
+func withPropagationPolicy(URL string) (string, error) {
+	var policy string
+	switch strings.ToLower(cascade) {
+	case "", "background":
+		return URL, nil
+	case "foreground":
+		policy = apiObject.DeletePropagationForeground
+	case "orphan":
+		policy = apiObject.DeletePropagationOrphan
+	default:
+		return "", fmt.Errorf("invalid cascade \"%s\", acceptable cascade is background, foreground or orphan", cascade)
+	}
+	return URL + "?propagationPolicy=" + policy, nil
+}
+
+// deleteWithPropagationPolicy deletes an object that may have dependents
+func deleteWithPropagationPolicy(URL string) error {
+	URL, err := withPropagationPolicy(URL)
+	if err != nil {
+		return err
+	}
+	resp, err := httputil.Delete(URL)
+	if err != nil {
+		return err
+	}
 	fmt.Println(resp)
 	return nil
 }
 
+func deleteSpecifiedNode(namespace, name string) error {
+	return deleteWithPropagationPolicy(url.Prefix + path.Join(url.NodeURL, namespace, name))
+}
+
 func deleteSpecifiedPod(namespace, name string) error {
 	resp := httputil.DeleteWithoutBody(url.Prefix + path.Join(url.PodURL, namespace, name))
 	fmt.Println(resp)
@@ -30,33 +62,31 @@ func deleteSpecifiedPod(namespace, name string) error {
 }
 
 func deleteSpecifiedReplicaSet(namespace, name string) error {
-	resp := httputil.DeleteWithoutBody(url.Prefix + path.Join(url.ReplicaSetURL, namespace, name))
-	fmt.Println(resp)
-	return nil
+	return deleteWithPropagationPolicy(url.Prefix + path.Join(url.ReplicaSetURL, namespace, name))
+}
+
+func deleteSpecifiedDeployment(namespace, name string) error {
+	return deleteWithPropagationPolicy(url.Prefix + path.Join(url.DeploymentURL, namespace, name))
 }
 
 func deleteSpecifiedHPA(namespace, name string) error {
-	resp := httputil.DeleteWithoutBody(url.Prefix + path.Join(url.HPAURL, namespace, name))
-	fmt.Println(resp)
-	return nil
+	return deleteWithPropagationPolicy(url.Prefix + path.Join(url.HPAURL, namespace, name))
 }
 
 func deleteSpecifiedService(namespace, name string) error {
-	resp := httputil.DeleteWithoutBody(url.Prefix + path.Join(url.ServiceURL, namespace, name))
-	fmt.Println(resp)
-	return nil
+	return deleteWithPropagationPolicy(url.Prefix + path.Join(url.ServiceURL, namespace, name))
 }
 
 func deleteSpecifiedDNS(namespace, name string) error {
-	resp := httputil.DeleteWithoutBody(url.Prefix + path.Join(url.DNSURL, namespace, name))
-	fmt.Println(resp)
-	return nil
+	return deleteWithPropagationPolicy(url.Prefix + path.Join(url.DNSURL, namespace, name))
+}
+
+func deleteSpecifiedEvent(namespace, name string) error {
+	return deleteWithPropagationPolicy(url.Prefix + path.Join(url.EventURL, namespace, name))
 }
 
 func deleteSpecifiedGpuJob(namespace, name string) error {
-	resp := httputil.DeleteWithoutBody(url.Prefix + path.Join(url.GpuURL, namespace, name))
-	fmt.Println(resp)
-	return nil
+	return deleteWithPropagationPolicy(url.Prefix + path.Join(url.GpuURL, namespace, name))
 }
 
 func del(cmd *cobra.Command, args []string) {
@@ -72,6 +102,10 @@ func del(cmd *cobra.Command, args []string) {
 		err = deleteSpecifiedPod(namespace, name)
 	case "rs":
 		err = deleteSpecifiedReplicaSet(namespace, name)
+	case "deployment":
+		err = deleteSpecifiedDeployment(namespace, name)
+	case "event":
+		err = deleteSpecifiedEvent(namespace, name)
 	case "hpa":
 		err = deleteSpecifiedHPA(namespace, name)
 	case "service":
@@ -80,8 +114,27 @@ func del(cmd *cobra.Command, args []string) {
 		err = deleteSpecifiedDNS(namespace, name)
 	case "gpu":
 		err = deleteSpecifiedGpuJob(namespace, name)
+	case "ns", "namespace":
+		err = deleteSpecifiedNamespace(target)
+	case "mutatingwebhookconfiguration":
+		err = deleteWithPropagationPolicy(url.Prefix + url.MutatingWebhookURL + target)
+	case "validatingwebhookconfiguration":
+		err = deleteWithPropagationPolicy(url.Prefix + url.ValidatingWebhookURL + target)
+	case "role":
+		err = deleteWithPropagationPolicy(url.Prefix + path.Join(url.RoleURL, namespace, name))
+	case "clusterrole":
+		err = deleteWithPropagationPolicy(url.Prefix + url.ClusterRoleURL + target)
+	case "rolebinding":
+		err = deleteWithPropagationPolicy(url.Prefix + path.Join(url.RoleBindingURL, namespace, name))
+	case "clusterrolebinding":
+		err = deleteWithPropagationPolicy(url.Prefix + url.ClusterRoleBindingURL + target)
+	case "crd":
+		err = deleteSpecifiedCRD(target)
 	default:
-		err = fmt.Errorf("invalid api object type \"%s\", acceptable api object type is pod, service, etc", apiObjectType)
+		var found bool
+		if found, err = deleteCustomObject(apiObjectType, target); err == nil && !found {
+			err = fmt.Errorf("invalid api object type \"%s\", acceptable api object type is pod, service, etc", apiObjectType)
+		}
 	}
 	if err != nil {
 		fmt.Println(err.Error())
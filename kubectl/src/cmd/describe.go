@@ -31,6 +31,10 @@ func describe(cmd *cobra.Command, args []string) {
 	switch apiObjectType {
 	case "pod":
 		err = printSpecifiedPodDescription(name)
+	case "rs":
+		err = printSpecifiedReplicaSetDescription(name)
+	case "deployment":
+		err = printSpecifiedDeploymentDescription(name)
 	default:
 		fmt.Println("Invalid api object type!")
 		return
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 	"github.com/spf13/cobra"
-	"io/ioutil"
 	"minik8s/apiObject"
 	"minik8s/apiserver/src/url"
 	"minik8s/util/httputil"
@@ -28,9 +27,10 @@ func updateFuncToApiServer(function *apiObject.Function) {
 	URL := url.Prefix + path.Join(url.FuncURL, function.Name)
 	if resp, err := httputil.PutJson(URL, function); err != nil {
 		fmt.Println(err.Error())
-	} else if content, err := ioutil.ReadAll(resp.Body); err == nil {
-		defer resp.Body.Close()
-		fmt.Println(string(content))
+	} else if content, err := httputil.ReadResponse(resp); err != nil {
+		fmt.Println(err.Error())
+	} else {
+		fmt.Println(content)
 	}
 }
 
@@ -38,9 +38,10 @@ func addFuncToApiServer(function *apiObject.Function) {
 	URL := url.Prefix + url.FuncURL
 	if resp, err := httputil.PostJson(URL, function); err != nil {
 		fmt.Println(err.Error())
-	} else if content, err := ioutil.ReadAll(resp.Body); err == nil {
-		defer resp.Body.Close()
-		fmt.Println(string(content))
+	} else if content, err := httputil.ReadResponse(resp); err != nil {
+		fmt.Println(err.Error())
+	} else {
+		fmt.Println(content)
 	}
 }
 
@@ -6,6 +6,11 @@ import (
 	"strings"
 )
 
+var (
+	labelSelector string
+	fieldSelector string
+)
+
 var getCmd = &cobra.Command{
 	Use:   "get",
 	Short: "Kubectl get is used to get brief information of the api object with given unique name",
@@ -38,6 +43,18 @@ func get(cmd *cobra.Command, args []string) {
 		err = printSpecifiedReplicaSetStatus(name)
 	case "rss":
 		err = printReplicaSetStatuses()
+	case "deployment":
+		err = printSpecifiedDeployment(name)
+	case "deployments":
+		err = printDeployments()
+	case "event":
+		err = printSpecifiedEvent(name)
+	case "events":
+		err = printEvents()
+	case "lease":
+		err = printSpecifiedLease(name)
+	case "leases":
+		err = printLeases()
 	case "hpas":
 		err = printHPAStatuses()
 	case "hpa":
@@ -62,8 +79,21 @@ func get(cmd *cobra.Command, args []string) {
 		err = printSpecifiedGpuJob(name)
 	case "gpus":
 		err = printGpuJobs()
+	case "ns", "namespace":
+		if name == "" {
+			err = printNamespaces()
+		} else {
+			err = printSpecifiedNamespace(name)
+		}
+	case "namespaces":
+		err = printNamespaces()
+	case "crds":
+		err = printCRDs()
 	default:
-		err = fmt.Errorf("invalid api object type \"%s\", acceptable api object type is pod, service, etc", apiObjectType)
+		var found bool
+		if found, err = printCustomResource(apiObjectType, name); err == nil && !found {
+			err = fmt.Errorf("invalid api object type \"%s\", acceptable api object type is pod, service, etc", apiObjectType)
+		}
 	}
 	if err != nil {
 		fmt.Println(err.Error())
@@ -7,8 +7,11 @@ import (
 	"minik8s/apiObject"
 	"minik8s/apiserver/src/url"
 	"minik8s/entity"
+	"minik8s/util/apiutil"
 	"minik8s/util/httputil"
+	netURL "net/url"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -32,6 +35,15 @@ func replicaSetStatusTbl() table.Table {
 	return tbl
 }
 
+func deploymentTbl() table.Table {
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	tbl := table.New("Name", "UID", "Ready", "Up-to-date", "Available", "Revision", "Strategy", "Paused")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	return tbl
+}
+
 func workflowResultTbl() table.Table {
 	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
 	columnFmt := color.New(color.FgYellow).SprintfFunc()
@@ -77,6 +89,24 @@ func nodeStatusTbl() table.Table {
 	return tbl
 }
 
+func eventTbl() table.Table {
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	tbl := table.New("Last Seen", "Type", "Reason", "Object", "Message", "Count", "Source")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	return tbl
+}
+
+func leaseTbl() table.Table {
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	tbl := table.New("Name", "Holder", "Acquired", "Renewed", "Transitions")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	return tbl
+}
+
 func podStatusLogTbl() table.Table {
 	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
 	columnFmt := color.New(color.FgYellow).SprintfFunc()
@@ -86,6 +116,27 @@ func podStatusLogTbl() table.Table {
 	return tbl
 }
 
+// listURL adds the selectors of kubectl get -l and --field-selector to URL
+func listURL(URL string) string {
+	query := netURL.Values{}
+	if labelSelector != "" {
+		query.Set("labelSelector", labelSelector)
+	}
+	if fieldSelector != "" {
+		query.Set("fieldSelector", fieldSelector)
+	}
+	if len(query) == 0 {
+		return URL
+	}
+	return URL + "?" + query.Encode()
+}
+
+// getList gets all the objects of a list URL, a page at a time
+func getList(URL string, target interface{}) error {
+	_, err := apiutil.List(listURL(URL), target)
+	return err
+}
+
 func getPodFromApiServer(fullName string) (pod *entity.PodStatus, err error) {
 	namespace, name := parseName(fullName)
 	URL := url.Prefix + path.Join(url.PodURL, "status", namespace, name)
@@ -94,12 +145,12 @@ func getPodFromApiServer(fullName string) (pod *entity.PodStatus, err error) {
 }
 
 func getPodsFromApiServer() (pods []*entity.PodStatus, err error) {
-	err = httputil.GetAndUnmarshal(url.Prefix+url.PodURL, &pods)
+	err = getList(url.Prefix+url.PodURL, &pods)
 	return
 }
 
 func getNodesFromApiServer() (nodes []*entity.NodeStatus, err error) {
-	err = httputil.GetAndUnmarshal(url.Prefix+url.NodeURL, &nodes)
+	err = getList(url.Prefix+url.NodeURL, &nodes)
 	return
 }
 
@@ -111,7 +162,7 @@ func getNodeFromApiServer(fullName string) (node *entity.NodeStatus, err error)
 }
 
 func getReplicaSetsFromApiServer() (replicaSets []*entity.ReplicaSetStatus, err error) {
-	err = httputil.GetAndUnmarshal(url.Prefix+url.ReplicaSetURL, &replicaSets)
+	err = getList(url.Prefix+url.ReplicaSetURL, &replicaSets)
 	return
 }
 
@@ -123,7 +174,7 @@ func getReplicaSetFromApiServer(fullName string) (replicaSet *entity.ReplicaSetS
 }
 
 func getHPAsFromApiServer() (hpas []*entity.HPAStatus, err error) {
-	err = httputil.GetAndUnmarshal(url.Prefix+url.HPAURL, &hpas)
+	err = getList(url.Prefix+url.HPAURL, &hpas)
 	return
 }
 
@@ -192,7 +243,7 @@ func printSpecifiedPodDescription(name string) error {
 	tbl.AddRow(fullName, podStatus.ID, podStatus.Lifecycle.String(), podStatus.SyncTime.Format(time.RFC3339), podStatus.Error)
 	tbl.Print()
 
-	return nil
+	return printEventsOf("Pod", name)
 }
 
 func printSpecifiedNodeStatus(name string) error {
@@ -326,6 +377,178 @@ func printSpecifiedReplicaSetStatus(name string) error {
 	return nil
 }
 
+func printSpecifiedReplicaSetDescription(name string) error {
+	if err := printSpecifiedReplicaSetStatus(name); err != nil {
+		return err
+	}
+	return printEventsOf("ReplicaSet", name)
+}
+
+func addDeploymentRow(tbl table.Table, deployment *apiObject.Deployment) {
+	fullName := path.Join(deployment.Namespace(), deployment.Name())
+	ready := strconv.Itoa(deployment.Status.AvailableReplicas) + "/" + strconv.Itoa(deployment.Replicas())
+	tbl.AddRow(
+		fullName,
+		deployment.UID(),
+		ready,
+		deployment.Status.UpdatedReplicas,
+		deployment.Status.AvailableReplicas,
+		deployment.Status.Revision,
+		deployment.Spec.Strategy.Type,
+		deployment.Spec.Paused,
+	)
+}
+
+func printDeployments() error {
+	deployments, err := getDeploymentsFromApiServer()
+	if err != nil {
+		return err
+	}
+
+	tbl := deploymentTbl()
+	for i := range deployments {
+		addDeploymentRow(tbl, &deployments[i])
+	}
+	tbl.Print()
+	return nil
+}
+
+func printSpecifiedDeployment(name string) error {
+	deployment, err := getDeploymentFromApiServer(name)
+	if err != nil {
+		return err
+	}
+	if deployment == nil {
+		return fmt.Errorf("no such deployment")
+	}
+
+	tbl := deploymentTbl()
+	addDeploymentRow(tbl, deployment)
+	tbl.Print()
+	return nil
+}
+
+func printSpecifiedDeploymentDescription(name string) error {
+	if err := printSpecifiedDeployment(name); err != nil {
+		return err
+	}
+	return printEventsOf("Deployment", name)
+}
+
+func addEventRow(tbl table.Table, event *apiObject.Event) {
+	source := event.Source.Component
+	if event.Source.Host != "" {
+		source += ", " + event.Source.Host
+	}
+	tbl.AddRow(
+		time.Since(event.LastTimestamp).Round(time.Second),
+		event.Type,
+		event.Reason,
+		event.InvolvedObject.String(),
+		event.Message,
+		event.Count,
+		source,
+	)
+}
+
+// sortEvents sorts the events from the one that last happened the earliest
+func sortEvents(events []apiObject.Event) {
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].LastTimestamp.Before(events[j].LastTimestamp)
+	})
+}
+
+func printEvents() error {
+	events, err := getEventsFromApiServer()
+	if err != nil {
+		return err
+	}
+
+	sortEvents(events)
+	tbl := eventTbl()
+	for i := range events {
+		addEventRow(tbl, &events[i])
+	}
+	tbl.Print()
+	return nil
+}
+
+func printSpecifiedEvent(name string) error {
+	event, err := getEventFromApiServer(name)
+	if err != nil {
+		return err
+	}
+	if event == nil {
+		return fmt.Errorf("no such event")
+	}
+
+	tbl := eventTbl()
+	addEventRow(tbl, event)
+	tbl.Print()
+	return nil
+}
+
+// printEventsOf prints the events of the object of the given kind, for
+// kubectl describe.
+func printEventsOf(kind, fullName string) error {
+	events, err := getEventsOfObjectFromApiServer(kind, fullName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Events:")
+	if len(events) == 0 {
+		fmt.Println("<none>")
+		return nil
+	}
+	sortEvents(events)
+	tbl := eventTbl()
+	for i := range events {
+		addEventRow(tbl, &events[i])
+	}
+	tbl.Print()
+	return nil
+}
+
+func addLeaseRow(tbl table.Table, lease *apiObject.Lease) {
+	tbl.AddRow(
+		lease.Name(),
+		lease.Spec.HolderIdentity,
+		time.Since(lease.Spec.AcquireTime).Round(time.Second),
+		time.Since(lease.Spec.RenewTime).Round(time.Second),
+		lease.Spec.LeaseTransitions,
+	)
+}
+
+func printLeases() error {
+	leases, err := getLeasesFromApiServer()
+	if err != nil {
+		return err
+	}
+
+	tbl := leaseTbl()
+	for i := range leases {
+		addLeaseRow(tbl, &leases[i])
+	}
+	tbl.Print()
+	return nil
+}
+
+func printSpecifiedLease(name string) error {
+	lease, err := getLeaseFromApiServer(name)
+	if err != nil {
+		return err
+	}
+	if lease == nil {
+		return fmt.Errorf("no such lease")
+	}
+
+	tbl := leaseTbl()
+	addLeaseRow(tbl, lease)
+	tbl.Print()
+	return nil
+}
+
 func printHPAStatuses() error {
 	hpaStatuses, err := getHPAsFromApiServer()
 	if err != nil {
@@ -408,7 +631,55 @@ func getServiceFromApiServer(fullName string) (service *apiObject.Service, err e
 
 func getServicesFromApiServer() (services []apiObject.Service, err error) {
 	URL := url.Prefix + url.ServiceURL
-	err = httputil.GetAndUnmarshal(URL, &services)
+	err = getList(URL, &services)
+	return
+}
+
+func getDeploymentFromApiServer(fullName string) (deployment *apiObject.Deployment, err error) {
+	namespace, name := parseName(fullName)
+	URL := url.Prefix + path.Join(url.DeploymentURL, namespace, name)
+	err = httputil.GetAndUnmarshal(URL, &deployment)
+	return
+}
+
+func getDeploymentsFromApiServer() (deployments []apiObject.Deployment, err error) {
+	URL := url.Prefix + url.DeploymentURL
+	err = getList(URL, &deployments)
+	return
+}
+
+func getEventFromApiServer(fullName string) (event *apiObject.Event, err error) {
+	namespace, name := parseName(fullName)
+	URL := url.Prefix + path.Join(url.EventURL, namespace, name)
+	err = httputil.GetAndUnmarshal(URL, &event)
+	return
+}
+
+func getEventsFromApiServer() (events []apiObject.Event, err error) {
+	URL := url.Prefix + url.EventURL
+	err = getList(URL, &events)
+	return
+}
+
+// getEventsOfObjectFromApiServer gets the events of the object of the given
+// kind, whatever the selectors of kubectl get are.
+func getEventsOfObjectFromApiServer(kind, fullName string) (events []apiObject.Event, err error) {
+	namespace, name := parseName(fullName)
+	query := netURL.Values{}
+	query.Set("fieldSelector", fmt.Sprintf("involvedObject.kind=%s,involvedObject.name=%s,metadata.namespace=%s", kind, name, namespace))
+	_, err = apiutil.List(url.Prefix+url.EventURL+"?"+query.Encode(), &events)
+	return
+}
+
+func getLeaseFromApiServer(name string) (lease *apiObject.Lease, err error) {
+	URL := url.Prefix + path.Join(url.LeaseURL, name)
+	err = httputil.GetAndUnmarshal(URL, &lease)
+	return
+}
+
+func getLeasesFromApiServer() (leases []apiObject.Lease, err error) {
+	URL := url.Prefix + url.LeaseURL
+	err = getList(URL, &leases)
 	return
 }
 
@@ -421,7 +692,7 @@ func getDnsFromApiServer(fullName string) (dns *apiObject.Dns, err error) {
 
 func getDnsesFromApiServer() (dnses []apiObject.Dns, err error) {
 	URL := url.Prefix + url.DNSURL
-	err = httputil.GetAndUnmarshal(URL, &dnses)
+	err = getList(URL, &dnses)
 	return
 }
 
@@ -434,7 +705,7 @@ func getWorkflowResultFromApiServer(fullName string) (result *entity.FunctionTri
 
 func getWorkflowResultsFromApiServer() (results []*entity.FunctionTriggerResult, err error) {
 	URL := url.Prefix + url.WorkflowURL
-	err = httputil.GetAndUnmarshal(URL, &results)
+	err = getList(URL, &results)
 	return
 }
 
@@ -446,7 +717,7 @@ func getFunctionStatusFromApiServer(name string) (function *entity.FunctionStatu
 
 func getFunctionStatusesFromApiServer() (functions []*entity.FunctionStatus, err error) {
 	URL := url.Prefix + url.FuncURL
-	err = httputil.GetAndUnmarshal(URL, &functions)
+	err = getList(URL, &functions)
 	return
 }
 
@@ -459,7 +730,7 @@ func getGpuJobStatusFromApiServer(fullName string) (gpu *entity.GpuJobStatus, er
 
 func getGpuJobStatusesFromApiServer() (gpus []*entity.GpuJobStatus, err error) {
 	URL := url.Prefix + url.GpuURL
-	err = httputil.GetAndUnmarshal(URL, &gpus)
+	err = getList(URL, &gpus)
 	return
 }
 
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 	"github.com/spf13/cobra"
-	"io/ioutil"
 	"minik8s/apiObject"
 	"minik8s/apiserver/src/url"
 	"minik8s/util/httputil"
@@ -33,13 +32,12 @@ func labelSpecifiedNode(fullName string, labels apiObject.Labels) {
 		return
 	}
 
-	body := resp.Body
-	defer body.Close()
-	content, err := ioutil.ReadAll(body)
+	content, err := httputil.ReadResponse(resp)
 	if err != nil {
-		logger.Error(err.Error())
+		fmt.Println(err.Error())
+		return
 	}
-	fmt.Printf("Label node %s with %v and get resp: %s\n", name, labels, string(content))
+	fmt.Printf("Label node %s with %v and get resp: %s\n", name, labels, content)
 }
 
 func parseLabels(args []string) apiObject.Labels {
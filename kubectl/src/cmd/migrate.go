@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"minik8s/apiserver/src/url"
+	"minik8s/entity"
+	"minik8s/util/httputil"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Kubectl migrate is used to rewrite the stored objects in the storage version of their kind",
+	Long: `Kubectl migrate is used to rewrite the stored objects in the storage version of their kind, e.g. after it changed.
+For example: kubectl migrate`,
+	Args: cobra.NoArgs,
+	Run:  migrate,
+}
+
+func migrate(cmd *cobra.Command, args []string) {
+	resp, err := httputil.PostJson(url.Prefix+url.MigrateURL, nil)
+	var content string
+	if err == nil {
+		content, err = httputil.ReadResponse(resp)
+	}
+	var migrations []entity.StorageMigration
+	if err == nil {
+		err = json.Unmarshal([]byte(content), &migrations)
+	}
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	total := 0
+	for _, migration := range migrations {
+		if migration.Migrated > 0 {
+			fmt.Printf("migrated %d %s to %s\n", migration.Migrated, migration.Kind, migration.StorageVersion)
+			total += migration.Migrated
+		}
+	}
+	if total == 0 {
+		fmt.Println("every object is stored in the storage version already")
+	}
+}
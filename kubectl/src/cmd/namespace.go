@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/url"
+	"minik8s/util/httputil"
+)
+
+func namespaceTbl() table.Table {
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	tbl := table.New("Name", "UID", "Status")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	return tbl
+}
+
+func getNamespaceFromApiServer(name string) (ns *apiObject.Namespace, err error) {
+	err = httputil.GetAndUnmarshal(url.Prefix+url.NamespaceURL+name, &ns)
+	return
+}
+
+func printNamespaces() error {
+	var namespaces []*apiObject.Namespace
+	if err := getList(url.Prefix+url.NamespaceURL, &namespaces); err != nil {
+		return err
+	}
+
+	tbl := namespaceTbl()
+	for _, ns := range namespaces {
+		tbl.AddRow(ns.Name(), ns.UID(), ns.Phase())
+	}
+	tbl.Print()
+	return nil
+}
+
+func printSpecifiedNamespace(name string) error {
+	ns, err := getNamespaceFromApiServer(name)
+	if err != nil {
+		return err
+	}
+	if ns == nil {
+		return fmt.Errorf("no such namespace %s", name)
+	}
+
+	tbl := namespaceTbl()
+	tbl.AddRow(ns.Name(), ns.UID(), ns.Phase())
+	tbl.Print()
+	return nil
+}
+
+// deleteSpecifiedNamespace only starts the deletion, the namespace stays
+// Terminating until everything in it is deleted.
+func deleteSpecifiedNamespace(name string) error {
+	resp, err := httputil.Delete(url.Prefix + url.NamespaceURL + name)
+	if err != nil {
+		return err
+	}
+	fmt.Println(resp)
+	if ns, err := getNamespaceFromApiServer(name); err == nil && ns != nil {
+		fmt.Printf("namespace %s is %s\n", name, ns.Phase())
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"minik8s/apiserver/src/contentType"
+	"minik8s/apiserver/src/url"
+	"minik8s/util/httputil"
+	"path"
+	"strings"
+)
+
+var (
+	patchContent    string
+	patchType       string
+	resourceVersion string
+)
+
+var patchCmd = &cobra.Command{
+	Use:   "patch",
+	Short: "Kubectl patch is used to change some fields of an api object given its name",
+	Long: `Kubectl patch is used to change some fields of an api object given its name, with a JSON merge patch or a JSON patch.
+For example: kubectl patch rs nginx -p '{"Spec":{"Replicas":3}}'; kubectl patch rs nginx --type json -p '[{"op":"replace","path":"/Spec/Replicas","value":3}]'`,
+	Args: cobra.ExactValidArgs(2),
+	Run:  patch,
+}
+
+// patchURL is the URL of the object target of type apiObjectType, it is empty
+// if there is no such type.
+func patchURL(apiObjectType, target string) (string, error) {
+	namespace, name := parseName(target)
+	switch apiObjectType {
+	case "node":
+		return url.Prefix + path.Join(url.NodeURL, namespace, name), nil
+	case "pod":
+		return url.Prefix + path.Join(url.PodURL, namespace, name), nil
+	case "rs":
+		return url.Prefix + path.Join(url.ReplicaSetURL, namespace, name), nil
+	case "deployment":
+		return url.Prefix + path.Join(url.DeploymentURL, namespace, name), nil
+	case "hpa":
+		return url.Prefix + path.Join(url.HPAURL, namespace, name), nil
+	case "service":
+		return url.Prefix + path.Join(url.ServiceURL, namespace, name), nil
+	case "dns":
+		return url.Prefix + path.Join(url.DNSURL, namespace, name), nil
+	case "gpu":
+		return url.Prefix + path.Join(url.GpuURL, namespace, name), nil
+	case "ns", "namespace":
+		return url.Prefix + url.NamespaceURL + target, nil
+	case "mutatingwebhookconfiguration":
+		return url.Prefix + url.MutatingWebhookURL + target, nil
+	case "validatingwebhookconfiguration":
+		return url.Prefix + url.ValidatingWebhookURL + target, nil
+	case "role":
+		return url.Prefix + path.Join(url.RoleURL, namespace, name), nil
+	case "clusterrole":
+		return url.Prefix + url.ClusterRoleURL + target, nil
+	case "rolebinding":
+		return url.Prefix + path.Join(url.RoleBindingURL, namespace, name), nil
+	case "clusterrolebinding":
+		return url.Prefix + url.ClusterRoleBindingURL + target, nil
+	case "crd":
+		return url.Prefix + path.Join(url.CRDURL, target), nil
+	}
+	crd, err := findCRD(apiObjectType)
+	if err != nil || crd == nil {
+		return "", err
+	}
+	return customObjectURL(crd, target), nil
+}
+
+func patch(cmd *cobra.Command, args []string) {
+	apiObjectType := strings.ToLower(args[0])
+	target := args[1]
+
+	var patchContentType string
+	switch strings.ToLower(patchType) {
+	case "merge":
+		patchContentType = contentType.MergePatch
+	case "json":
+		patchContentType = contentType.JsonPatch
+	default:
+		fmt.Printf("invalid patch type \"%s\", acceptable patch type is merge or json\n", patchType)
+		return
+	}
+	if patchContent == "" {
+		fmt.Println("the patch is empty, give it with -p")
+		return
+	}
+
+	URL, err := patchURL(apiObjectType, target)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	} else if URL == "" {
+		fmt.Printf("invalid api object type \"%s\", acceptable api object type is rs, service, etc\n", apiObjectType)
+		return
+	}
+	if resourceVersion != "" {
+		URL += "?resourceVersion=" + resourceVersion
+	}
+
+	if _, err = httputil.Patch(URL, patchContentType, []byte(patchContent)); err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	fmt.Printf("%s %s patched\n", apiObjectType, target)
+}
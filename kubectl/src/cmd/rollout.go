@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/contentType"
+	"minik8s/apiserver/src/url"
+	"minik8s/entity"
+	"minik8s/util/apiutil"
+	"minik8s/util/httputil"
+	netURL "net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var toRevision int
+
+var rolloutCmd = &cobra.Command{
+	Use:   "rollout",
+	Short: "Kubectl rollout is used to manage the rollout of a deployment",
+	Long: `Kubectl rollout is used to manage the rollout of a deployment, the operation is one of status, history, undo, pause and resume.
+For example: kubectl rollout status deployment nginx; kubectl rollout undo deployment nginx --to-revision 1`,
+	Args: cobra.ExactArgs(3),
+	Run:  rollout,
+}
+
+// rolloutPollInterval is how often kubectl rollout status checks the
+// deployment.
+const rolloutPollInterval = time.Second
+
+// revisionedReplicaSet is a replicaSet of a deployment and its revision
+type revisionedReplicaSet struct {
+	apiObject.ReplicaSet
+	revision int
+}
+
+func rolloutHistoryTbl() table.Table {
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	tbl := table.New("Revision", "ReplicaSet", "Replicas", "Images")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	return tbl
+}
+
+// getDeploymentReplicaSets gets the replicaSets of the deployment, from the
+// oldest revision to the latest.
+func getDeploymentReplicaSets(deployment *apiObject.Deployment) ([]revisionedReplicaSet, error) {
+	query := netURL.Values{}
+	query.Set("labelSelector", apiObject.DeploymentLabel+"="+deployment.Name())
+	query.Set("fieldSelector", "metadata.namespace="+deployment.Namespace())
+	var statuses []*entity.ReplicaSetStatus
+	if _, err := apiutil.List(url.Prefix+url.ReplicaSetURL+"?"+query.Encode(), &statuses); err != nil {
+		return nil, err
+	}
+
+	var history []revisionedReplicaSet
+	for _, status := range statuses {
+		rs := apiObject.ReplicaSet{}
+		if err := httputil.GetAndUnmarshal(url.Prefix+path.Join(url.ReplicaSetURL, status.Namespace, status.Name), &rs); err != nil {
+			if httputil.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		revision, _ := strconv.Atoi(rs.Annotations()[apiObject.RevisionAnnotation])
+		history = append(history, revisionedReplicaSet{ReplicaSet: rs, revision: revision})
+	}
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].revision < history[j].revision
+	})
+	return history, nil
+}
+
+func imagesOf(template apiObject.PodTemplateSpec) string {
+	var images []string
+	for _, container := range template.Spec.Containers {
+		images = append(images, container.Image)
+	}
+	return strings.Join(images, ",")
+}
+
+// rolledOut tells whether all the replicas of the deployment run its current
+// template and are available, and describes the progress if not.
+func rolledOut(deployment *apiObject.Deployment) (bool, string) {
+	status := deployment.Status
+	replicas := deployment.Replicas()
+	switch {
+	case deployment.Spec.Paused:
+		return false, "the deployment is paused, resume it to roll out"
+	case status.TemplateHash != deployment.TemplateHash():
+		return false, "waiting for the rollout to start"
+	case status.UpdatedReplicas < replicas:
+		return false, fmt.Sprintf("%d of %d new replicas have been updated", status.UpdatedReplicas, replicas)
+	case status.Replicas > status.UpdatedReplicas:
+		return false, fmt.Sprintf("%d old replicas are pending termination", status.Replicas-status.UpdatedReplicas)
+	case status.AvailableReplicas != replicas:
+		return false, fmt.Sprintf("%d of %d updated replicas are available", status.AvailableReplicas, replicas)
+	}
+	return true, "successfully rolled out"
+}
+
+func rolloutStatus(fullName string) error {
+	last := ""
+	for {
+		deployment, err := getDeploymentFromApiServer(fullName)
+		if err != nil {
+			return err
+		}
+		done, progress := rolledOut(deployment)
+		if progress != last {
+			fmt.Printf("deployment %s: %s\n", fullName, progress)
+			last = progress
+		}
+		if done || deployment.Spec.Paused {
+			return nil
+		}
+		time.Sleep(rolloutPollInterval)
+	}
+}
+
+func rolloutHistory(fullName string) error {
+	deployment, err := getDeploymentFromApiServer(fullName)
+	if err != nil {
+		return err
+	}
+	history, err := getDeploymentReplicaSets(deployment)
+	if err != nil {
+		return err
+	}
+
+	tbl := rolloutHistoryTbl()
+	for _, rs := range history {
+		tbl.AddRow(rs.revision, rs.Name(), rs.Replicas(), imagesOf(rs.Template()))
+	}
+	tbl.Print()
+	return nil
+}
+
+// rolloutUndo rolls the deployment back to the template of a revision, the
+// one before the current one if revision is 0.
+func rolloutUndo(fullName string, revision int) error {
+	deployment, err := getDeploymentFromApiServer(fullName)
+	if err != nil {
+		return err
+	}
+	history, err := getDeploymentReplicaSets(deployment)
+	if err != nil {
+		return err
+	}
+
+	var target *revisionedReplicaSet
+	for i := len(history) - 1; i >= 0; i-- {
+		rs := &history[i]
+		if revision == 0 && rs.Labels()[apiObject.PodTemplateHashLabel] != deployment.TemplateHash() ||
+			revision != 0 && rs.revision == revision {
+			target = rs
+			break
+		}
+	}
+	if target == nil {
+		if revision == 0 {
+			return fmt.Errorf("no revision to roll back to")
+		}
+		return fmt.Errorf("no revision %d", revision)
+	}
+	if apiObject.TemplateHash(target.Template()) == deployment.TemplateHash() {
+		fmt.Printf("deployment %s skipped rollback, revision %d is the current template\n", fullName, target.revision)
+		return nil
+	}
+
+	value, _ := json.Marshal(target.Template())
+	patch, _ := json.Marshal([]map[string]interface{}{
+		{"op": "replace", "path": "/Spec/Template", "value": json.RawMessage(value)},
+	})
+	URL := url.Prefix + path.Join(url.DeploymentURL, deployment.Namespace(), deployment.Name())
+	if _, err = httputil.Patch(URL+"?resourceVersion="+deployment.Metadata.ResourceVersion, contentType.JsonPatch, patch); err != nil {
+		return err
+	}
+	fmt.Printf("deployment %s rolled back to revision %d\n", fullName, target.revision)
+	return nil
+}
+
+func rolloutPause(fullName string, paused bool) error {
+	namespace, name := parseName(fullName)
+	patch := fmt.Sprintf(`{"Spec":{"Paused":%t}}`, paused)
+	if _, err := httputil.Patch(url.Prefix+path.Join(url.DeploymentURL, namespace, name), contentType.MergePatch, []byte(patch)); err != nil {
+		return err
+	}
+	if paused {
+		fmt.Printf("deployment %s paused\n", fullName)
+	} else {
+		fmt.Printf("deployment %s resumed\n", fullName)
+	}
+	return nil
+}
+
+func rollout(cmd *cobra.Command, args []string) {
+	op := strings.ToLower(args[0])
+	apiObjectType := strings.ToLower(args[1])
+	fullName := args[2]
+	if apiObjectType != "deployment" {
+		fmt.Printf("invalid api object type \"%s\", only a deployment is rolled out\n", apiObjectType)
+		return
+	}
+
+	var err error
+	switch op {
+	case "status":
+		err = rolloutStatus(fullName)
+	case "history":
+		err = rolloutHistory(fullName)
+	case "undo":
+		err = rolloutUndo(fullName, toRevision)
+	case "pause":
+		err = rolloutPause(fullName, true)
+	case "resume":
+		err = rolloutPause(fullName, false)
+	default:
+		err = fmt.Errorf("invalid operation \"%s\", acceptable operation is status, history, undo, pause or resume", op)
+	}
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
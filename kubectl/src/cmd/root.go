@@ -1,9 +1,16 @@
 package cmd
 
 import (
+	"flag"
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"minik8s/controller/src/controller/hpa"
+	"minik8s/global"
+	"minik8s/util/configutil"
+	"minik8s/util/httputil"
+	"os"
+	"path"
 )
 
 func Execute() {
@@ -12,10 +19,23 @@ func Execute() {
 	}
 }
 
-var filePath string
+var (
+	filePath    string
+	validate    bool
+	credentials string
+	// configFlags holds the flags of the config, of which kubectl only
+	// offers --config and --api-server.
+	configFlags = flag.NewFlagSet("config", flag.ContinueOnError)
+)
 
 func init() {
+	configutil.AddFlags(configFlags)
+	rootCmd.PersistentFlags().AddGoFlag(configFlags.Lookup("config"))
+	rootCmd.PersistentFlags().AddGoFlag(configFlags.Lookup("api-server"))
+	rootCmd.PersistentFlags().StringVarP(&credentials, "credentials", "", "", "credentials file, $HOME/.minik8s/config or else "+httputil.CredentialsFile("admin")+" by default")
+
 	applyCmd.Flags().StringVarP(&filePath, "filePath", "f", "", "filePath of api object yaml file")
+	applyCmd.Flags().BoolVarP(&validate, "validate", "", true, "reject a yaml file with fields its kind does not have, e.g. misspelled ones")
 
 	autoscaleCmd.Flags().StringVarP(&target, "target", "t", "", "target name")
 	autoscaleCmd.Flags().Float64VarP(&cpuPercent, "cpu", "c", 0.0, "cpu utilization percent metric")
@@ -24,6 +44,24 @@ func init() {
 	autoscaleCmd.Flags().IntVarP(&maxReplicas, "max", "", 1, "max replicas")
 	autoscaleCmd.Flags().IntVarP(&scaleInterval, "interval", "i", hpa.DefaultScaleInterval, "scale interval")
 
+	getCmd.Flags().StringVarP(&labelSelector, "selector", "l", "", "label selector to filter the list on, e.g. app=nginx,tier!=db")
+	getCmd.Flags().StringVarP(&fieldSelector, "field-selector", "", "", "field selector to filter the list on, e.g. spec.nodeName=node1")
+
+	deleteCmd.Flags().StringVarP(&cascade, "cascade", "", "background", "what to do with the dependents: background, foreground or orphan")
+
+	patchCmd.Flags().StringVarP(&patchContent, "patch", "p", "", "the patch, a JSON merge patch or a JSON patch as chosen by --type")
+	patchCmd.Flags().StringVarP(&patchType, "type", "", "merge", "the type of the patch: merge or json")
+	patchCmd.Flags().StringVarP(&resourceVersion, "resource-version", "", "", "only patch the object if it is still at this resource version")
+
+	rolloutCmd.Flags().IntVarP(&toRevision, "to-revision", "", 0, "the revision kubectl rollout undo rolls back to, the previous one by default")
+
+	backupCmd.Flags().StringVarP(&backupOutput, "output", "o", "", "the file kubectl backup create writes the backup to")
+	backupCmd.Flags().StringVarP(&backupFile, "file", "f", "", "the backup file kubectl backup restore restores")
+
+	certsCmd.Flags().StringSliceVarP(&certsHosts, "hosts", "", nil, "the hosts kubectl certs init issues the certificate of the api-server for, besides localhost")
+	certsCmd.Flags().StringSliceVarP(&certsGroups, "groups", "", nil, "the groups of the user kubectl certs issue signs a certificate for")
+	certsCmd.Flags().StringVarP(&certsOutputDir, "output-dir", "o", global.CredentialsDir, "the directory kubectl certs issue writes the certificate to")
+
 	labelCmd.Flags().BoolVarP(&overwrite, "overwrite", "o", false, "overwrite labels")
 
 	gpuCmd.Flags().StringVarP(&directory, "dir", "d", "./", "directory")
@@ -41,8 +79,13 @@ func init() {
 	rootCmd.AddCommand(describeCmd)
 	rootCmd.AddCommand(autoscaleCmd)
 	rootCmd.AddCommand(deleteCmd)
+	rootCmd.AddCommand(patchCmd)
+	rootCmd.AddCommand(rolloutCmd)
 	rootCmd.AddCommand(labelCmd)
 	rootCmd.AddCommand(resetCmd)
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(certsCmd)
 	rootCmd.AddCommand(gpuCmd)
 	rootCmd.AddCommand(funcCmd)
 	rootCmd.AddCommand(wfCmd)
@@ -55,7 +98,40 @@ var rootCmd = &cobra.Command{
 	Short: "Kubectl is for better control of minik8s",
 	Long: `By using kubectl, you can create api object in minik8s, or know details of them by using kubectl describe command.
 For example: kubectl apply -f ./example.yaml; kubectl describe pod examplePod`,
-	Run: runRoot,
+	PersistentPreRunE: loadConfigAndCredentials,
+	Run:               runRoot,
+}
+
+func loadConfigAndCredentials(cmd *cobra.Command, args []string) error {
+	// the flags cobra parsed are given to the config as if it parsed them
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if configFlags.Lookup(f.Name) != nil {
+			_ = configFlags.Set(f.Name, f.Value.String())
+		}
+	})
+	if err := configutil.Load(configFlags); err != nil {
+		return err
+	}
+	return loadCredentials(cmd, args)
+}
+
+// loadCredentials picks the credentials the requests are sent with, those of
+// the user if any, or else the admin credentials of the master node.
+func loadCredentials(cmd *cobra.Command, args []string) error {
+	if credentials != "" {
+		return httputil.LoadCredentials(credentials)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if userCredentials := path.Join(home, ".minik8s", "config"); fileExists(userCredentials) {
+			return httputil.LoadCredentials(userCredentials)
+		}
+	}
+	return httputil.LoadCredentials(httputil.CredentialsFile("admin"))
+}
+
+func fileExists(filePath string) bool {
+	_, err := os.Stat(filePath)
+	return err == nil
 }
 
 func runRoot(cmd *cobra.Command, args []string) {
@@ -23,10 +23,9 @@ func addWorkflowToApiServer(wf *apiObject.Workflow) error {
 	URL := url.Prefix + url.WorkflowURL
 	//fmt.Printf("wf: %+v\n", wf)
 	if resp, err := httputil.PostJson(URL, wf); err == nil {
-		var content []byte
-		if content, err = ioutil.ReadAll(resp.Body); err == nil {
-			defer resp.Body.Close()
-			fmt.Println(string(content))
+		var content string
+		if content, err = httputil.ReadResponse(resp); err == nil {
+			fmt.Println(content)
 			return nil
 		} else {
 			return err
@@ -18,6 +18,14 @@ const (
 	HorizontalPodAutoscaler
 	DNS
 	GpuJob
+	CustomResourceDefinition
+	Namespace
+	MutatingWebhookConfiguration
+	ValidatingWebhookConfiguration
+	Role
+	ClusterRole
+	RoleBinding
+	ClusterRoleBinding
 )
 
 func (tp *ApiObjectType) String() string {
@@ -36,6 +44,22 @@ func (tp *ApiObjectType) String() string {
 		return "HorizontalPodAutoscaler"
 	case DNS:
 		return "DNS"
+	case CustomResourceDefinition:
+		return "CustomResourceDefinition"
+	case Namespace:
+		return "Namespace"
+	case MutatingWebhookConfiguration:
+		return "MutatingWebhookConfiguration"
+	case ValidatingWebhookConfiguration:
+		return "ValidatingWebhookConfiguration"
+	case Role:
+		return "Role"
+	case ClusterRole:
+		return "ClusterRole"
+	case RoleBinding:
+		return "RoleBinding"
+	case ClusterRoleBinding:
+		return "ClusterRoleBinding"
 	}
 	return "Unknown"
 }
@@ -65,6 +89,8 @@ func parseType(content []byte) ApiObjectType {
 		return Pod
 	case "ReplicaSet":
 		return ReplicaSet
+	case "Deployment":
+		return Deployment
 	case "HorizontalPodAutoscaler":
 		return HorizontalPodAutoscaler
 	case "Service":
@@ -73,6 +99,22 @@ func parseType(content []byte) ApiObjectType {
 		return DNS
 	case "GpuJob":
 		return GpuJob
+	case "CustomResourceDefinition":
+		return CustomResourceDefinition
+	case "Namespace":
+		return Namespace
+	case "MutatingWebhookConfiguration":
+		return MutatingWebhookConfiguration
+	case "ValidatingWebhookConfiguration":
+		return ValidatingWebhookConfiguration
+	case "Role":
+		return Role
+	case "ClusterRole":
+		return ClusterRole
+	case "RoleBinding":
+		return RoleBinding
+	case "ClusterRoleBinding":
+		return ClusterRoleBinding
 	}
 	return Unknown
 }
@@ -3,52 +3,54 @@ package main
 import (
 	"flag"
 	"fmt"
-	"io/ioutil"
-	"minik8s/apiObject"
-	"minik8s/apiserver/src/url"
+	"minik8s/apiserver/src/auth"
+	"minik8s/kubelet/src/certificate"
 	"minik8s/kubelet/src/kubelet"
+	"minik8s/listwatch"
+	"minik8s/util/certutil"
+	"minik8s/util/configutil"
 	"minik8s/util/httputil"
 	"minik8s/util/netutil"
 	"minik8s/util/wait"
 	"os"
-	"time"
 )
 
-func registerNode(ip string) {
+// useNodeCertificate authenticates the kubelet as system:node:<hostname> from
+// now on, by a certificate it requests with the shared kubelet credentials.
+func useNodeCertificate() error {
 	hostname := netutil.Hostname()
-	node := apiObject.Node{
-		Base: apiObject.Base{
-			ApiVersion: "v1",
-			Kind:       "Node",
-			Metadata: apiObject.Metadata{
-				Name:      hostname,
-				Namespace: "default",
-				UID:       "",
-			},
-		},
-		Ip: ip,
+	certFile, keyFile := certutil.CertFile("kubelet"), certutil.KeyFile("kubelet")
+	if err := certificate.Request("node-"+hostname, auth.NodeUserPrefix+hostname, []string{auth.GroupComponents}, certFile, keyFile); err != nil {
+		return err
 	}
-
-	URL := url.Prefix + url.NodeURL
-	resp, err := httputil.PostJson(URL, node)
-	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(-1)
-	}
-
-	body := resp.Body
-	content, _ := ioutil.ReadAll(body)
-	defer body.Close()
-	fmt.Println(string(content))
+	return httputil.UseCredentials(&httputil.Credentials{ClientCertificate: certFile, ClientKey: keyFile})
 }
 
 func main() {
+	listwatch.SetIdentity("kubelet")
 	var ip string
+	var requestCertificate bool
 	flag.StringVar(&ip, "ip", "127.0.0.1", "ip address for node register")
+	flag.BoolVar(&requestCertificate, "request-certificate", false, "request a client certificate of the node from the cluster CA, which the admin approves")
+	configutil.AddFlags(flag.CommandLine)
 	flag.Parse()
+	if err := configutil.Load(flag.CommandLine); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(-1)
+	}
+	if err := httputil.LoadCredentials(httputil.CredentialsFile("kubelet")); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(-1)
+	}
+	if requestCertificate {
+		if err := useNodeCertificate(); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(-1)
+		}
+	}
 
-	go wait.Period(0, time.Minute, func() {
-		registerNode(ip)
+	go wait.Period(0, configutil.Current().SyncPeriods.NodeRegister, func() {
+		kubelet.RegisterNode(ip)
 	})
 
 	kl := kubelet.New()
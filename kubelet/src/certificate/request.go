@@ -0,0 +1,96 @@
+package certificate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/url"
+	"minik8s/util/certutil"
+	"minik8s/util/httputil"
+	"minik8s/util/logger"
+	"os"
+	"path"
+	"time"
+)
+
+var log = logger.Log("Certificate")
+
+const pollPeriod = 5 * time.Second
+
+// Request gets a client certificate of user in groups from the cluster CA,
+// unless certFile and keyFile already hold one. It sends a certificate
+// signing request named name, and waits until the admin approves it.
+func Request(name, user string, groups []string, certFile, keyFile string) error {
+	if fileExists(certFile) && fileExists(keyFile) {
+		return nil
+	}
+	key, err := certutil.NewKey()
+	if err != nil {
+		return err
+	}
+	request, err := certutil.NewRequest(key, user, groups)
+	if err != nil {
+		return err
+	}
+	if err = create(name, request); err != nil {
+		return err
+	}
+
+	log("waiting for the approval of certificate signing request %s, by kubectl certs approve %s", name, name)
+	csr := &apiObject.CertificateSigningRequest{}
+	for {
+		if err = httputil.GetAndUnmarshal(url.Prefix+path.Join(url.CSRURL, name), csr); err != nil {
+			return err
+		}
+		if csr.Condition() == apiObject.CertificateDenied {
+			condition := csr.Status.Conditions[len(csr.Status.Conditions)-1]
+			return fmt.Errorf("certificate signing request %s denied: %s %s", name, condition.Reason, condition.Message)
+		}
+		if csr.Status.Certificate != "" {
+			break
+		}
+		time.Sleep(pollPeriod)
+	}
+
+	keyPem, err := certutil.EncodeKey(key)
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(keyFile, keyPem, 0600); err != nil {
+		return err
+	}
+	log("got the certificate of %s", user)
+	return ioutil.WriteFile(certFile, []byte(csr.Status.Certificate), 0644)
+}
+
+// create sends the request, replacing one of the same name left by a former
+// run, whose key is lost.
+func create(name string, request []byte) error {
+	csr := &apiObject.CertificateSigningRequest{
+		Base: apiObject.Base{ApiVersion: "v1", Kind: "CertificateSigningRequest"},
+		Spec: apiObject.CertificateSigningRequestSpec{Request: string(request)},
+	}
+	csr.Metadata.Name = name
+
+	err := post(csr)
+	if httputil.IsAlreadyExists(err) {
+		if _, err = httputil.Delete(url.Prefix + path.Join(url.CSRURL, name)); err != nil {
+			return err
+		}
+		err = post(csr)
+	}
+	return err
+}
+
+func post(csr *apiObject.CertificateSigningRequest) error {
+	resp, err := httputil.PostJson(url.Prefix+url.CSRURL, csr)
+	if err == nil {
+		_, err = httputil.ReadResponse(resp)
+	}
+	return err
+}
+
+func fileExists(filePath string) bool {
+	_, err := os.Stat(filePath)
+	return err == nil
+}
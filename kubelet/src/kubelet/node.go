@@ -0,0 +1,41 @@
+package kubelet
+
+import (
+	"fmt"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/url"
+	"minik8s/util/httputil"
+	"minik8s/util/netutil"
+	"os"
+)
+
+// RegisterNode registers the node of the kubelet by its hostname and ip
+func RegisterNode(ip string) {
+	hostname := netutil.Hostname()
+	node := apiObject.Node{
+		Base: apiObject.Base{
+			ApiVersion: "v1",
+			Kind:       "Node",
+			Metadata: apiObject.Metadata{
+				Name:      hostname,
+				Namespace: "default",
+				UID:       "",
+			},
+		},
+		Ip: ip,
+	}
+
+	URL := url.Prefix + url.NodeURL
+	resp, err := httputil.PostJson(URL, node)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(-1)
+	}
+
+	// the node registers again every minute, and then already exists
+	if content, err := httputil.ReadResponse(resp); err == nil {
+		fmt.Println(content)
+	} else if !httputil.IsAlreadyExists(err) {
+		fmt.Println(err.Error())
+	}
+}
@@ -7,14 +7,13 @@ import (
 	"minik8s/kubelet/src/runtime/container"
 	"minik8s/kubelet/src/runtime/runtime"
 	"minik8s/kubelet/src/status"
+	"minik8s/util/configutil"
 	"minik8s/util/logger"
 	"minik8s/util/wait"
-	"time"
 )
 
 const (
 	eventChannelSize = 10
-	relistPeriod     = 10 * time.Second
 )
 
 var log = logger.Log("PLEG")
@@ -238,6 +237,7 @@ func (m *manager) relist() error {
 }
 
 func (m *manager) run() {
+	relistPeriod := configutil.Current().SyncPeriods.PodRelist
 	wait.Period(relistPeriod, relistPeriod, func() {
 		if err := m.relist(); err != nil {
 			log(err.Error())
@@ -5,14 +5,15 @@ import (
 	"github.com/docker/go-connections/nat"
 	"minik8s/apiObject"
 	"minik8s/apiObject/types"
-	"minik8s/apiserver/src/url"
 	"minik8s/kubelet/src/podutil"
 	"minik8s/kubelet/src/runtime/container"
 	"minik8s/kubelet/src/runtime/image"
+	"minik8s/util/configutil"
 	"minik8s/util/logger"
 	"minik8s/util/netutil"
 	"minik8s/util/weaveutil"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -22,6 +23,9 @@ func (rm *runtimeManager) needPullImage(container *apiObject.Container) (bool, e
 		return true, nil
 	}
 	exist, err := rm.im.ExistsImage(container.Image)
+	if err == nil && !exist && container.ImagePullPolicy == apiObject.PullPolicyNever {
+		return false, fmt.Errorf("image %s is not present and its pull policy is Never", container.Image)
+	}
 	return !exist, err
 }
 
@@ -74,7 +78,8 @@ func (rm *runtimeManager) addPortBindings(portBindings container.PortBindings, p
 		if port.Protocol == "" {
 			port.Protocol = "tcp"
 		}
-		containerPort, err := nat.NewPort(port.Protocol, port.ContainerPort)
+		// docker names the protocols in lower case
+		containerPort, err := nat.NewPort(strings.ToLower(port.Protocol), port.ContainerPort)
 		if err != nil {
 			return err
 		}
@@ -282,7 +287,8 @@ func (rm *runtimeManager) startPauseContainer(pod *apiObject.Pod) error {
 	err = rm.cm.StartContainer(ID, &container.StartConfig{})
 
 	// Step 5: Attach to weave subnet
-	if err = weaveutil.WeaveAttach(ID, pod.Spec.ClusterIp+url.MaskStr); err != nil {
+	_, mask := configutil.Current().PodIPRange()
+	if err = weaveutil.WeaveAttach(ID, fmt.Sprintf("%s/%d", pod.Spec.ClusterIp, mask)); err != nil {
 		return err
 	}
 	return err
@@ -337,23 +343,29 @@ func (rm *runtimeManager) removePodCommonContainers(pod *apiObject.Pod) error {
 
 // startCommonContainer starts a common container according to the given spec
 func (rm *runtimeManager) startCommonContainer(pod *apiObject.Pod, c *apiObject.Container) error {
+	ref := apiObject.ReferenceTo("Pod", pod)
+
 	// Step 1: Do we need pull the image?
 	needPull, err := rm.needPullImage(c)
 	if err != nil {
+		recorder.Eventf(ref, apiObject.EventTypeWarning, "Failed", "Failed to pull image %s: %s", c.Image, err.Error())
 		return err
 	}
 
 	// Step 2: If needed, pull the image for the given container
 	if needPull {
 		log("Pulling image[Name = %s]", c.Image)
+		recorder.Eventf(ref, apiObject.EventTypeNormal, "Pulling", "Pulling image %s", c.Image)
 		err = rm.im.PullImage(c.Image, &image.PullConfig{
 			Verbose: true,
 			All:     false,
 		})
 		if err != nil {
 			log("Pull error:", err.Error())
+			recorder.Eventf(ref, apiObject.EventTypeWarning, "Failed", "Failed to pull image %s: %s", c.Image, err.Error())
 			return err
 		}
+		recorder.Eventf(ref, apiObject.EventTypeNormal, "Pulled", "Successfully pulled image %s", c.Image)
 	} else {
 		log("No need to pull image %s, continue", c.Image)
 	}
@@ -370,14 +382,20 @@ func (rm *runtimeManager) startCommonContainer(pod *apiObject.Pod, c *apiObject.
 	ID, err = rm.cm.CreateContainer(containerFullName, rm.getCommonContainerCreateConfig(pod, c))
 	if err != nil {
 		log("Created failed, because: %s\n", err.Error())
+		recorder.Eventf(ref, apiObject.EventTypeWarning, "Failed", "Error creating container %s: %s", c.Name, err.Error())
 		return err
 	}
 	log("Create the container successfully, got %s", ID)
+	recorder.Eventf(ref, apiObject.EventTypeNormal, "Created", "Created container %s", c.Name)
 
 	// Step 4: Start this container
 	log("Now start the container with ID %s", ID)
-	err = rm.cm.StartContainer(ID, &container.StartConfig{})
-	return err
+	if err = rm.cm.StartContainer(ID, &container.StartConfig{}); err != nil {
+		recorder.Eventf(ref, apiObject.EventTypeWarning, "Failed", "Error starting container %s: %s", c.Name, err.Error())
+		return err
+	}
+	recorder.Eventf(ref, apiObject.EventTypeNormal, "Started", "Started container %s", c.Name)
+	return nil
 }
 
 func (rm *runtimeManager) getAllPodContainers() map[types.UID][]*container.Status {
@@ -7,6 +7,7 @@ import (
 	"minik8s/kubelet/src/podutil"
 	"minik8s/kubelet/src/runtime/container"
 	"minik8s/kubelet/src/runtime/image"
+	"minik8s/util/eventutil"
 	"minik8s/util/logger"
 	"minik8s/util/netutil"
 	"strconv"
@@ -15,6 +16,8 @@ import (
 
 var log = logger.Log("Runtime")
 
+var recorder = eventutil.NewRecorder("kubelet")
+
 type Pod struct {
 	ID         types.UID
 	Name       string
@@ -135,10 +138,12 @@ func (rm *runtimeManager) PodStartContainer(podUID types.UID, ID container.ID) e
 }
 
 func (rm *runtimeManager) PodRestartContainer(pod *apiObject.Pod, containerID container.ID, fullName string) error {
-	parseSucc, _, _, _, _, restartCount := podutil.ParseContainerFullName(fullName)
+	parseSucc, containerName, _, _, _, restartCount := podutil.ParseContainerFullName(fullName)
 	if !parseSucc {
 		panic("Could not happen")
 	}
+	recorder.Eventf(apiObject.ReferenceTo("Pod", pod), apiObject.EventTypeWarning, "BackOff",
+		"Back-off restarting failed container %s", containerName)
 	newName := fullName[:len(fullName)-1]
 	newName += strconv.Itoa(restartCount + 1)
 	err := rm.cm.RenameContainer(containerID, newName)
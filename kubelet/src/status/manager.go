@@ -8,6 +8,7 @@ import (
 	"minik8s/kubelet/src/runtime/runtime"
 	"minik8s/listwatch"
 	"minik8s/util/cache"
+	"minik8s/util/configutil"
 	"minik8s/util/httputil"
 	"minik8s/util/logger"
 	"minik8s/util/netutil"
@@ -23,7 +24,6 @@ import (
 var log = logger.Log("Status Manager")
 
 const (
-	syncPeriod     = 10 * time.Second
 	fullSyncPeriod = 30 * time.Second
 )
 
@@ -177,6 +177,7 @@ func (m *manager) fullSyncLoopIteration() {
 }
 
 func (m *manager) syncLoop() {
+	syncPeriod := configutil.Current().SyncPeriods.PodStatus
 	go wait.Period(syncPeriod, syncPeriod, m.syncLoopIteration)
 	go wait.Period(fullSyncPeriod, fullSyncPeriod, m.fullSyncLoopIteration)
 }
@@ -0,0 +1,90 @@
+package listwatch
+
+import (
+	"fmt"
+	"minik8s/util/configutil"
+	"os"
+	"sync"
+)
+
+// Broker delivers every message published to a topic to every subscriber of
+// that topic.
+type Broker interface {
+	Publish(topic string, msg interface{}) error
+	// Subscribe calls handler for each message of topic, one at a time, and
+	// only returns when the broker is closed or fails.
+	Subscribe(topic string, handler WatchHandler) error
+	Close() error
+}
+
+// namedSubscriber is a broker that tells the subscribers of a topic in one
+// process apart by name, which must stay the same across restarts.
+type namedSubscriber interface {
+	SubscribeAs(topic, name string, handler WatchHandler) error
+}
+
+const (
+	PubSubBackend = "pubsub"
+	StreamBackend = "stream"
+	MemoryBackend = "memory"
+)
+
+var (
+	brokerLock sync.Mutex
+	broker     Broker
+)
+
+// SetBroker picks the broker used by Publish and Watch. Call it at startup,
+// before any of them. All the components of a cluster must agree on the
+// backend, an in-memory broker only makes sense when they all run in one
+// process.
+func SetBroker(b Broker) {
+	brokerLock.Lock()
+	defer brokerLock.Unlock()
+	broker = b
+}
+
+// NewBroker creates a broker of the given backend, Redis ones connecting to
+// redisAddr.
+func NewBroker(backend, redisAddr string) (Broker, error) {
+	switch backend {
+	case "", PubSubBackend:
+		return NewRedisBroker(redisAddr), nil
+	case StreamBackend:
+		return NewStreamBroker(redisAddr), nil
+	case MemoryBackend:
+		return NewMemoryBroker(), nil
+	}
+	return nil, fmt.Errorf("unknown listwatch backend %s", backend)
+}
+
+// defaultBroker is used if SetBroker was not called. The backend is taken
+// from the LISTWATCH_BACKEND environment variable, Redis Pub/Sub if unset.
+func defaultBroker() Broker {
+	brokerLock.Lock()
+	defer brokerLock.Unlock()
+	if broker == nil {
+		addr := configutil.Current().Redis
+		b, err := NewBroker(os.Getenv("LISTWATCH_BACKEND"), addr)
+		if err != nil {
+			fmt.Println(err.Error())
+			b = NewRedisBroker(addr)
+		}
+		broker = b
+	}
+	return broker
+}
+
+// toPayload converts a published message the way Redis does, so that
+// handlers see the same payload whatever the broker is.
+func toPayload(msg interface{}) string {
+	switch v := msg.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(msg)
+}
@@ -0,0 +1,108 @@
+package listwatch
+
+import (
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"sync"
+)
+
+// memoryBroker delivers messages through in-process queues, so that the
+// whole control plane can run in one process, or in unit tests, without
+// Redis. Like Pub/Sub, a subscriber only gets messages published after it
+// has subscribed. Queues are unbounded, so Publish never blocks, even when
+// called by a handler.
+type memoryBroker struct {
+	lock        sync.Mutex
+	subscribers map[string][]*memorySubscriber
+	closed      bool
+}
+
+type memorySubscriber struct {
+	lock   sync.Mutex
+	cond   *sync.Cond
+	queue  []*redis.Message
+	closed bool
+}
+
+func NewMemoryBroker() Broker {
+	return &memoryBroker{
+		subscribers: make(map[string][]*memorySubscriber),
+	}
+}
+
+func newMemorySubscriber() *memorySubscriber {
+	sub := &memorySubscriber{}
+	sub.cond = sync.NewCond(&sub.lock)
+	return sub
+}
+
+func (sub *memorySubscriber) push(msg *redis.Message) {
+	sub.lock.Lock()
+	defer sub.lock.Unlock()
+	sub.queue = append(sub.queue, msg)
+	sub.cond.Signal()
+}
+
+// pop blocks until there is a message, and returns nil once closed
+func (sub *memorySubscriber) pop() *redis.Message {
+	sub.lock.Lock()
+	defer sub.lock.Unlock()
+	for len(sub.queue) == 0 && !sub.closed {
+		sub.cond.Wait()
+	}
+	if sub.closed {
+		return nil
+	}
+	msg := sub.queue[0]
+	sub.queue = sub.queue[1:]
+	return msg
+}
+
+func (sub *memorySubscriber) close() {
+	sub.lock.Lock()
+	defer sub.lock.Unlock()
+	sub.closed = true
+	sub.cond.Broadcast()
+}
+
+func (b *memoryBroker) Publish(topic string, msg interface{}) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.closed {
+		return fmt.Errorf("broker closed")
+	}
+	payload := toPayload(msg)
+	for _, sub := range b.subscribers[topic] {
+		sub.push(&redis.Message{Channel: topic, Payload: payload})
+	}
+	return nil
+}
+
+func (b *memoryBroker) Subscribe(topic string, handler WatchHandler) error {
+	sub := newMemorySubscriber()
+	b.lock.Lock()
+	if b.closed {
+		b.lock.Unlock()
+		return fmt.Errorf("broker closed")
+	}
+	b.subscribers[topic] = append(b.subscribers[topic], sub)
+	b.lock.Unlock()
+
+	for msg := sub.pop(); msg != nil; msg = sub.pop() {
+		handler(msg)
+	}
+	return nil
+}
+
+func (b *memoryBroker) Close() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.closed = true
+	for _, subs := range b.subscribers {
+		for _, sub := range subs {
+			sub.close()
+		}
+	}
+	b.subscribers = make(map[string][]*memorySubscriber)
+	return nil
+}
@@ -0,0 +1,83 @@
+package listwatch
+
+import (
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func waitSubscribers(b *memoryBroker, topic string, num int) {
+	for {
+		b.lock.Lock()
+		n := len(b.subscribers[topic])
+		b.lock.Unlock()
+		if n >= num {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMemoryBroker(t *testing.T) {
+	b := NewMemoryBroker()
+	topic := "__test__"
+
+	var lock sync.Mutex
+	var received [2][]string
+	var done sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		done.Add(1)
+		i := i
+		go func() {
+			defer done.Done()
+			err := b.Subscribe(topic, func(msg *redis.Message) {
+				lock.Lock()
+				defer lock.Unlock()
+				received[i] = append(received[i], msg.Payload)
+			})
+			assert.Nil(t, err)
+		}()
+	}
+	waitSubscribers(b.(*memoryBroker), topic, 2)
+
+	assert.Nil(t, b.Publish(topic, "hello"))
+	assert.Nil(t, b.Publish(topic, []byte("world")))
+	assert.Nil(t, b.Publish("other", "ignored"))
+
+	assert.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(received[0]) == 2 && len(received[1]) == 2
+	}, time.Second, time.Millisecond)
+
+	assert.Nil(t, b.Close())
+	done.Wait()
+	assert.Equal(t, []string{"hello", "world"}, received[0])
+	assert.Equal(t, []string{"hello", "world"}, received[1])
+	assert.NotNil(t, b.Publish(topic, "closed"))
+}
+
+func TestMemoryBrokerPublishFromHandler(t *testing.T) {
+	b := NewMemoryBroker()
+	result := make(chan string, 1)
+
+	go b.Subscribe("ping", func(msg *redis.Message) {
+		_ = b.Publish("pong", msg.Payload)
+	})
+	go b.Subscribe("pong", func(msg *redis.Message) {
+		result <- msg.Payload
+	})
+	waitSubscribers(b.(*memoryBroker), "ping", 1)
+	waitSubscribers(b.(*memoryBroker), "pong", 1)
+
+	assert.Nil(t, b.Publish("ping", "42"))
+	select {
+	case payload := <-result:
+		assert.Equal(t, "42", payload)
+	case <-time.After(time.Second):
+		t.Fatal("no pong")
+	}
+	_ = b.Close()
+}
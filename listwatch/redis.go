@@ -2,23 +2,45 @@ package listwatch
 
 import (
 	"context"
+	"fmt"
 	"github.com/go-redis/redis/v8"
-	"minik8s/global"
 )
 
 var ctx = context.Background()
 
-var client = redis.NewClient(&redis.Options{
-	Addr:     global.Host + ":6379",
-	Password: "",
-	DB:       0,
-})
+type redisBroker struct {
+	client *redis.Client
+}
+
+// NewRedisBroker creates a broker on Redis Pub/Sub. Messages published while
+// a subscriber is disconnected are lost for it.
+func NewRedisBroker(addr string) Broker {
+	return &redisBroker{client: newRedisClient(addr)}
+}
+
+func newRedisClient(addr string) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: "",
+		DB:       0,
+	})
+}
+
+func (b *redisBroker) Publish(topic string, msg interface{}) error {
+	return b.client.Publish(ctx, topic, msg).Err()
+}
 
-func subscribe(topic string) *redis.PubSub {
-	return client.Subscribe(ctx, topic)
+func (b *redisBroker) Subscribe(topic string, handler WatchHandler) error {
+	sub := b.client.Subscribe(ctx, topic)
+	defer sub.Close()
+	fmt.Println("Subscribe", topic)
+	for msg := range sub.Channel() {
+		//fmt.Printf("Received from %s: %s\n", msg.Channel, msg.Payload)
+		handler(msg)
+	}
+	return nil
 }
 
-// Publish is for testing now
-func Publish(topic string, msg interface{}) {
-	client.Publish(ctx, topic, msg)
+func (b *redisBroker) Close() error {
+	return b.client.Close()
 }
@@ -0,0 +1,189 @@
+package listwatch
+
+import (
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Unlike Pub/Sub, a message published to a Redis Stream stays there until
+// the stream is trimmed. Every watcher reads the stream through its own
+// consumer group, named after the process, and the watcher if there are several
+// of the topic in the process, and acknowledges a
+// message only after its handler returns. A restarted component therefore
+// first replays what it had received but not handled, and then continues
+// from its last acknowledged offset.
+
+const (
+	streamKeyPrefix  = "stream:"
+	streamPayloadKey = "payload"
+	streamMaxLen     = 10000
+	streamReadCount  = 64
+	streamBlock      = 5 * time.Second
+	streamRetryDelay = time.Second
+)
+
+var (
+	identityOnce sync.Once
+	identity     string
+)
+
+type streamBroker struct {
+	client     *redis.Client
+	groupsLock sync.Mutex
+	groups     map[string]bool
+}
+
+// NewStreamBroker creates a broker on Redis Streams with consumer groups.
+func NewStreamBroker(addr string) Broker {
+	return &streamBroker{
+		client: newRedisClient(addr),
+		groups: make(map[string]bool),
+	}
+}
+
+func streamKey(topic string) string {
+	return streamKeyPrefix + topic
+}
+
+// SetIdentity names this process after its component, e.g. kubelet, rather
+// than its binary, whose name may change, e.g. with go run. Call it at
+// startup, before any Watch.
+func SetIdentity(component string) {
+	identityOnce.Do(func() {
+		identity = newIdentity(component)
+	})
+}
+
+// Identity names this process in consumer groups, e.g. kubelet@node1. It can
+// be overridden by the LISTWATCH_IDENTITY environment variable, which is
+// needed if two instances of a component run on the same host.
+func Identity() string {
+	identityOnce.Do(func() {
+		identity = newIdentity(filepath.Base(os.Args[0]))
+	})
+	return identity
+}
+
+func newIdentity(component string) string {
+	if identity := os.Getenv("LISTWATCH_IDENTITY"); identity != "" {
+		return identity
+	}
+	hostname, _ := os.Hostname()
+	return component + "@" + hostname
+}
+
+// consumerGroup returns the group of the watcher of topic called name in this
+// process, e.g. controller@node1, or controller@node1/deployment-controller if
+// it has a name, so that each watcher gets every message, as with Pub/Sub,
+// and the same watcher gets the same group after a restart. Two watchers of
+// topic by the same name would share the messages instead, which is refused.
+func (b *streamBroker) consumerGroup(topic, name string) (string, error) {
+	group := Identity()
+	if name != "" {
+		group += "/" + name
+	}
+	b.groupsLock.Lock()
+	defer b.groupsLock.Unlock()
+	if b.groups[topic+" "+group] {
+		return "", fmt.Errorf("%s is already watched as %s, the watchers of a topic in a process need names, see WatchAs", topic, group)
+	}
+	b.groups[topic+" "+group] = true
+	return group, nil
+}
+
+func (b *streamBroker) Publish(topic string, msg interface{}) error {
+	return b.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: streamKey(topic),
+		MaxLen: streamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{streamPayloadKey: msg},
+	}).Err()
+}
+
+func (b *streamBroker) createGroup(key, group string) error {
+	// "$" means a brand-new group only sees messages published from now on,
+	// an existing group keeps its offset.
+	err := b.client.XGroupCreateMkStream(ctx, key, group, "$").Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return err
+}
+
+// Subscribe reads the stream of topic as a consumer group until the broker is
+// closed. It starts with ID "0", i.e. the messages delivered to us before but
+// never acknowledged, and switches to ">", i.e. new messages, once they are
+// done.
+func (b *streamBroker) Subscribe(topic string, handler WatchHandler) error {
+	return b.SubscribeAs(topic, "", handler)
+}
+
+// SubscribeAs is Subscribe through the consumer group of the watcher name
+func (b *streamBroker) SubscribeAs(topic, name string, handler WatchHandler) error {
+	key := streamKey(topic)
+	group, err := b.consumerGroup(topic, name)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Subscribe stream", topic, "as", group)
+
+	for {
+		if err := b.createGroup(key, group); err != nil {
+			if err == redis.ErrClosed {
+				return err
+			}
+			fmt.Printf("create group %s of %s failed: %v\n", group, key, err)
+			time.Sleep(streamRetryDelay)
+			continue
+		}
+		break
+	}
+
+	lastID := "0"
+	for {
+		streams, err := b.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    group,
+			Consumer: Identity(),
+			Streams:  []string{key, lastID},
+			Count:    streamReadCount,
+			Block:    streamBlock,
+		}).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err == redis.ErrClosed {
+			return nil
+		}
+		if err != nil {
+			if strings.HasPrefix(err.Error(), "NOGROUP") {
+				_ = b.createGroup(key, group)
+			}
+			fmt.Printf("read stream %s failed: %v\n", key, err)
+			time.Sleep(streamRetryDelay)
+			continue
+		}
+
+		numMessages := 0
+		for _, stream := range streams {
+			for _, xMsg := range stream.Messages {
+				numMessages++
+				payload, _ := xMsg.Values[streamPayloadKey].(string)
+				handler(&redis.Message{Channel: topic, Payload: payload})
+				b.client.XAck(ctx, key, group, xMsg.ID)
+			}
+		}
+
+		if lastID == "0" && numMessages == 0 {
+			lastID = ">"
+		}
+	}
+}
+
+func (b *streamBroker) Close() error {
+	return b.client.Close()
+}
@@ -0,0 +1,103 @@
+package listwatch
+
+import (
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConsumerGroup(t *testing.T) {
+	b := &streamBroker{groups: make(map[string]bool)}
+	cache, err := b.consumerGroup("rsStatus", "")
+	assert.Nil(t, err)
+	assert.Equal(t, Identity(), cache)
+	deployment, err := b.consumerGroup("rsStatus", "deployment-controller")
+	assert.Nil(t, err)
+	assert.Equal(t, Identity()+"/deployment-controller", deployment)
+
+	// the same watcher of another topic, or after a restart, has the same group
+	group, err := b.consumerGroup("podStatus", "")
+	assert.Nil(t, err)
+	assert.Equal(t, cache, group)
+	restarted := &streamBroker{groups: make(map[string]bool)}
+	group, err = restarted.consumerGroup("rsStatus", "deployment-controller")
+	assert.Nil(t, err)
+	assert.Equal(t, deployment, group)
+
+	_, err = b.consumerGroup("rsStatus", "")
+	assert.NotNil(t, err)
+	_, err = b.consumerGroup("rsStatus", "deployment-controller")
+	assert.NotNil(t, err)
+}
+
+// newTestStreamBroker connects to the Redis of LISTWATCH_TEST_REDIS, the
+// tests that need one are skipped without it.
+func newTestStreamBroker(t *testing.T) Broker {
+	addr := os.Getenv("LISTWATCH_TEST_REDIS")
+	if addr == "" {
+		t.Skip("LISTWATCH_TEST_REDIS is not set")
+	}
+	b := NewStreamBroker(addr)
+	if err := b.(*streamBroker).client.Ping(ctx).Err(); err != nil {
+		t.Skipf("no redis at %s: %v", addr, err)
+	}
+	return b
+}
+
+func TestStreamBroker(t *testing.T) {
+	b := newTestStreamBroker(t)
+	topic := "__test__" + time.Now().Format(time.RFC3339Nano)
+	defer b.(*streamBroker).client.Del(ctx, streamKey(topic))
+
+	var lock sync.Mutex
+	received := make(map[string][]string)
+	subscribe := func(b Broker, name string) {
+		_ = b.(*streamBroker).SubscribeAs(topic, name, func(msg *redis.Message) {
+			lock.Lock()
+			defer lock.Unlock()
+			received[name] = append(received[name], msg.Payload)
+		})
+	}
+	numReceived := func(name string) int {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(received[name])
+	}
+	waitGroup := func(name string) {
+		assert.Eventually(t, func() bool {
+			groups, _ := b.(*streamBroker).client.XInfoGroups(ctx, streamKey(topic)).Result()
+			for _, group := range groups {
+				if group.Name == Identity()+"/"+name {
+					return true
+				}
+			}
+			return false
+		}, time.Second, time.Millisecond)
+	}
+
+	go subscribe(b, "first")
+	go subscribe(b, "second")
+	waitGroup("first")
+	waitGroup("second")
+	assert.Nil(t, b.Publish(topic, "hello"))
+	assert.Eventually(t, func() bool {
+		return numReceived("first") == 1 && numReceived("second") == 1
+	}, 2*streamBlock, time.Millisecond)
+	assert.Nil(t, b.Close())
+
+	// a message published while the second watcher is down reaches it once
+	// it is back, under the same name
+	other := newTestStreamBroker(t)
+	defer other.Close()
+	assert.Nil(t, other.Publish(topic, "world"))
+	go subscribe(other, "second")
+	assert.Eventually(t, func() bool {
+		return numReceived("second") == 2
+	}, 2*streamBlock, time.Millisecond)
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Equal(t, []string{"hello", "world"}, received["second"])
+}
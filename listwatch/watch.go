@@ -1,19 +1,39 @@
 package listwatch
 
 import (
-	"fmt"
 	"github.com/go-redis/redis/v8"
+	"minik8s/util/logger"
 )
 
 type WatchHandler func(message *redis.Message)
 
+// Publish publishes msg to topic through the broker picked at startup
+func Publish(topic string, msg interface{}) {
+	if err := defaultBroker().Publish(topic, msg); err != nil {
+		logger.Error("publish to %s failed: %v", topic, err)
+	}
+}
+
 // Watch infinitely watches a given topic, please call it
 // by goroutine
 func Watch(topic string, handler WatchHandler) {
-	sub := subscribe(topic)
-	fmt.Println("Subscribe", topic)
-	for msg := range sub.Channel() {
-		//fmt.Printf("Received from %s: %s\n", msg.Channel, msg.Payload)
-		handler(msg)
+	if err := defaultBroker().Subscribe(topic, handler); err != nil {
+		logger.Error("watch %s failed: %v", topic, err)
+	}
+}
+
+// WatchAs is Watch for one of several watchers of topic in this process, which
+// the stream broker tells apart by name, e.g. "deployment-controller". The
+// other brokers ignore the name.
+func WatchAs(topic, name string, handler WatchHandler) {
+	b := defaultBroker()
+	var err error
+	if named, ok := b.(namedSubscriber); ok {
+		err = named.SubscribeAs(topic, name, handler)
+	} else {
+		err = b.Subscribe(topic, handler)
+	}
+	if err != nil {
+		logger.Error("watch %s as %s failed: %v", topic, name, err)
 	}
 }
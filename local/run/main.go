@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"minik8s/local/src/local"
+	"minik8s/util/configutil"
+	"os"
+)
+
+const usage = `minik8s local runs a whole cluster of one node in this process.
+
+Usage:
+  minik8s local [flags]
+
+Flags:
+`
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "local" {
+		fmt.Print(usage)
+		os.Exit(2)
+	}
+	var dataDir, ip string
+	fs := flag.NewFlagSet("minik8s local", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Print(usage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&dataDir, "data-dir", "minik8s-data", "the directory of the etcd data, the credentials, the certificates, the config file and the audit log")
+	fs.StringVar(&ip, "ip", "127.0.0.1", "ip address for node register")
+	configutil.AddFlags(fs)
+	_ = fs.Parse(os.Args[2:])
+
+	if err := local.UseDataDir(dataDir); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(-1)
+	}
+	if err := configutil.Load(fs); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(-1)
+	}
+	if err := local.Run(ip); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(-1)
+	}
+}
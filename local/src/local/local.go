@@ -0,0 +1,140 @@
+package local
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"minik8s/apiserver/src/apiserver"
+	"minik8s/apiserver/src/audit"
+	"minik8s/apiserver/src/auth"
+	"minik8s/apiserver/src/url"
+	"minik8s/controller/src/controller"
+	"minik8s/global"
+	"minik8s/kubelet/src/kubelet"
+	"minik8s/listwatch"
+	"minik8s/proxy/src/proxy"
+	"minik8s/scheduler/src/scheduler"
+	"minik8s/serverless/src/knative"
+	"minik8s/serverless/src/registry"
+	"minik8s/util/certutil"
+	"minik8s/util/configutil"
+	"minik8s/util/httputil"
+	"minik8s/util/leaderelection"
+	"minik8s/util/logger"
+	"minik8s/util/wait"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+var log = logger.Log("local")
+
+const apiServerStartTimeout = 2 * time.Minute
+
+// UseDataDir keeps the state of the cluster in dataDir: the credentials, the
+// certificates, the config file and the audit log are there instead of in
+// /etc/minik8s and /var/log/minik8s, and etcd keeps its data in dataDir/etcd.
+// Call it before the config is loaded, since the config file is there too.
+func UseDataDir(dataDir string) error {
+	dataDir, err := filepath.Abs(dataDir)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dataDir, 0700); err != nil {
+		return err
+	}
+	global.CredentialsDir = dataDir
+	audit.DefaultLogFile = path.Join(dataDir, "audit.log")
+	return nil
+}
+
+func etcdDataDir() string {
+	return path.Join(global.CredentialsDir, "etcd")
+}
+
+// KubectlCredentialsFile is where the credentials kubectl uses against the
+// local cluster are, e.g. kubectl --credentials <data-dir>/kubectl.yaml
+func KubectlCredentialsFile() string {
+	return path.Join(global.CredentialsDir, "kubectl.yaml")
+}
+
+// Run runs the whole cluster in this process: the api-server with an
+// embedded etcd, the scheduler, the controller manager, the serverless
+// controller, the proxy, and the kubelet of a node of the given ip. The
+// components pass their messages through an in-memory broker instead of
+// Redis, and elect no leader since each is the only replica. Run only returns
+// if the cluster fails to start.
+func Run(nodeIp string) error {
+	listwatch.SetBroker(listwatch.NewMemoryBroker())
+
+	// the credentials are created ahead of the api-server, since the other
+	// components share the client of this process, which sends the admin ones
+	if err := auth.Bootstrap(); err != nil {
+		return err
+	}
+	if err := certutil.Bootstrap([]string{url.Hostname}, false); err != nil {
+		return err
+	}
+	if err := writeKubectlCredentials(); err != nil {
+		return err
+	}
+	if err := httputil.LoadCredentials(httputil.CredentialsFile("admin")); err != nil {
+		return err
+	}
+
+	go func() {
+		apiserver.NewWithEmbeddedEtcd(etcdDataDir()).Run()
+		log("the api-server stopped, exit")
+		os.Exit(-1)
+	}()
+	if err := waitForApiServer(); err != nil {
+		return err
+	}
+	log("the api-server is ready, run kubectl --credentials %s", KubectlCredentialsFile())
+
+	go scheduler.New(leaderelection.Config{}).Start()
+	go controller.NewControllerManager(leaderelection.Config{}).Start()
+	go func() {
+		registry.InitRegistry()
+		knative.NewKnative().Run()
+	}()
+	go proxy.New().Run()
+	go wait.Period(0, configutil.Current().SyncPeriods.NodeRegister, func() {
+		kubelet.RegisterNode(nodeIp)
+	})
+	kubelet.New().Run()
+	return nil
+}
+
+// writeKubectlCredentials writes the admin credentials along with the CA of
+// the local cluster, which kubectl does not look for in the data directory.
+func writeKubectlCredentials() error {
+	content, err := ioutil.ReadFile(httputil.CredentialsFile("admin"))
+	if err != nil {
+		return err
+	}
+	creds := &httputil.Credentials{}
+	if err = yaml.Unmarshal(content, creds); err != nil {
+		return err
+	}
+	creds.CertificateAuthority = certutil.CertFile(certutil.CA)
+	credsYaml, _ := yaml.Marshal(creds)
+	return ioutil.WriteFile(KubectlCredentialsFile(), credsYaml, 0600)
+}
+
+// waitForApiServer waits until the api-server answers, whatever it answers
+func waitForApiServer() error {
+	deadline := time.Now().Add(apiServerStartTimeout)
+	for {
+		resp, err := httputil.Get(url.Prefix + url.NodeURL)
+		if err == nil {
+			_ = resp.Body.Close()
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("the api-server is not ready after %v: %s", apiServerStartTimeout, err.Error())
+		}
+		time.Sleep(time.Second)
+	}
+}
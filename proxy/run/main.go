@@ -1,9 +1,21 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"minik8s/listwatch"
 	"minik8s/proxy/src/proxy"
+	"minik8s/util/configutil"
+	"os"
 )
 
 func main() {
+	listwatch.SetIdentity("proxy")
+	configutil.AddFlags(flag.CommandLine)
+	flag.Parse()
+	if err := configutil.Load(flag.CommandLine); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(-1)
+	}
 	proxy.New().Run()
 }
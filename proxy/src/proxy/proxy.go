@@ -82,7 +82,11 @@ func (proxy *Proxy) syncLoopIteration(endpointUpdates <-chan *entity.EndpointUpd
 				log(err.Error())
 			}
 		case entity.UpdateAction:
-			log("undefined UpdateAction")
+			// The nginx of the service keeps its cluster ip, only its ports
+			// and endpoints change
+			if err := proxy.iptablesManager.ApplyService(serviceUpdate.Target.Service, serviceUpdate.Target.Endpoints); err != nil {
+				log(err.Error())
+			}
 		case entity.DeleteAction:
 			if err := proxy.iptablesManager.ShutdownService(serviceUpdate.Target.Service); err != nil {
 				log(err.Error())
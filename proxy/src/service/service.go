@@ -1,9 +1,10 @@
 package service
 
 import (
+	"fmt"
 	"minik8s/apiObject"
-	"minik8s/apiserver/src/url"
 	"minik8s/nginx"
+	"minik8s/util/configutil"
 	"minik8s/util/weaveutil"
 	"strconv"
 )
@@ -26,7 +27,8 @@ func (sm *serviceManager) StartService(service apiObject.Service) error {
 	if err := nm.Start(); err != nil {
 		return err
 	}
-	return weaveutil.WeaveAttach(nm.GetName(), service.Spec.ClusterIP+url.MaskStr)
+	_, mask := configutil.Current().ServiceIPRange()
+	return weaveutil.WeaveAttach(nm.GetName(), fmt.Sprintf("%s/%d", service.Spec.ClusterIP, mask))
 }
 
 func (sm *serviceManager) ApplyService(service apiObject.Service, endpoints []apiObject.Endpoint) error {
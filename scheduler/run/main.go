@@ -1,8 +1,30 @@
 package main
 
-import "minik8s/scheduler/src/scheduler"
+import (
+	"flag"
+	"fmt"
+	"minik8s/listwatch"
+	"minik8s/scheduler/src/scheduler"
+	"minik8s/util/configutil"
+	"minik8s/util/httputil"
+	"minik8s/util/leaderelection"
+	"os"
+)
 
 func main() {
-	s := scheduler.New()
+	listwatch.SetIdentity("scheduler")
+	leaderElection := leaderelection.DefaultConfig("kube-scheduler")
+	leaderElection.AddFlags(flag.CommandLine)
+	configutil.AddFlags(flag.CommandLine)
+	flag.Parse()
+	if err := configutil.Load(flag.CommandLine); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(-1)
+	}
+	if err := httputil.LoadCredentials(httputil.CredentialsFile("kube-scheduler")); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(-1)
+	}
+	s := scheduler.New(leaderElection)
 	s.Start()
 }
@@ -1,12 +1,20 @@
 package scheduler
 
 import (
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
 	"minik8s/apiObject"
+	"minik8s/apiserver/src/url"
 	"minik8s/entity"
-	"minik8s/scheduler/src/filter"
 	"minik8s/scheduler/src/selector"
+	"minik8s/util/httputil"
+	"minik8s/util/selectorutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -51,17 +59,50 @@ var testPod = &apiObject.Pod{
 	},
 }
 
+// serveNodes serves the nodes as the api-server does, only those matching the
+// labelSelector of the request, and points the requests at it.
+func serveNodes(t *testing.T, nodes []*entity.NodeStatus) func() {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, url.NodeURL, r.URL.Path)
+		labelSelector, err := selectorutil.Parse(r.URL.Query().Get("labelSelector"))
+		assert.Nil(t, err)
+		selected := make([]*entity.NodeStatus, 0)
+		for _, node := range nodes {
+			if labelSelector.Matches(node.Labels) {
+				selected = append(selected, node)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(selected)
+	}))
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.Nil(t, ioutil.WriteFile(caFile, ca, 0600))
+	assert.Nil(t, httputil.UseCredentials(&httputil.Credentials{CertificateAuthority: caFile}))
+
+	prefix := url.Prefix
+	url.Prefix = server.URL
+	return func() {
+		url.Prefix = prefix
+		server.Close()
+		_ = httputil.UseCredentials(&httputil.Credentials{})
+	}
+}
+
 func TestFilter(t *testing.T) {
-	f := filter.Default()
-	testNodes := []*entity.NodeStatus{testNode1, testNode2}
-	filtered := f.Filter(testPod, testNodes)
+	defer serveNodes(t, []*entity.NodeStatus{testNode1, testNode2})()
+	sch := &scheduler{}
+	pod := &apiObject.Pod{Spec: apiObject.PodSpec{NodeSelector: map[string]string{"os": "linux"}}}
+	filtered := sch.getNodes(pod)
 	assert.Equal(t, 1, len(filtered))
 	assert.Equal(t, "node1", filtered[0].Hostname)
 
-	testPod.Spec.NodeSelector["os"] = "windows"
-	filtered = f.Filter(testPod, testNodes)
+	pod.Spec.NodeSelector["os"] = "windows"
+	filtered = sch.getNodes(pod)
 	assert.Equal(t, 1, len(filtered))
 	assert.Equal(t, "node2", filtered[0].Hostname)
+
+	// a pod without a nodeSelector may go anywhere
+	assert.Equal(t, 2, len(sch.getNodes(&apiObject.Pod{})))
 }
 
 func TestSelector(t *testing.T) {
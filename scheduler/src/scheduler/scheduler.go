@@ -2,17 +2,21 @@ package scheduler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/go-redis/redis/v8"
 	"minik8s/apiObject"
 	"minik8s/apiserver/src/url"
 	"minik8s/entity"
 	"minik8s/listwatch"
-	"minik8s/scheduler/src/filter"
 	"minik8s/scheduler/src/selector"
+	"minik8s/util/eventutil"
 	"minik8s/util/httputil"
+	"minik8s/util/leaderelection"
 	"minik8s/util/logger"
+	"minik8s/util/selectorutil"
 	"minik8s/util/topicutil"
+	netURL "net/url"
 	"strings"
 )
 
@@ -23,31 +27,50 @@ type Scheduler interface {
 	Schedule(podUpdate *entity.PodUpdate) error
 }
 
-func New() Scheduler {
+// New returns a scheduler, which only schedules while it leads the replicas
+// elected by leaderElection.
+func New(leaderElection leaderelection.Config) Scheduler {
 	return &scheduler{
-		filter.Default(),
-		selector.DefaultFactory.NewSelector(selector.Random),
+		selector:       selector.DefaultFactory.NewSelector(selector.Random),
+		recorder:       eventutil.NewRecorder("scheduler"),
+		leaderElection: leaderElection,
 	}
 }
 
 type scheduler struct {
-	filter   filter.Filter
-	selector selector.Selector
+	selector       selector.Selector
+	recorder       eventutil.Recorder
+	leaderElection leaderelection.Config
 }
 
-// getNodesFromApiServer get nodes from api-server
-func (s *scheduler) getNodesFromApiServer() (nodes []*entity.NodeStatus) {
-	_ = httputil.GetAndUnmarshal(url.Prefix+url.NodeURL, &nodes)
+// nodeSelectorOf returns the label selector of the nodes the pod may run on
+func nodeSelectorOf(pod *apiObject.Pod) selectorutil.Selector {
+	return selectorutil.FromSet(pod.NodeSelector())
+}
+
+// getNodesFromApiServer get the nodes that have all the labels of the
+// nodeSelector of the pod from api-server
+func (s *scheduler) getNodesFromApiServer(pod *apiObject.Pod) (nodes []*entity.NodeStatus) {
+	URL := url.Prefix + url.NodeURL
+	if labelSelector := nodeSelectorOf(pod); !labelSelector.Empty() {
+		URL += "?labelSelector=" + netURL.QueryEscape(labelSelector.String())
+	}
+	if err := httputil.GetAndUnmarshal(URL, &nodes); err != nil {
+		logger.Error(err.Error())
+	}
 	return
 }
 
-func (s *scheduler) getNodes() []*entity.NodeStatus {
-	return s.getNodesFromApiServer()
+func (s *scheduler) getNodes(pod *apiObject.Pod) []*entity.NodeStatus {
+	return s.getNodesFromApiServer(pod)
 }
 
 func (s *scheduler) sendScheduleInfoToApiServer(node string, pod *apiObject.Pod) {
 	URL := url.Prefix + strings.Replace(url.PodURLWithSpecifiedNode, ":node", node, 1)
-	_, err := httputil.PostJson(URL, pod)
+	resp, err := httputil.PostJson(URL, pod)
+	if err == nil {
+		_, err = httputil.ReadResponse(resp)
+	}
 	if err != nil {
 		logger.Error(err.Error())
 	}
@@ -82,26 +105,26 @@ func (s *scheduler) changeStrategy(tp selector.Type) {
 }
 
 func (s *scheduler) Schedule(podUpdate *entity.PodUpdate) error {
-	// Step 1: Get nodes from api-server
-	nodes := s.getNodes()
+	pod := &podUpdate.Target
 
-	if len(nodes) == 0 {
-		return fmt.Errorf("no available node now")
-	}
+	// Step 1: Get the nodes matching the nodeSelector from api-server
+	nodes := s.getNodes(pod)
 
-	// Step 2: Preliminary Filter
-	filtered := s.filter.Filter(&podUpdate.Target, nodes)
-	if len(filtered) == 0 {
-		return fmt.Errorf("no suitable node now")
+	// Step 2: Select one node
+	var node *entity.NodeStatus
+	if len(nodes) != 0 {
+		node = s.selector.Select(nodes)
 	}
-
-	// Step 3: Select one node
-	node := s.selector.Select(filtered)
 	if node == nil {
-		return fmt.Errorf("no suitable node now")
+		message := "no suitable node now"
+		if nodeSelector := nodeSelectorOf(pod); !nodeSelector.Empty() {
+			message = fmt.Sprintf("no suitable node now, %d node(s) match node selector %s", len(nodes), nodeSelector.String())
+		}
+		s.recorder.Event(apiObject.ReferenceTo("Pod", pod), apiObject.EventTypeWarning, "FailedScheduling", message)
+		return errors.New(message)
 	}
 
-	// Step 4: Prepare for the message
+	// Step 3: Prepare for the message
 	nodeName := node.Hostname
 	topic := topicutil.PodUpdateTopic(nodeName)
 	updateMsg, err := json.Marshal(podUpdate)
@@ -109,10 +132,12 @@ func (s *scheduler) Schedule(podUpdate *entity.PodUpdate) error {
 		return err
 	}
 
-	// Step 5: Send schedule info to api-server
-	s.sendScheduleInfoToApiServer(nodeName, &podUpdate.Target)
+	// Step 4: Send schedule info to api-server
+	s.sendScheduleInfoToApiServer(nodeName, pod)
+	s.recorder.Eventf(apiObject.ReferenceTo("Pod", pod), apiObject.EventTypeNormal, "Scheduled",
+		"Successfully assigned %s/%s to %s", pod.Namespace(), pod.Name(), nodeName)
 
-	// Step 6: Send msg to such node
+	// Step 5: Send msg to such node
 	fmt.Printf("Send msg %s: [%v]%v to %s\n", topic, podUpdate.Action.String(), podUpdate.Target.Name(), nodeName)
 	listwatch.Publish(topic, updateMsg)
 	return nil
@@ -139,6 +164,10 @@ func (s *scheduler) parseAndSchedule(msg *redis.Message) {
 }
 
 func (s *scheduler) Start() {
+	leaderelection.Run(s.leaderElection, s.run)
+}
+
+func (s *scheduler) run() {
 	go listwatch.Watch(topicutil.ScheduleStrategyTopic(), s.handleStrategyChange)
 	listwatch.Watch(topicutil.SchedulerPodUpdateTopic(), s.parseAndSchedule)
 }
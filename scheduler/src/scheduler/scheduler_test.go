@@ -7,6 +7,7 @@ import (
 	"minik8s/apiObject"
 	"minik8s/entity"
 	"minik8s/scheduler/src/selector"
+	"minik8s/util/leaderelection"
 	"testing"
 )
 
@@ -19,11 +20,11 @@ func readPod(podPath string) *apiObject.Pod {
 
 func TestGetNodes(t *testing.T) {
 	sch := scheduler{selector: selector.DefaultFactory.NewSelector(selector.Random)}
-	fmt.Println(sch.getNodes()[0])
+	fmt.Println(sch.getNodes(testPod)[0])
 }
 
 func TestScheduler(t *testing.T) {
-	s := New()
+	s := New(leaderelection.DefaultConfig("kube-scheduler"))
 	err := s.Schedule(&entity.PodUpdate{
 		Action: entity.CreateAction,
 		Target: *testPod,
@@ -1,11 +1,28 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"minik8s/listwatch"
 	"minik8s/serverless/src/knative"
 	"minik8s/serverless/src/registry"
+	"minik8s/util/configutil"
+	"minik8s/util/httputil"
+	"os"
 )
 
 func main() {
+	listwatch.SetIdentity("serverless")
+	configutil.AddFlags(flag.CommandLine)
+	flag.Parse()
+	if err := configutil.Load(flag.CommandLine); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(-1)
+	}
+	if err := httputil.LoadCredentials(httputil.CredentialsFile("serverless")); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(-1)
+	}
 	registry.InitRegistry()
 	//function.CreateFunctionImage("helloworld", "serverless/src/app/func.py") // the third parameter need to be replaced
 	kn := knative.NewKnative()
@@ -10,6 +10,7 @@ import (
 	"minik8s/entity"
 	"minik8s/listwatch"
 	"minik8s/serverless/src/trigger"
+	"minik8s/util/configutil"
 	"minik8s/util/httputil"
 	"minik8s/util/logger"
 	"minik8s/util/topicutil"
@@ -24,7 +25,6 @@ var logManager = logger.Log("KPA manager")
 var bgCtx = context.Background()
 
 const (
-	scalePeriod        = time.Second * 10
 	scaleTimeThreshold = time.Minute * 2
 )
 
@@ -96,9 +96,9 @@ func (c *controller) sendResultToApiServer(result *entity.FunctionTriggerResult)
 	URL := url.Prefix + path.Join(url.WorkflowURL, "result", result.WorkflowNamespace, result.WorkflowName)
 	resp, err := httputil.PostJson(URL, result)
 	if err == nil {
-		if content, err := ioutil.ReadAll(resp.Body); err == nil {
-			defer resp.Body.Close()
-			logManager("Send result of wf %s to api-server and get resp: %s", result.WorkflowName, string(content))
+		var content string
+		if content, err = httputil.ReadResponse(resp); err == nil {
+			logManager("Send result of wf %s to api-server and get resp: %s", result.WorkflowName, content)
 		}
 	}
 	return err
@@ -123,6 +123,7 @@ func (c *controller) handleTriggerResult() {
 func (c *controller) Run() {
 	go listwatch.Watch(topicutil.FunctionUpdateTopic(), c.handleFunctionUpdate)
 	go listwatch.Watch(topicutil.WorkflowUpdateTopic(), c.handleWorkflowUpdate)
+	scalePeriod := configutil.Current().SyncPeriods.FunctionScale
 	go wait.Period(scalePeriod, scalePeriod, c.scale)
 	go c.handleTriggerResult()
 }
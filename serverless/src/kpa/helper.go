@@ -16,7 +16,7 @@ import (
 )
 
 func (c *controller) updateReplicaSetToApiServer(funcReplicaSet *functionReplicaSet) {
-	URL := url.Prefix + path.Join(url.ReplicaSetURL, "function", funcReplicaSet.Function)
+	URL := url.Prefix + path.Join(url.ReplicaSetURL, "function", funcReplicaSet.Function, "scale")
 	resp := httputil.PutForm(URL, map[string]string{
 		"replicas": strconv.Itoa(funcReplicaSet.NumReplicas),
 	})
@@ -33,7 +33,7 @@ func (c *controller) scaleToHalf(funcReplicaSet *functionReplicaSet) {
 func (c *controller) createReplicaSet(apiFunc *apiObject.Function) {
 	c.scaleLock.Lock()
 	defer c.scaleLock.Unlock()
-	imageName := registry.RegistryHost + "/" + imageutil.FormatImageName(apiFunc.Name)
+	imageName := registry.RegistryHost() + "/" + imageutil.FormatImageName(apiFunc.Name)
 	replicaSet := apiObject.ReplicaSet{
 		Base: apiObject.Base{
 			ApiVersion: "api/v1",
@@ -46,6 +46,11 @@ func (c *controller) createReplicaSet(apiFunc *apiObject.Function) {
 		Spec: apiObject.ReplicaSetSpec{
 			Replicas: 2,
 			Template: apiObject.PodTemplateSpec{
+				Metadata: apiObject.Metadata{
+					Labels: apiObject.Labels{
+						apiObject.FunctionLabel: apiFunc.Name,
+					},
+				},
 				Spec: apiObject.PodSpec{
 					//NodeSelector: apiObject.Labels{
 					//	"type": "master",
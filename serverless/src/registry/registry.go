@@ -12,6 +12,7 @@ import (
 	"log"
 	"minik8s/kubelet/src/runtime/container"
 	"minik8s/serverless/src/utils"
+	"minik8s/util/configutil"
 	"net/http"
 	"os"
 
@@ -27,8 +28,6 @@ import (
 const (
 	RegistryImage = "registry:2.8.0"
 	RegistryName  = "local-registry"
-	RegistryHost     = "10.119.11.101:5000"
-	//RegistryHost     = "0.0.0.0:5000"
 	RegistryHostIP   = "0.0.0.0"
 	RegistryHostPort = "5000"
 )
@@ -37,6 +36,12 @@ var (
 	cli *client.Client
 )
 
+// RegistryHost is the host:port of the registry in the config, the images of
+// the functions are named after it.
+func RegistryHost() string {
+	return configutil.Current().Registry
+}
+
 func InitRegistry() {
 	utils.PullImg(RegistryImage)
 
@@ -157,6 +162,6 @@ func newRepository(imageName string) (distribution.Repository, error) {
 	if err != nil {
 		return nil, err
 	}
-	return rclient.NewRepository(ref.(reference.Named), "http://"+RegistryHost, http.DefaultTransport)
+	return rclient.NewRepository(ref.(reference.Named), "http://"+RegistryHost(), http.DefaultTransport)
 
 }
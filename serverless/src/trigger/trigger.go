@@ -4,18 +4,43 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math/rand"
+	"minik8s/apiObject"
 	"minik8s/apiserver/src/url"
 	"minik8s/entity"
+	"minik8s/kubelet/src/runtime/runtime"
 	"minik8s/util/httputil"
 	"minik8s/util/logger"
 	"net/http"
-	"strings"
+	netURL "net/url"
+	"path"
 )
 
-func getFuncPods(funcName string) []entity.PodStatus {
-	URL := url.Prefix + strings.Replace(url.FuncPodsURLWithSpecifiedName, ":name", funcName, -1)
+// getFuncPods gets the running pods that serve the function. The pods of a
+// function deployed before they were labeled by FunctionLabel are only known
+// by the uid of its ReplicaSet.
+func getFuncPods(funcName string) []*entity.PodStatus {
+	if pods := getRunningPods(apiObject.FunctionLabel + "=" + funcName); len(pods) > 0 {
+		return pods
+	}
+	replicaSet := apiObject.ReplicaSet{}
+	err := httputil.GetAndUnmarshal(url.Prefix+path.Join(url.ReplicaSetURL, "function", funcName), &replicaSet)
+	if err != nil {
+		if !httputil.IsNotFound(err) {
+			logger.Error(err.Error())
+		}
+		return nil
+	}
+	return getRunningPods(runtime.KubernetesReplicaSetUIDLabel + "=" + replicaSet.UID())
+}
+
+// getRunningPods gets the running pods that match labelSelector
+func getRunningPods(labelSelector string) []*entity.PodStatus {
+	query := netURL.Values{}
+	query.Set("labelSelector", labelSelector)
+	query.Set("fieldSelector", "status.phase=Running")
+	URL := url.Prefix + url.PodURL + "?" + query.Encode()
 
-	var pods []entity.PodStatus
+	var pods []*entity.PodStatus
 	err := httputil.GetAndUnmarshal(URL, &pods)
 	if err != nil {
 		logger.Error(err.Error())
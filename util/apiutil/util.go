@@ -2,7 +2,6 @@ package apiutil
 
 import (
 	"fmt"
-	"io/ioutil"
 	"minik8s/util/httputil"
 )
 
@@ -12,7 +11,10 @@ func ApplyApiObjectToApiServer(URL string, object interface{}) {
 		fmt.Println(err.Error())
 		return
 	}
-	respBody, _ := ioutil.ReadAll(resp.Body)
-	defer resp.Body.Close()
-	fmt.Println(string(respBody))
+	content, err := httputil.ReadResponse(resp)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	fmt.Println(content)
 }
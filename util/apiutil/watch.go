@@ -0,0 +1,93 @@
+package apiutil
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	apiURL "minik8s/apiserver/src/url"
+	"minik8s/entity"
+	"minik8s/util/httputil"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+type WatchHandler func(event *entity.WatchEvent)
+
+// ListPageSize is how many objects List asks the api-server for at a time
+const ListPageSize = 500
+
+// List gets all the objects of the list URL into target, a pointer to a
+// slice, a page at a time, and returns the resourceVersion that the list is
+// consistent with.
+func List(URL string, target interface{}) (resourceVersion string, err error) {
+	listURL, err := url.Parse(URL)
+	if err != nil {
+		return "", err
+	}
+	query := listURL.Query()
+	query.Set("limit", strconv.Itoa(ListPageSize))
+	items := reflect.ValueOf(target).Elem()
+	for {
+		listURL.RawQuery = query.Encode()
+		page := reflect.New(items.Type())
+		var next string
+		if resourceVersion, next, err = listPage(listURL.String(), page.Interface()); err != nil {
+			return "", err
+		}
+		items.Set(reflect.AppendSlice(items, page.Elem()))
+		if next == "" {
+			return resourceVersion, nil
+		}
+		query.Set("continue", next)
+	}
+}
+
+func listPage(URL string, target interface{}) (resourceVersion, next string, err error) {
+	resp, err := httputil.Get(URL)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	if err = httputil.CheckResponse(resp); err != nil {
+		return "", "", err
+	}
+	if err = json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return "", "", err
+	}
+	return resp.Header.Get(apiURL.ResourceVersionHeader), resp.Header.Get(apiURL.ContinueHeader), nil
+}
+
+// Watch streams the events of the list URL after resourceVersion to handler,
+// until the api-server closes the connection. Call it by goroutine.
+func Watch(URL, resourceVersion string, handler WatchHandler) error {
+	query := url.Values{}
+	query.Set("watch", "true")
+	if resourceVersion != "" {
+		query.Set("resourceVersion", resourceVersion)
+	}
+	resp, err := httputil.Get(URL + "?" + query.Encode())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err = httputil.CheckResponse(resp); err != nil {
+		return fmt.Errorf("watch %s: %s", URL, err.Error())
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		event := &entity.WatchEvent{}
+		if err = json.Unmarshal(scanner.Bytes(), event); err != nil {
+			return err
+		}
+		if event.Type == entity.WatchError {
+			var msg string
+			_ = json.Unmarshal(event.Object, &msg)
+			return fmt.Errorf("watch %s failed: %s", URL, msg)
+		}
+		handler(event)
+	}
+	return scanner.Err()
+}
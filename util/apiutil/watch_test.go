@@ -0,0 +1,31 @@
+package apiutil
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	apiURL "minik8s/apiserver/src/url"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListPages(t *testing.T) {
+	pages := map[string][]string{"": {"a", "b"}, "next": {"c"}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "app=foo", r.URL.Query().Get("labelSelector"))
+		assert.NotEqual(t, "", r.URL.Query().Get("limit"))
+		token := r.URL.Query().Get("continue")
+		w.Header().Set(apiURL.ResourceVersionHeader, "42")
+		if token == "" {
+			w.Header().Set(apiURL.ContinueHeader, "next")
+		}
+		_ = json.NewEncoder(w).Encode(pages[token])
+	}))
+	defer server.Close()
+
+	var names []string
+	resourceVersion, err := List(server.URL+"/api/v1/pods/?labelSelector=app%3Dfoo", &names)
+	assert.Nil(t, err)
+	assert.Equal(t, "42", resourceVersion)
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+}
@@ -0,0 +1,293 @@
+package certutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"minik8s/global"
+	"net"
+	"os"
+	"path"
+	"time"
+)
+
+const (
+	caValidity   = 10 * 365 * 24 * time.Hour
+	certValidity = 365 * 24 * time.Hour
+)
+
+// CertFile and KeyFile are where the certificate and the key of name are,
+// e.g. /etc/minik8s/ca.crt and /etc/minik8s/ca.key for the cluster CA.
+func CertFile(name string) string {
+	return path.Join(global.CredentialsDir, name+".crt")
+}
+
+func KeyFile(name string) string {
+	return path.Join(global.CredentialsDir, name+".key")
+}
+
+const (
+	// CA is the name of the cluster CA, which signs every other certificate
+	CA = "ca"
+	// ApiServer is the name of the serving certificate of the api-server
+	ApiServer = "apiserver"
+)
+
+// KeyPair is a certificate and its private key
+type KeyPair struct {
+	Cert *x509.Certificate
+	Key  crypto.Signer
+}
+
+func NewKey() (crypto.Signer, error) {
+	return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+}
+
+// NewCA returns a self-signed CA
+func NewCA(commonName string) (*KeyPair, error) {
+	key, err := NewKey()
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName},
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	cert, err := sign(template, key.Public(), nil, key, caValidity)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{Cert: cert, Key: key}, nil
+}
+
+// IssueServing signs a certificate that serves the given hosts, which are
+// dns names or ips.
+func (ca *KeyPair) IssueServing(commonName string, hosts []string) (*KeyPair, error) {
+	key, err := NewKey()
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: commonName},
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	cert, err := sign(template, key.Public(), ca.Cert, ca.Key, certValidity)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{Cert: cert, Key: key}, nil
+}
+
+// IssueClient signs a client certificate, which the api-server takes as the
+// user of the common name in the groups of the organizations.
+func (ca *KeyPair) IssueClient(user string, groups []string) (*KeyPair, error) {
+	key, err := NewKey()
+	if err != nil {
+		return nil, err
+	}
+	cert, err := ca.signClient(pkix.Name{CommonName: user, Organization: groups}, key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{Cert: cert, Key: key}, nil
+}
+
+// SignRequest signs the client certificate asked for by a PEM encoded
+// certificate request, and returns it PEM encoded.
+func (ca *KeyPair) SignRequest(request []byte) ([]byte, error) {
+	csr, err := ParseRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := ca.signClient(csr.Subject, csr.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeCert(cert), nil
+}
+
+func (ca *KeyPair) signClient(subject pkix.Name, pub crypto.PublicKey) (*x509.Certificate, error) {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: subject.CommonName, Organization: subject.Organization},
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	return sign(template, pub, ca.Cert, ca.Key, certValidity)
+}
+
+// sign signs template by parent, or self-signs it if parent is nil
+func sign(template *x509.Certificate, pub crypto.PublicKey, parent *x509.Certificate, parentKey crypto.Signer, validity time.Duration) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	template.SerialNumber = serial
+	// a little early, in case the clocks of the nodes differ
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(validity)
+	if parent == nil {
+		parent = template
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, pub, parentKey)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+// NewRequest returns a PEM encoded certificate request for a client
+// certificate of user in groups, signed by key.
+func NewRequest(key crypto.Signer, user string, groups []string) ([]byte, error) {
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: user, Organization: groups},
+	}, key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}), nil
+}
+
+// ParseRequest parses a PEM encoded certificate request and checks that it
+// is signed by the key it carries.
+func ParseRequest(request []byte) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode(request)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, fmt.Errorf("no PEM encoded certificate request")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if err = csr.CheckSignature(); err != nil {
+		return nil, err
+	}
+	if csr.Subject.CommonName == "" {
+		return nil, fmt.Errorf("the certificate request has no common name")
+	}
+	return csr, nil
+}
+
+func EncodeCert(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
+
+func EncodeKey(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// Write writes the key pair to certFile and keyFile, only readable by the
+// owner for the key.
+func (kp *KeyPair) Write(certFile, keyFile string) error {
+	keyPem, err := EncodeKey(kp.Key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(path.Dir(certFile), 0700); err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(keyFile, keyPem, 0600); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(certFile, EncodeCert(kp.Cert), 0644)
+}
+
+// Read reads a key pair written by Write
+func Read(certFile, keyFile string) (*KeyPair, error) {
+	certPem, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+	keyPem, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(certPem)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM encoded certificate in %s", certFile)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	block, _ = pem.Decode(keyPem)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM encoded key in %s", keyFile)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported key in %s", keyFile)
+	}
+	return &KeyPair{Cert: cert, Key: signer}, nil
+}
+
+// ReadCA reads the cluster CA in global.CredentialsDir
+func ReadCA() (*KeyPair, error) {
+	return Read(CertFile(CA), KeyFile(CA))
+}
+
+// Bootstrap creates the cluster CA unless it exists, and issues the serving
+// certificate of the api-server for the given hosts, plus localhost, unless
+// it exists. With force the serving certificate is issued again anyway.
+func Bootstrap(hosts []string, force bool) error {
+	ca, err := ReadCA()
+	if os.IsNotExist(err) {
+		if ca, err = NewCA("minik8s-ca"); err != nil {
+			return err
+		}
+		if err = ca.Write(CertFile(CA), KeyFile(CA)); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	if _, err = os.Stat(CertFile(ApiServer)); err == nil && !force {
+		return nil
+	}
+	hosts = append(hosts, "localhost", "127.0.0.1")
+	if hostname, err := os.Hostname(); err == nil {
+		hosts = append(hosts, hostname)
+	}
+	serving, err := ca.IssueServing("minik8s-apiserver", unique(hosts))
+	if err != nil {
+		return err
+	}
+	return serving.Write(CertFile(ApiServer), KeyFile(ApiServer))
+}
+
+func unique(values []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, value := range values {
+		if value != "" && !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,98 @@
+package certutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+)
+
+func tlsCertificate(t *testing.T, kp *KeyPair) tls.Certificate {
+	keyPem, err := EncodeKey(kp.Key)
+	assert.NoError(t, err)
+	cert, err := tls.X509KeyPair(EncodeCert(kp.Cert), keyPem)
+	assert.NoError(t, err)
+	return cert
+}
+
+func TestTLS(t *testing.T) {
+	ca, err := NewCA("test-ca")
+	assert.NoError(t, err)
+	serving, err := ca.IssueServing("test-server", []string{"127.0.0.1", "localhost"})
+	assert.NoError(t, err)
+
+	// the client certificate comes from a certificate request
+	key, err := NewKey()
+	assert.NoError(t, err)
+	request, err := NewRequest(key, "system:node:worker1", []string{"system:components"})
+	assert.NoError(t, err)
+	certPem, err := ca.SignRequest(request)
+	assert.NoError(t, err)
+	keyPem, err := EncodeKey(key)
+	assert.NoError(t, err)
+	clientCert, err := tls.X509KeyPair(certPem, keyPem)
+	assert.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.VerifiedChains) > 0 {
+			subject := r.TLS.VerifiedChains[0][0].Subject
+			_, _ = w.Write([]byte(subject.CommonName + " " + subject.Organization[0]))
+		}
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{tlsCertificate(t, serving)},
+		ClientCAs:    pool,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{clientCert},
+	}}}
+	resp, err := client.Get(server.URL)
+	if assert.NoError(t, err) {
+		body, _ := ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		assert.Equal(t, "system:node:worker1 system:components", string(body))
+	}
+
+	// a server of another CA is not trusted
+	other, _ := NewCA("other-ca")
+	otherPool := x509.NewCertPool()
+	otherPool.AddCert(other.Cert)
+	client.Transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: otherPool}}
+	_, err = client.Get(server.URL)
+	assert.Error(t, err)
+}
+
+func TestReadWrite(t *testing.T) {
+	dir := t.TempDir()
+	ca, err := NewCA("test-ca")
+	assert.NoError(t, err)
+	certFile, keyFile := path.Join(dir, "ca.crt"), path.Join(dir, "ca.key")
+	assert.NoError(t, ca.Write(certFile, keyFile))
+
+	read, err := Read(certFile, keyFile)
+	if assert.NoError(t, err) {
+		assert.Equal(t, ca.Cert.Raw, read.Cert.Raw)
+		assert.True(t, read.Cert.IsCA)
+	}
+}
+
+func TestParseRequest(t *testing.T) {
+	_, err := ParseRequest([]byte("not a request"))
+	assert.Error(t, err)
+
+	key, _ := NewKey()
+	request, _ := NewRequest(key, "", nil)
+	_, err = ParseRequest(request)
+	assert.Error(t, err)
+}
@@ -0,0 +1,282 @@
+package configutil
+
+import (
+	"flag"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"minik8s/apiserver/src/url"
+	"minik8s/global"
+	"net"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// Version is the only apiVersion of the config file so far
+	Version = "minik8s/v1"
+	Kind    = "ComponentConfig"
+)
+
+// Config tells the components where the rest of the cluster is. It is read
+// from a yaml file, then overridden by the environment, then by the flags.
+type Config struct {
+	ApiVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+
+	// ApiServer is the host:port of the api-server, which it listens on too
+	ApiServer     string   `yaml:"apiServer"`
+	EtcdEndpoints []string `yaml:"etcdEndpoints"`
+	Redis         string   `yaml:"redis"`
+	// Registry is the host:port of the registry of the function images
+	Registry string `yaml:"registry"`
+	// PodCIDR and ServiceCIDR are the first ip handed out to a pod or a
+	// service, and the prefix length of the network they are attached to.
+	PodCIDR     string `yaml:"podCIDR"`
+	ServiceCIDR string `yaml:"serviceCIDR"`
+
+	SyncPeriods SyncPeriods `yaml:"syncPeriods"`
+}
+
+// SyncPeriods are how often the components do their periodic work
+type SyncPeriods struct {
+	// NodeRegister is how often the kubelet registers its node again
+	NodeRegister time.Duration `yaml:"nodeRegister"`
+	// PodRelist is how often the kubelet lists the containers of its pods
+	PodRelist time.Duration `yaml:"podRelist"`
+	// PodStatus is how often the kubelet reports the pod statuses that changed
+	PodStatus time.Duration `yaml:"podStatus"`
+	// NodeMonitor is how often the node controller checks the node statuses
+	NodeMonitor time.Duration `yaml:"nodeMonitor"`
+	// DeploymentResync is how often every deployment is synced again
+	DeploymentResync time.Duration `yaml:"deploymentResync"`
+	// GarbageCollect is how often the objects without owners are collected
+	GarbageCollect time.Duration `yaml:"garbageCollect"`
+	// FunctionScale is how often the functions are scaled to their requests
+	FunctionScale time.Duration `yaml:"functionScale"`
+	// EventPurge is how often the api-server deletes the old events
+	EventPurge time.Duration `yaml:"eventPurge"`
+	// GpuJobPoll is how often the gpu server polls the state of its job
+	GpuJobPoll time.Duration `yaml:"gpuJobPoll"`
+}
+
+// Default is the config of a cluster on a single machine
+func Default() *Config {
+	return &Config{
+		ApiVersion:    Version,
+		Kind:          Kind,
+		ApiServer:     "localhost:8080",
+		EtcdEndpoints: []string{"localhost:2379"},
+		Redis:         "localhost:6379",
+		Registry:      "localhost:5000",
+		PodCIDR:       "10.44.0.1/16",
+		ServiceCIDR:   "10.44.127.1/16",
+		SyncPeriods: SyncPeriods{
+			NodeRegister:     time.Minute,
+			PodRelist:        10 * time.Second,
+			PodStatus:        10 * time.Second,
+			NodeMonitor:      5 * time.Second,
+			DeploymentResync: 15 * time.Second,
+			GarbageCollect:   5 * time.Second,
+			FunctionScale:    10 * time.Second,
+			EventPurge:       10 * time.Minute,
+			GpuJobPoll:       time.Minute,
+		},
+	}
+}
+
+var current = Default()
+
+// Current is the config loaded by Load, the default one until then
+func Current() *Config {
+	return current
+}
+
+// File is where the config is unless --config tells otherwise
+func File() string {
+	return path.Join(global.CredentialsDir, "config.yaml")
+}
+
+// option is a field of the config that the environment and the flags can
+// override.
+type option struct {
+	flag  string
+	env   string
+	usage string
+	field func(c *Config) interface{}
+}
+
+var options = []option{
+	{"api-server", "MINIK8S_API_SERVER", "host:port of the api-server", func(c *Config) interface{} { return &c.ApiServer }},
+	{"etcd-endpoints", "MINIK8S_ETCD_ENDPOINTS", "comma separated host:port of the etcd servers", func(c *Config) interface{} { return &c.EtcdEndpoints }},
+	{"redis", "MINIK8S_REDIS", "host:port of redis", func(c *Config) interface{} { return &c.Redis }},
+	{"registry", "MINIK8S_REGISTRY", "host:port of the registry of the function images", func(c *Config) interface{} { return &c.Registry }},
+	{"pod-cidr", "MINIK8S_POD_CIDR", "first pod ip and the prefix length of the pod network", func(c *Config) interface{} { return &c.PodCIDR }},
+	{"service-cidr", "MINIK8S_SERVICE_CIDR", "first service ip and the prefix length of the service network", func(c *Config) interface{} { return &c.ServiceCIDR }},
+	{"node-register-period", "MINIK8S_NODE_REGISTER_PERIOD", "how often the kubelet registers its node", func(c *Config) interface{} { return &c.SyncPeriods.NodeRegister }},
+	{"pod-relist-period", "MINIK8S_POD_RELIST_PERIOD", "how often the kubelet lists the containers of its pods", func(c *Config) interface{} { return &c.SyncPeriods.PodRelist }},
+	{"pod-status-period", "MINIK8S_POD_STATUS_PERIOD", "how often the kubelet reports the pod statuses", func(c *Config) interface{} { return &c.SyncPeriods.PodStatus }},
+	{"node-monitor-period", "MINIK8S_NODE_MONITOR_PERIOD", "how often the node controller checks the nodes", func(c *Config) interface{} { return &c.SyncPeriods.NodeMonitor }},
+	{"deployment-resync-period", "MINIK8S_DEPLOYMENT_RESYNC_PERIOD", "how often every deployment is synced", func(c *Config) interface{} { return &c.SyncPeriods.DeploymentResync }},
+	{"garbage-collect-period", "MINIK8S_GARBAGE_COLLECT_PERIOD", "how often the objects without owners are collected", func(c *Config) interface{} { return &c.SyncPeriods.GarbageCollect }},
+	{"function-scale-period", "MINIK8S_FUNCTION_SCALE_PERIOD", "how often the functions are scaled", func(c *Config) interface{} { return &c.SyncPeriods.FunctionScale }},
+	{"event-purge-period", "MINIK8S_EVENT_PURGE_PERIOD", "how often the old events are deleted", func(c *Config) interface{} { return &c.SyncPeriods.EventPurge }},
+	{"gpu-job-poll-period", "MINIK8S_GPU_JOB_POLL_PERIOD", "how often the gpu server polls its job", func(c *Config) interface{} { return &c.SyncPeriods.GpuJobPoll }},
+}
+
+// AddFlags adds --config and a flag for each option to fs. A flag only
+// overrides the config if it is given.
+func AddFlags(fs *flag.FlagSet) {
+	defaults := Default()
+	fs.String("config", "", "the config file, "+File()+" if it exists by default")
+	for _, o := range options {
+		fs.String(o.flag, format(o.field(defaults)), o.usage+", or $"+o.env)
+	}
+}
+
+// Load reads the config file given by --config, or File if it exists, then
+// applies the environment and the flags given in fs, which must have been
+// added by AddFlags and parsed. The result becomes the Current config.
+func Load(fs *flag.FlagSet) error {
+	file, required := File(), false
+	if f := fs.Lookup("config"); f != nil && f.Value.String() != "" {
+		file, required = f.Value.String(), true
+	}
+	c, err := ReadFile(file, required)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range options {
+		if value := os.Getenv(o.env); value != "" {
+			if err = set(o.field(c), value); err != nil {
+				return fmt.Errorf("$%s: %s", o.env, err.Error())
+			}
+		}
+	}
+	for _, o := range options {
+		if !isSet(fs, o.flag) {
+			continue
+		}
+		if err = set(o.field(c), fs.Lookup(o.flag).Value.String()); err != nil {
+			return fmt.Errorf("--%s: %s", o.flag, err.Error())
+		}
+	}
+
+	if err = c.validate(); err != nil {
+		return err
+	}
+	use(c)
+	return nil
+}
+
+// ReadFile reads the config in file on top of the default one. If there is no
+// such file, the default config is returned unless the file is required.
+func ReadFile(file string, required bool) (*Config, error) {
+	c := Default()
+	content, err := ioutil.ReadFile(file)
+	switch {
+	case os.IsNotExist(err) && !required:
+		return c, nil
+	case err != nil:
+		return nil, err
+	}
+	if err = yaml.Unmarshal(content, c); err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err.Error())
+	}
+	if c.ApiVersion != Version || c.Kind != Kind {
+		return nil, fmt.Errorf("%s: unsupported config %s %s, acceptable is apiVersion %s kind %s", file, c.ApiVersion, c.Kind, Version, Kind)
+	}
+	return c, nil
+}
+
+func isSet(fs *flag.FlagSet, name string) (found bool) {
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	return
+}
+
+func set(field interface{}, value string) error {
+	switch field := field.(type) {
+	case *string:
+		*field = value
+	case *[]string:
+		*field = strings.Split(value, ",")
+	case *time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*field = d
+	}
+	return nil
+}
+
+func format(field interface{}) string {
+	switch field := field.(type) {
+	case *string:
+		return *field
+	case *[]string:
+		return strings.Join(*field, ",")
+	case *time.Duration:
+		return field.String()
+	}
+	return ""
+}
+
+func (c *Config) validate() error {
+	if _, _, err := net.SplitHostPort(c.ApiServer); err != nil {
+		return fmt.Errorf("invalid api-server address \"%s\": %s", c.ApiServer, err.Error())
+	}
+	if len(c.EtcdEndpoints) == 0 {
+		return fmt.Errorf("no etcd endpoints")
+	}
+	for _, cidr := range []string{c.PodCIDR, c.ServiceCIDR} {
+		if ip, _, err := net.ParseCIDR(cidr); err != nil || ip.To4() == nil {
+			return fmt.Errorf("invalid cidr \"%s\", acceptable cidr is like 10.44.0.1/16", cidr)
+		}
+	}
+	for _, o := range options {
+		if d, ok := o.field(c).(*time.Duration); ok && *d <= 0 {
+			return fmt.Errorf("%s must be positive", o.flag)
+		}
+	}
+	return nil
+}
+
+// use makes c the Current config, and points the urls of the api-server at
+// its address.
+func use(c *Config) {
+	current = c
+	url.Hostname, _, _ = net.SplitHostPort(c.ApiServer)
+	url.Prefix = url.HttpsScheme + c.ApiServer
+}
+
+// PodIPRange is the first pod ip and the prefix length of the pod network
+func (c *Config) PodIPRange() (string, int) {
+	return ipRange(c.PodCIDR)
+}
+
+// ServiceIPRange is the first service ip and the prefix length of the
+// service network.
+func (c *Config) ServiceIPRange() (string, int) {
+	return ipRange(c.ServiceCIDR)
+}
+
+// ipRange splits a cidr that has been validated
+func ipRange(cidr string) (string, int) {
+	ip, network, _ := net.ParseCIDR(cidr)
+	ones, _ := network.Mask.Size()
+	return ip.String(), ones
+}
+
+// ApiServerPort is the port the api-server listens on
+func (c *Config) ApiServerPort() string {
+	_, port, _ := net.SplitHostPort(c.ApiServer)
+	return port
+}
@@ -0,0 +1,80 @@
+package configutil
+
+import (
+	"flag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"minik8s/apiserver/src/url"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	file := path.Join(t.TempDir(), "config.yaml")
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestReadFile(t *testing.T) {
+	file := writeConfig(t, `apiVersion: minik8s/v1
+kind: ComponentConfig
+redis: 10.0.0.1:6379
+syncPeriods:
+  podRelist: 3s
+`)
+	c, err := ReadFile(file, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1:6379", c.Redis)
+	assert.Equal(t, 3*time.Second, c.SyncPeriods.PodRelist)
+	// what the file leaves out is the default
+	assert.Equal(t, "localhost:8080", c.ApiServer)
+	assert.Equal(t, 10*time.Second, c.SyncPeriods.PodStatus)
+
+	_, err = ReadFile(writeConfig(t, "apiVersion: minik8s/v2\nkind: ComponentConfig\n"), true)
+	assert.Error(t, err)
+
+	_, err = ReadFile(path.Join(t.TempDir(), "none.yaml"), true)
+	assert.Error(t, err)
+	c, err = ReadFile(path.Join(t.TempDir(), "none.yaml"), false)
+	assert.NoError(t, err)
+	assert.Equal(t, Default(), c)
+}
+
+func TestLoad(t *testing.T) {
+	defer use(Default())
+	file := writeConfig(t, `apiVersion: minik8s/v1
+kind: ComponentConfig
+apiServer: 10.0.0.1:8080
+redis: 10.0.0.1:6379
+registry: 10.0.0.1:5000
+`)
+	assert.NoError(t, os.Setenv("MINIK8S_REDIS", "10.0.0.2:6379"))
+	assert.NoError(t, os.Setenv("MINIK8S_REGISTRY", "10.0.0.2:5000"))
+	defer os.Unsetenv("MINIK8S_REDIS")
+	defer os.Unsetenv("MINIK8S_REGISTRY")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	AddFlags(fs)
+	assert.NoError(t, fs.Parse([]string{"--config", file, "--registry", "10.0.0.3:5000", "--pod-cidr", "10.45.0.1/16"}))
+	assert.NoError(t, Load(fs))
+
+	// the flags override the environment, which overrides the file
+	c := Current()
+	assert.Equal(t, "10.0.0.1:8080", c.ApiServer)
+	assert.Equal(t, "10.0.0.2:6379", c.Redis)
+	assert.Equal(t, "10.0.0.3:5000", c.Registry)
+	base, mask := c.PodIPRange()
+	assert.Equal(t, "10.45.0.1", base)
+	assert.Equal(t, 16, mask)
+	assert.Equal(t, "https://10.0.0.1:8080", url.Prefix)
+	assert.Equal(t, "10.0.0.1", url.Hostname)
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	AddFlags(fs)
+	assert.NoError(t, fs.Parse([]string{"--pod-cidr", "10.45.0.1"}))
+	assert.Error(t, Load(fs))
+}
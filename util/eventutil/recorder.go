@@ -0,0 +1,59 @@
+package eventutil
+
+import (
+	"fmt"
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/url"
+	"minik8s/util/httputil"
+	"minik8s/util/logger"
+	"os"
+	"time"
+)
+
+// Recorder reports the events of a component to the api-server. Reporting is
+// best effort, an event that cannot be reported is only logged.
+type Recorder interface {
+	Event(ref apiObject.ObjectReference, eventType, reason, message string)
+	Eventf(ref apiObject.ObjectReference, eventType, reason, format string, args ...interface{})
+}
+
+type recorder struct {
+	source apiObject.EventSource
+}
+
+// NewRecorder returns the recorder of a component, e.g. "scheduler", on this
+// host.
+func NewRecorder(component string) Recorder {
+	hostname, _ := os.Hostname()
+	return &recorder{source: apiObject.EventSource{Component: component, Host: hostname}}
+}
+
+func (r *recorder) Event(ref apiObject.ObjectReference, eventType, reason, message string) {
+	now := time.Now()
+	event := &apiObject.Event{
+		Base:           apiObject.Base{ApiVersion: "v1", Kind: "Event"},
+		InvolvedObject: ref,
+		Type:           eventType,
+		Reason:         reason,
+		Message:        message,
+		Source:         r.source,
+		Count:          1,
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+	}
+	go post(event)
+}
+
+func (r *recorder) Eventf(ref apiObject.ObjectReference, eventType, reason, format string, args ...interface{}) {
+	r.Event(ref, eventType, reason, fmt.Sprintf(format, args...))
+}
+
+func post(event *apiObject.Event) {
+	resp, err := httputil.PostJson(url.Prefix+url.EventURL, event)
+	if err == nil {
+		_, err = httputil.ReadResponse(resp)
+	}
+	if err != nil {
+		logger.Error("record event %s %s of %s: %s", event.Type, event.Reason, event.InvolvedObject.String(), err.Error())
+	}
+}
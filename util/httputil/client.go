@@ -0,0 +1,120 @@
+package httputil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/ioutil"
+	"minik8s/apiserver/src/url"
+	"minik8s/global"
+	"minik8s/util/certutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+// Credentials identify a user or a component to the api-server. The
+// certificate of the api-server is verified against CertificateAuthority,
+// or the cluster CA in global.CredentialsDir by default.
+type Credentials struct {
+	User                 string `yaml:"user"`
+	Token                string `yaml:"token"`
+	ClientCertificate    string `yaml:"clientCertificate,omitempty"`
+	ClientKey            string `yaml:"clientKey,omitempty"`
+	CertificateAuthority string `yaml:"certificateAuthority,omitempty"`
+}
+
+// Every request of this package goes through client, with the credentials
+// set by UseCredentials.
+var (
+	client      = &http.Client{}
+	bearerToken string
+)
+
+func init() {
+	// the requests trust the cluster CA even if no credentials are loaded
+	if err := UseCredentials(&Credentials{}); err != nil {
+		fmt.Println(err.Error())
+	}
+}
+
+// CredentialsFile returns where the credentials of a component are, e.g.
+// /etc/minik8s/kubelet.yaml
+func CredentialsFile(component string) string {
+	return path.Join(global.CredentialsDir, component+".yaml")
+}
+
+// LoadCredentials uses the credentials in the yaml file at filePath. If there
+// is no such file the requests stay anonymous.
+func LoadCredentials(filePath string) error {
+	content, err := ioutil.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	creds := &Credentials{}
+	if err = yaml.Unmarshal(content, creds); err != nil {
+		return err
+	}
+	return UseCredentials(creds)
+}
+
+// UseCredentials makes the following requests authenticate with creds
+func UseCredentials(creds *Credentials) error {
+	tlsConfig := &tls.Config{}
+	caFile := creds.CertificateAuthority
+	if caFile == "" {
+		caFile = certutil.CertFile(certutil.CA)
+	}
+	if caPem, err := ioutil.ReadFile(caFile); err == nil {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caPem) {
+			return fmt.Errorf("no PEM encoded certificate in %s", caFile)
+		}
+	} else if creds.CertificateAuthority != "" || !os.IsNotExist(err) {
+		return err
+	}
+	if creds.ClientCertificate != "" {
+		cert, err := tls.LoadX509KeyPair(creds.ClientCertificate, creds.ClientKey)
+		if err != nil {
+			return err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	client.Transport = transport
+	bearerToken = creds.Token
+	return nil
+}
+
+// NewRequest is http.NewRequest with the credentials. They are only sent to
+// the api-server, never to e.g. the pods of a function.
+func NewRequest(method, URL string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, URL, body)
+	if err != nil {
+		return nil, err
+	}
+	if bearerToken != "" && strings.HasPrefix(URL, url.Prefix) {
+		req.Header.Set("Authorization", "Bearer "+bearerToken)
+	}
+	return req, nil
+}
+
+// Do sends req, it must come from NewRequest
+func Do(req *http.Request) (*http.Response, error) {
+	return client.Do(req)
+}
+
+func Get(URL string) (*http.Response, error) {
+	req, err := NewRequest(http.MethodGet, URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return Do(req)
+}
@@ -1,25 +1,27 @@
 package httputil
 
 import (
-	"io/ioutil"
 	"net/http"
 )
 
-func DeleteWithoutBody(URL string) string {
-	req, err := http.NewRequest(http.MethodDelete, URL, nil)
+// Delete sends a DELETE to URL, returning the StatusError if it failed
+func Delete(URL string) (string, error) {
+	req, err := NewRequest(http.MethodDelete, URL, nil)
 	if err != nil {
-		return err.Error()
+		return "", err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := Do(req)
 	if err != nil {
-		return err.Error()
+		return "", err
 	}
-	defer resp.Body.Close()
+	return ReadResponse(resp)
+}
 
-	body, err := ioutil.ReadAll(resp.Body)
+func DeleteWithoutBody(URL string) string {
+	body, err := Delete(URL)
 	if err != nil {
 		return err.Error()
 	}
-	return string(body)
+	return body
 }
@@ -3,14 +3,17 @@ package httputil
 import (
 	"encoding/json"
 	"io/ioutil"
-	"net/http"
 )
 
 func GetAndUnmarshal(URL string, target interface{}) error {
-	resp, err := http.Get(URL)
+	resp, err := Get(URL)
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
+	if err = CheckResponse(resp); err != nil {
+		return err
+	}
 	var content []byte
 	content, err = ioutil.ReadAll(resp.Body)
 	if err != nil {
@@ -0,0 +1,22 @@
+package httputil
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// Patch sends a PATCH of the given content type to URL, returning the
+// patched object, or the StatusError if it failed.
+func Patch(URL, contentType string, patch []byte) (string, error) {
+	req, err := NewRequest(http.MethodPatch, URL, bytes.NewReader(patch))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := Do(req)
+	if err != nil {
+		return "", err
+	}
+	return ReadResponse(resp)
+}
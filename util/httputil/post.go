@@ -6,25 +6,24 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
 func PostJson(URL string, content interface{}) (*http.Response, error) {
-	cli := http.Client{}
 	b, _ := json.Marshal(content)
-	req, err := http.NewRequest(http.MethodPost, URL, bytes.NewReader(b))
+	req, err := NewRequest(http.MethodPost, URL, bytes.NewReader(b))
 	if err != nil {
 		return nil, err
 	}
-	return cli.Do(req)
+	return Do(req)
 }
 
 func PostString(URL string, content string) (*http.Response, error) {
-	cli := http.Client{}
-	req, err := http.NewRequest(http.MethodPost, URL, bytes.NewReader([]byte(content)))
+	req, err := NewRequest(http.MethodPost, URL, bytes.NewReader([]byte(content)))
 	if err != nil {
 		return nil, err
 	}
-	return cli.Do(req)
+	return Do(req)
 }
 
 func PostForm(URL string, form map[string]string) string {
@@ -34,12 +33,19 @@ func PostForm(URL string, form map[string]string) string {
 	}
 
 	var err error
+	var req *http.Request
 	var resp *http.Response
-	if resp, err = http.PostForm(URL, values); err == nil {
-		defer resp.Body.Close()
-		var body []byte
-		if body, err = ioutil.ReadAll(resp.Body); err == nil {
-			return string(body)
+	if req, err = NewRequest(http.MethodPost, URL, strings.NewReader(values.Encode())); err == nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if resp, err = Do(req); err == nil {
+			defer resp.Body.Close()
+			var body []byte
+			if err = CheckResponse(resp); err != nil {
+				return err.Error()
+			}
+			if body, err = ioutil.ReadAll(resp.Body); err == nil {
+				return string(body)
+			}
 		}
 	}
 	return err.Error()
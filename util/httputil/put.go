@@ -8,21 +8,31 @@ import (
 )
 
 func PutForm(URL string, form map[string]string) string {
-	cli := http.Client{}
 	formJson, _ := json.Marshal(form)
 	r := bytes.NewReader(formJson)
-	req, _ := http.NewRequest(http.MethodPut, URL, r)
+	req, err := NewRequest(http.MethodPut, URL, r)
+	if err != nil {
+		return err.Error()
+	}
 
-	resp, _ := cli.Do(req)
+	resp, err := Do(req)
+	if err != nil {
+		return err.Error()
+	}
+	defer resp.Body.Close()
+	if err = CheckResponse(resp); err != nil {
+		return err.Error()
+	}
 	body, _ := ioutil.ReadAll(resp.Body)
 	return string(body)
 }
 
 func PutJson(URL string, v interface{}) (*http.Response, error) {
-	cli := http.Client{}
 	vJson, _ := json.Marshal(v)
 	r := bytes.NewReader(vJson)
-	req, _ := http.NewRequest(http.MethodPut, URL, r)
-
-	return cli.Do(req)
+	req, err := NewRequest(http.MethodPut, URL, r)
+	if err != nil {
+		return nil, err
+	}
+	return Do(req)
 }
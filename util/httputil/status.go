@@ -0,0 +1,182 @@
+package httputil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"minik8s/entity"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// APIStatus is implemented by the errors that know the Status the api-server
+// answers them with.
+type APIStatus interface {
+	Status() entity.Status
+}
+
+// StatusError is a failed request. The api-server answers with its Status,
+// and the clients get it back from CheckResponse.
+type StatusError struct {
+	ErrStatus entity.Status
+}
+
+func (e *StatusError) Error() string {
+	return e.ErrStatus.Message
+}
+
+func (e *StatusError) Status() entity.Status {
+	return e.ErrStatus
+}
+
+func newStatusError(code int, reason, message string, details *entity.StatusDetails) *StatusError {
+	return &StatusError{ErrStatus: entity.Status{Code: code, Reason: reason, Message: message, Details: details}}
+}
+
+func NewBadRequest(message string) *StatusError {
+	return newStatusError(http.StatusBadRequest, entity.StatusReasonBadRequest, message, nil)
+}
+
+func NewUnauthorized(message string) *StatusError {
+	return newStatusError(http.StatusUnauthorized, entity.StatusReasonUnauthorized, message, nil)
+}
+
+func NewForbidden(message string) *StatusError {
+	return newStatusError(http.StatusForbidden, entity.StatusReasonForbidden, message, nil)
+}
+
+func NewNotFound(kind, namespace, name string) *StatusError {
+	return newStatusError(http.StatusNotFound, entity.StatusReasonNotFound,
+		fmt.Sprintf("no such %s %s", kind, path.Join(namespace, name)),
+		&entity.StatusDetails{Kind: kind, Namespace: namespace, Name: name})
+}
+
+func NewMethodNotSupported(method, path string) *StatusError {
+	return newStatusError(http.StatusMethodNotAllowed, entity.StatusReasonMethodNotAllowed,
+		fmt.Sprintf("%s %s is not supported", method, path), nil)
+}
+
+func NewUnsupportedMediaType(contentType string) *StatusError {
+	return newStatusError(http.StatusUnsupportedMediaType, entity.StatusReasonUnsupportedMediaType,
+		fmt.Sprintf("unsupported content type %q", contentType), nil)
+}
+
+func NewAlreadyExists(kind, namespace, name string) *StatusError {
+	return newStatusError(http.StatusConflict, entity.StatusReasonAlreadyExists,
+		fmt.Sprintf("%s %s already exists", kind, path.Join(namespace, name)),
+		&entity.StatusDetails{Kind: kind, Namespace: namespace, Name: name})
+}
+
+// NewConflict is a write based on a stale resourceVersion, the client should
+// re-read the object and retry.
+func NewConflict(message string) *StatusError {
+	return newStatusError(http.StatusConflict, entity.StatusReasonConflict, message, nil)
+}
+
+func NewInvalid(kind, namespace, name string, err error) *StatusError {
+	return newStatusError(http.StatusUnprocessableEntity, entity.StatusReasonInvalid,
+		fmt.Sprintf("%s %s is invalid: %s", kind, path.Join(namespace, name), err.Error()),
+		&entity.StatusDetails{Kind: kind, Namespace: namespace, Name: name})
+}
+
+// NewFieldsInvalid is NewInvalid with a cause for each invalid field, all of
+// them being in the message too.
+func NewFieldsInvalid(kind, namespace, name string, causes []entity.StatusCause) *StatusError {
+	msgs := make([]string, len(causes))
+	for i, cause := range causes {
+		msgs[i] = cause.Field + ": " + cause.Message
+	}
+	return newStatusError(http.StatusUnprocessableEntity, entity.StatusReasonInvalid,
+		fmt.Sprintf("%s %s is invalid: %s", kind, path.Join(namespace, name), strings.Join(msgs, "; ")),
+		&entity.StatusDetails{Kind: kind, Namespace: namespace, Name: name, Causes: causes})
+}
+
+// NewExpired is a continue token whose revision has been compacted, the client
+// has to list again from the start.
+func NewExpired(message string) *StatusError {
+	return newStatusError(http.StatusGone, entity.StatusReasonExpired, message, nil)
+}
+
+func NewInternalError(err error) *StatusError {
+	return newStatusError(http.StatusInternalServerError, entity.StatusReasonInternalError, err.Error(), nil)
+}
+
+// StatusOf returns the Status err is answered with, an error that does not
+// know it is an internal error.
+func StatusOf(err error) entity.Status {
+	if status, ok := err.(APIStatus); ok {
+		return status.Status()
+	}
+	return NewInternalError(err).Status()
+}
+
+// ReasonForError returns the reason of err, or "" if it has no Status
+func ReasonForError(err error) string {
+	if status, ok := err.(APIStatus); ok {
+		return status.Status().Reason
+	}
+	return ""
+}
+
+func IsBadRequest(err error) bool {
+	return ReasonForError(err) == entity.StatusReasonBadRequest
+}
+
+func IsUnauthorized(err error) bool {
+	return ReasonForError(err) == entity.StatusReasonUnauthorized
+}
+
+func IsForbidden(err error) bool {
+	return ReasonForError(err) == entity.StatusReasonForbidden
+}
+
+func IsNotFound(err error) bool {
+	return ReasonForError(err) == entity.StatusReasonNotFound
+}
+
+func IsAlreadyExists(err error) bool {
+	return ReasonForError(err) == entity.StatusReasonAlreadyExists
+}
+
+func IsConflict(err error) bool {
+	return ReasonForError(err) == entity.StatusReasonConflict
+}
+
+func IsInvalid(err error) bool {
+	return ReasonForError(err) == entity.StatusReasonInvalid
+}
+
+func IsExpired(err error) bool {
+	return ReasonForError(err) == entity.StatusReasonExpired
+}
+
+// CheckResponse returns nil if resp is a success, or else the StatusError in
+// its body, which it consumes.
+func CheckResponse(resp *http.Response) error {
+	if resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+	content, _ := ioutil.ReadAll(resp.Body)
+	status := entity.Status{}
+	if err := json.Unmarshal(content, &status); err != nil || status.Reason == "" {
+		// not answered by a handler, e.g. a proxy in between
+		status = entity.Status{Code: resp.StatusCode, Message: string(content)}
+		if status.Message == "" {
+			status.Message = http.StatusText(resp.StatusCode)
+		}
+	}
+	status.Code = resp.StatusCode
+	return &StatusError{ErrStatus: status}
+}
+
+// ReadResponse reads and closes the body of resp, returning the StatusError
+// instead if resp is not a success.
+func ReadResponse(resp *http.Response) (string, error) {
+	defer resp.Body.Close()
+	if err := CheckResponse(resp); err != nil {
+		return "", err
+	}
+	content, err := ioutil.ReadAll(resp.Body)
+	return string(content), err
+}
@@ -0,0 +1,50 @@
+package httputil
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"minik8s/entity"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			_, _ = w.Write([]byte("ok"))
+		case "/missing":
+			status := NewNotFound("ReplicaSet", "default", "rs1").Status()
+			w.WriteHeader(status.Code)
+			_ = json.NewEncoder(w).Encode(status)
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	resp, err := Get(server.URL + "/ok")
+	assert.Nil(t, err)
+	body, err := ReadResponse(resp)
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", body)
+
+	resp, _ = Get(server.URL + "/missing")
+	_, err = ReadResponse(resp)
+	assert.True(t, IsNotFound(err))
+	assert.Equal(t, "no such ReplicaSet default/rs1", err.Error())
+	assert.Equal(t, &entity.StatusDetails{Kind: "ReplicaSet", Namespace: "default", Name: "rs1"}, err.(APIStatus).Status().Details)
+
+	resp, _ = Get(server.URL + "/proxy")
+	_, err = ReadResponse(resp)
+	assert.Equal(t, http.StatusBadGateway, StatusOf(err).Code)
+	assert.Equal(t, "", ReasonForError(err))
+}
+
+func TestStatusOf(t *testing.T) {
+	assert.Equal(t, http.StatusInternalServerError, StatusOf(errors.New("etcd is down")).Code)
+	assert.True(t, IsAlreadyExists(NewAlreadyExists("Namespace", "", "test")))
+	assert.False(t, IsConflict(NewAlreadyExists("Namespace", "", "test")))
+}
@@ -0,0 +1,48 @@
+package leaderelection
+
+import (
+	"minik8s/apiObject"
+	"minik8s/apiserver/src/url"
+	"minik8s/util/httputil"
+	"path"
+)
+
+// leaseClient reads and writes the lease, an update must fail if the lease
+// was updated since it was read.
+type leaseClient interface {
+	// get returns nil if there is no such lease
+	get(name string) (*apiObject.Lease, error)
+	create(lease *apiObject.Lease) error
+	update(lease *apiObject.Lease) error
+}
+
+// apiServerClient keeps the lease in the api-server, which updates it only at
+// the resourceVersion it was read at.
+type apiServerClient struct{}
+
+func (c *apiServerClient) get(name string) (*apiObject.Lease, error) {
+	lease := &apiObject.Lease{}
+	if err := httputil.GetAndUnmarshal(url.Prefix+path.Join(url.LeaseURL, name), lease); err != nil {
+		if httputil.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return lease, nil
+}
+
+func (c *apiServerClient) create(lease *apiObject.Lease) error {
+	resp, err := httputil.PostJson(url.Prefix+url.LeaseURL, lease)
+	if err == nil {
+		_, err = httputil.ReadResponse(resp)
+	}
+	return err
+}
+
+func (c *apiServerClient) update(lease *apiObject.Lease) error {
+	resp, err := httputil.PutJson(url.Prefix+path.Join(url.LeaseURL, lease.Name()), lease)
+	if err == nil {
+		_, err = httputil.ReadResponse(resp)
+	}
+	return err
+}
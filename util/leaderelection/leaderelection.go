@@ -0,0 +1,252 @@
+package leaderelection
+
+import (
+	"flag"
+	"fmt"
+	"minik8s/apiObject"
+	"minik8s/util/logger"
+	"minik8s/util/uidutil"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var log = logger.Log("Leader election")
+
+// Config tells how the replicas of a component elect their leader, by a
+// Lease of the api-server.
+type Config struct {
+	// Enabled is false to run the component as the only replica
+	Enabled bool
+	// Lease is the name of the lease, e.g. kube-scheduler
+	Lease string
+	// Identity tells the replicas apart, the hostname and a random suffix by
+	// default.
+	Identity string
+	// LeaseDuration is how long the standbys wait after the lease was last
+	// renewed before they take it over.
+	LeaseDuration time.Duration
+	// RenewDeadline is how long the leader keeps trying to renew the lease
+	// before it gives up leading.
+	RenewDeadline time.Duration
+	// RetryPeriod is how often the lease is acquired or renewed
+	RetryPeriod time.Duration
+}
+
+const (
+	DefaultLeaseDuration = 15 * time.Second
+	DefaultRenewDeadline = 10 * time.Second
+	DefaultRetryPeriod   = 2 * time.Second
+)
+
+// DefaultConfig is the config of the replicas of a component that elect
+// their leader by the given lease.
+func DefaultConfig(lease string) Config {
+	hostname, _ := os.Hostname()
+	return Config{
+		Enabled:       true,
+		Lease:         lease,
+		Identity:      hostname + "_" + uidutil.New(),
+		LeaseDuration: DefaultLeaseDuration,
+		RenewDeadline: DefaultRenewDeadline,
+		RetryPeriod:   DefaultRetryPeriod,
+	}
+}
+
+// AddFlags lets the flags of the component change the config
+func (c *Config) AddFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&c.Enabled, "leader-elect", c.Enabled, "elect a leader among the replicas, only the leader works")
+	fs.DurationVar(&c.LeaseDuration, "leader-elect-lease-duration", c.LeaseDuration, "how long the standbys wait after the lease was last renewed before they take it over")
+	fs.DurationVar(&c.RenewDeadline, "leader-elect-renew-deadline", c.RenewDeadline, "how long the leader keeps trying to renew the lease before it gives up leading")
+	fs.DurationVar(&c.RetryPeriod, "leader-elect-retry-period", c.RetryPeriod, "how often the lease is acquired or renewed")
+}
+
+func (c *Config) validate() error {
+	if c.Lease == "" || c.Identity == "" {
+		return fmt.Errorf("the lease and the identity are required")
+	}
+	if c.RetryPeriod <= 0 || c.RenewDeadline <= c.RetryPeriod || c.LeaseDuration <= c.RenewDeadline {
+		return fmt.Errorf("the lease duration must be longer than the renew deadline, which must be longer than the retry period")
+	}
+	return nil
+}
+
+// Callbacks are called as the replica starts and stops leading
+type Callbacks struct {
+	// OnStartedLeading is run in a goroutine of its own
+	OnStartedLeading func()
+	OnStoppedLeading func()
+	// OnNewLeader is called when another replica is seen leading, optional
+	OnNewLeader func(identity string)
+}
+
+// LeaderElector acquires the lease of a replica and renews it while leading
+type LeaderElector struct {
+	config    Config
+	callbacks Callbacks
+	client    leaseClient
+	now       func() time.Time
+
+	lock sync.Mutex
+	// observedSpec is the spec of the lease last read, and observedTime when
+	// it was first read with that spec. The lease expires by the clock of
+	// the replica, whatever the clocks of the others say.
+	observedSpec   apiObject.LeaseSpec
+	observedTime   time.Time
+	reportedLeader string
+}
+
+func NewLeaderElector(config Config, callbacks Callbacks) (*LeaderElector, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	if callbacks.OnStartedLeading == nil || callbacks.OnStoppedLeading == nil {
+		return nil, fmt.Errorf("OnStartedLeading and OnStoppedLeading are required")
+	}
+	return &LeaderElector{
+		config:    config,
+		callbacks: callbacks,
+		client:    &apiServerClient{},
+		now:       time.Now,
+	}, nil
+}
+
+// Run waits until the replica leads, runs OnStartedLeading, and returns once
+// the lease could not be renewed within the renew deadline, after calling
+// OnStoppedLeading.
+func (le *LeaderElector) Run() {
+	le.acquire()
+	go le.callbacks.OnStartedLeading()
+	le.renew()
+	le.callbacks.OnStoppedLeading()
+}
+
+func (le *LeaderElector) acquire() {
+	log("%s tries to acquire lease %s", le.config.Identity, le.config.Lease)
+	for !le.tryAcquireOrRenew() {
+		time.Sleep(le.config.RetryPeriod)
+	}
+	log("%s acquired lease %s", le.config.Identity, le.config.Lease)
+}
+
+func (le *LeaderElector) renew() {
+	lastRenew := le.now()
+	for {
+		time.Sleep(le.config.RetryPeriod)
+		if le.tryAcquireOrRenew() {
+			lastRenew = le.now()
+		} else if le.now().Sub(lastRenew) > le.config.RenewDeadline || !le.IsLeader() {
+			log("%s lost lease %s", le.config.Identity, le.config.Lease)
+			return
+		}
+	}
+}
+
+// tryAcquireOrRenew takes the lease if it is free or expired, or renews it if
+// the replica holds it already. It tells whether the replica leads now.
+func (le *LeaderElector) tryAcquireOrRenew() bool {
+	now := le.now()
+	spec := apiObject.LeaseSpec{
+		HolderIdentity:       le.config.Identity,
+		LeaseDurationSeconds: int(le.config.LeaseDuration / time.Second),
+		AcquireTime:          now,
+		RenewTime:            now,
+	}
+
+	lease, err := le.client.get(le.config.Lease)
+	if err != nil {
+		log("get lease %s: %s", le.config.Lease, err.Error())
+		return false
+	}
+	if lease == nil {
+		lease = &apiObject.Lease{Base: apiObject.Base{ApiVersion: "v1", Kind: "Lease"}, Spec: spec}
+		lease.Metadata.Name = le.config.Lease
+		if err = le.client.create(lease); err != nil {
+			log("create lease %s: %s", le.config.Lease, err.Error())
+			return false
+		}
+		le.observe(spec, now)
+		return true
+	}
+
+	le.observe(lease.Spec, now)
+	if held := lease.Spec.HolderIdentity; held != "" && held != le.config.Identity && !le.expired(now) {
+		le.reportLeader(held)
+		return false
+	}
+	if lease.Spec.HolderIdentity == le.config.Identity {
+		spec.AcquireTime = lease.Spec.AcquireTime
+		spec.LeaseTransitions = lease.Spec.LeaseTransitions
+	} else {
+		spec.LeaseTransitions = lease.Spec.LeaseTransitions + 1
+	}
+	// The update fails if another replica updated the lease since it was read
+	lease.Spec = spec
+	if err = le.client.update(lease); err != nil {
+		log("update lease %s: %s", le.config.Lease, err.Error())
+		return false
+	}
+	le.observe(spec, now)
+	return true
+}
+
+func (le *LeaderElector) observe(spec apiObject.LeaseSpec, now time.Time) {
+	le.lock.Lock()
+	defer le.lock.Unlock()
+	if !reflect.DeepEqual(spec, le.observedSpec) {
+		le.observedSpec, le.observedTime = spec, now
+	}
+}
+
+func (le *LeaderElector) expired(now time.Time) bool {
+	le.lock.Lock()
+	defer le.lock.Unlock()
+	duration := time.Duration(le.observedSpec.LeaseDurationSeconds) * time.Second
+	return le.observedTime.Add(duration).Before(now)
+}
+
+func (le *LeaderElector) reportLeader(identity string) {
+	if identity == le.reportedLeader {
+		return
+	}
+	le.reportedLeader = identity
+	log("%s leads lease %s", identity, le.config.Lease)
+	if le.callbacks.OnNewLeader != nil {
+		le.callbacks.OnNewLeader(identity)
+	}
+}
+
+// IsLeader tells whether the replica held the lease when it was last read
+func (le *LeaderElector) IsLeader() bool {
+	return le.Leader() == le.config.Identity
+}
+
+// Leader is the replica that held the lease when it was last read
+func (le *LeaderElector) Leader() string {
+	le.lock.Lock()
+	defer le.lock.Unlock()
+	return le.observedSpec.HolderIdentity
+}
+
+// Run runs the component by run if it does not elect a leader. Otherwise it
+// waits until the replica leads to run it, and exits the process once the
+// replica stops leading, since the component cannot stop working halfway.
+func Run(config Config, run func()) {
+	if !config.Enabled {
+		run()
+		return
+	}
+	le, err := NewLeaderElector(config, Callbacks{
+		OnStartedLeading: run,
+		OnStoppedLeading: func() {
+			log("%s stopped leading, exit", config.Identity)
+			os.Exit(1)
+		},
+	})
+	if err != nil {
+		log(err.Error())
+		os.Exit(-1)
+	}
+	le.Run()
+}
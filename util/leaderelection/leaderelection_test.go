@@ -0,0 +1,91 @@
+package leaderelection
+
+import (
+	"github.com/stretchr/testify/assert"
+	"minik8s/apiObject"
+	"minik8s/util/httputil"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fakeClient keeps the lease in memory, with a resource version like the
+// api-server.
+type fakeClient struct {
+	lease *apiObject.Lease
+}
+
+func (c *fakeClient) get(name string) (*apiObject.Lease, error) {
+	if c.lease == nil {
+		return nil, nil
+	}
+	lease := *c.lease
+	return &lease, nil
+}
+
+func (c *fakeClient) create(lease *apiObject.Lease) error {
+	if c.lease != nil {
+		return httputil.NewAlreadyExists("Lease", "", lease.Name())
+	}
+	stored := *lease
+	stored.Metadata.ResourceVersion = "1"
+	c.lease = &stored
+	return nil
+}
+
+func (c *fakeClient) update(lease *apiObject.Lease) error {
+	if lease.Metadata.ResourceVersion != c.lease.Metadata.ResourceVersion {
+		return httputil.NewConflict("the lease has been modified")
+	}
+	stored := *lease
+	version, _ := strconv.Atoi(lease.Metadata.ResourceVersion)
+	stored.Metadata.ResourceVersion = strconv.Itoa(version + 1)
+	c.lease = &stored
+	return nil
+}
+
+func newTestElector(identity string, client leaseClient, now *time.Time) *LeaderElector {
+	config := DefaultConfig("test")
+	config.Identity = identity
+	le, _ := NewLeaderElector(config, Callbacks{OnStartedLeading: func() {}, OnStoppedLeading: func() {}})
+	le.client = client
+	le.now = func() time.Time { return *now }
+	return le
+}
+
+func TestLeaderElection(t *testing.T) {
+	now := time.Now()
+	client := &fakeClient{}
+	a := newTestElector("a", client, &now)
+	b := newTestElector("b", client, &now)
+
+	// a creates the lease, b stands by
+	assert.True(t, a.tryAcquireOrRenew())
+	assert.False(t, b.tryAcquireOrRenew())
+	assert.True(t, a.IsLeader())
+	assert.Equal(t, "a", b.Leader())
+
+	// a renews the lease before it expires
+	now = now.Add(DefaultRetryPeriod)
+	assert.True(t, a.tryAcquireOrRenew())
+	now = now.Add(DefaultLeaseDuration - time.Second)
+	assert.False(t, b.tryAcquireOrRenew())
+
+	// b takes the lease over once it has not seen it renewed for the lease
+	// duration
+	now = now.Add(DefaultLeaseDuration + time.Second)
+	assert.True(t, b.tryAcquireOrRenew())
+	assert.Equal(t, "b", client.lease.Spec.HolderIdentity)
+	assert.Equal(t, 1, client.lease.Spec.LeaseTransitions)
+
+	// a sees that it lost the lease
+	assert.False(t, a.tryAcquireOrRenew())
+	assert.False(t, a.IsLeader())
+}
+
+func TestConfigValidate(t *testing.T) {
+	config := DefaultConfig("test")
+	assert.NoError(t, config.validate())
+	config.RenewDeadline = config.LeaseDuration
+	assert.Error(t, config.validate())
+}
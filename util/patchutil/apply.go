@@ -0,0 +1,104 @@
+package patchutil
+
+import (
+	"encoding/json"
+)
+
+// OmitEmpty drops the fields of the objects of a JSON document that hold
+// null, false, 0, "" or an empty object or array, as omitempty would. What is
+// left of an object marshalled from a Go value is what was set in it.
+func OmitEmpty(raw []byte) ([]byte, error) {
+	doc, err := decode(raw)
+	if err != nil {
+		return nil, err
+	}
+	doc, _ = omitEmpty(doc)
+	return json.Marshal(doc)
+}
+
+// omitEmpty returns value without its empty fields, and tells whether it is
+// empty itself. The elements of an array are kept, empty or not.
+func omitEmpty(value interface{}) (interface{}, bool) {
+	switch value := value.(type) {
+	case nil:
+		return nil, true
+	case bool:
+		return value, !value
+	case string:
+		return value, value == ""
+	case json.Number:
+		f, err := value.Float64()
+		return value, err == nil && f == 0
+	case []interface{}:
+		for i := range value {
+			value[i], _ = omitEmpty(value[i])
+		}
+		return value, len(value) == 0
+	case map[string]interface{}:
+		for key := range value {
+			var empty bool
+			if value[key], empty = omitEmpty(value[key]); empty {
+				delete(value, key)
+			}
+		}
+		return value, len(value) == 0
+	}
+	return value, false
+}
+
+// ThreeWayMergePatch returns the JSON merge patch that brings current in line
+// with modified, the configuration that replaces original. The fields of
+// original missing from modified are removed, those of modified are set, and
+// the fields of current that neither mentions, e.g. those set by the server,
+// are left alone. original may be empty, e.g. for an object that was never
+// applied before. Arrays are replaced as a whole.
+func ThreeWayMergePatch(original, modified, current []byte) ([]byte, error) {
+	var originalDoc interface{}
+	if len(original) > 0 {
+		var err error
+		if originalDoc, err = decode(original); err != nil {
+			return nil, err
+		}
+	}
+	modifiedDoc, err := decode(modified)
+	if err != nil {
+		return nil, err
+	}
+	currentDoc, err := decode(current)
+	if err != nil {
+		return nil, err
+	}
+
+	modifiedFields, ok := modifiedDoc.(map[string]interface{})
+	if !ok {
+		return json.Marshal(modifiedDoc)
+	}
+	originalFields, _ := originalDoc.(map[string]interface{})
+	currentFields, _ := currentDoc.(map[string]interface{})
+	return json.Marshal(threeWay(originalFields, modifiedFields, currentFields))
+}
+
+func threeWay(original, modified, current map[string]interface{}) map[string]interface{} {
+	patch := map[string]interface{}{}
+	for key := range original {
+		if _, kept := modified[key]; !kept {
+			if _, exists := current[key]; exists {
+				patch[key] = nil
+			}
+		}
+	}
+	for key, value := range modified {
+		currentValue, exists := current[key]
+		modifiedFields, isObject := value.(map[string]interface{})
+		currentFields, wasObject := currentValue.(map[string]interface{})
+		if isObject && wasObject {
+			originalFields, _ := original[key].(map[string]interface{})
+			if fields := threeWay(originalFields, modifiedFields, currentFields); len(fields) > 0 {
+				patch[key] = fields
+			}
+		} else if !exists || !equal(value, currentValue) {
+			patch[key] = value
+		}
+	}
+	return patch
+}
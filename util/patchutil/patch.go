@@ -0,0 +1,293 @@
+package patchutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// decode keeps the numbers as they are written, so that patching an object
+// does not turn its int64 fields into floats.
+func decode(raw []byte) (value interface{}, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	err = decoder.Decode(&value)
+	return
+}
+
+// MergePatch applies a JSON merge patch (RFC 7386) to the JSON document
+// original: the objects of the patch are merged recursively, null removes a
+// field and any other value replaces it.
+func MergePatch(original, patch []byte) ([]byte, error) {
+	doc, err := decode(original)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decode(patch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %s", err.Error())
+	}
+	return json.Marshal(mergePatch(doc, p))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	patchFields, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetFields, ok := target.(map[string]interface{})
+	if !ok {
+		targetFields = map[string]interface{}{}
+	}
+	for key, value := range patchFields {
+		if value == nil {
+			delete(targetFields, key)
+		} else {
+			targetFields[key] = mergePatch(targetFields[key], value)
+		}
+	}
+	return targetFields
+}
+
+// Operation is one step of a JSON patch
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch applies a JSON patch (RFC 6902), i.e. a list of add, remove,
+// replace, move, copy and test operations on JSON pointers, to the JSON
+// document original. Either all operations succeed or the patch fails.
+func JSONPatch(original, patch []byte) ([]byte, error) {
+	doc, err := decode(original)
+	if err != nil {
+		return nil, err
+	}
+	var ops []Operation
+	if err = json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("invalid json patch: %s", err.Error())
+	}
+	for i, op := range ops {
+		if doc, err = apply(doc, op); err != nil {
+			return nil, fmt.Errorf("json patch operation %d (%s %s): %s", i, op.Op, op.Path, err.Error())
+		}
+	}
+	return json.Marshal(doc)
+}
+
+func apply(doc interface{}, op Operation) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch op.Op {
+	case "add", "replace", "test":
+		if len(op.Value) == 0 {
+			return nil, fmt.Errorf("missing value")
+		}
+		value, err := decode(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "add" {
+			return add(doc, path, value)
+		} else if op.Op == "replace" {
+			return replace(doc, path, value)
+		}
+		return doc, test(doc, path, value)
+	case "remove":
+		doc, _, err = remove(doc, path)
+		return doc, err
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if op.Op == "move" {
+			if strings.HasPrefix(op.Path+"/", op.From+"/") && op.Path != op.From {
+				return nil, fmt.Errorf("cannot move %s into itself", op.From)
+			}
+			if doc, value, err = remove(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			if value, err = get(doc, from); err != nil {
+				return nil, err
+			}
+			// The copy must not share its maps and slices with the original
+			raw, _ := json.Marshal(value)
+			value, _ = decode(raw)
+		}
+		return add(doc, path, value)
+	}
+	return nil, fmt.Errorf("unknown operation %q", op.Op)
+}
+
+// parsePointer splits a JSON pointer (RFC 6901) into its unescaped tokens,
+// the empty pointer being the whole document.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid path %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+func get(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		var err error
+		if doc, err = child(doc, token); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+func child(node interface{}, token string) (interface{}, error) {
+	switch node := node.(type) {
+	case map[string]interface{}:
+		value, ok := node[token]
+		if !ok {
+			return nil, fmt.Errorf("no such field %s", token)
+		}
+		return value, nil
+	case []interface{}:
+		i, err := index(node, token, false)
+		if err != nil {
+			return nil, err
+		}
+		return node[i], nil
+	}
+	return nil, fmt.Errorf("cannot get %s of a scalar", token)
+}
+
+// index parses the index token of array, which may be its length, or "-",
+// only for an add.
+func index(array []interface{}, token string, adding bool) (int, error) {
+	if token == "-" && adding {
+		return len(array), nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > len(array) || (i == len(array) && !adding) {
+		return 0, fmt.Errorf("invalid index %s", token)
+	}
+	return i, nil
+}
+
+// update replaces the parent of the last token of path by what fn makes of
+// it, and returns the updated document. Slices are values, so every parent up
+// to the root has to be set again.
+func update(doc interface{}, path []string, fn func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+	node, err := child(doc, path[0])
+	if err != nil {
+		return nil, err
+	}
+	if node, err = update(node, path[1:], fn); err != nil {
+		return nil, err
+	}
+	switch doc := doc.(type) {
+	case map[string]interface{}:
+		doc[path[0]] = node
+	case []interface{}:
+		i, _ := strconv.Atoi(path[0])
+		doc[i] = node
+	}
+	return doc, nil
+}
+
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return update(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch parent := parent.(type) {
+		case map[string]interface{}:
+			parent[token] = value
+			return parent, nil
+		case []interface{}:
+			i, err := index(parent, token, true)
+			if err != nil {
+				return nil, err
+			}
+			parent = append(parent, nil)
+			copy(parent[i+1:], parent[i:])
+			parent[i] = value
+			return parent, nil
+		}
+		return nil, fmt.Errorf("cannot add %s to a scalar", token)
+	})
+}
+
+func remove(doc interface{}, path []string) (newDoc, removed interface{}, err error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the whole document")
+	}
+	newDoc, err = update(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		if removed, err = child(parent, token); err != nil {
+			return nil, err
+		}
+		switch parent := parent.(type) {
+		case map[string]interface{}:
+			delete(parent, token)
+			return parent, nil
+		case []interface{}:
+			i, _ := strconv.Atoi(token)
+			return append(parent[:i], parent[i+1:]...), nil
+		}
+		return parent, nil
+	})
+	return
+}
+
+func replace(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return update(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		if _, err := child(parent, token); err != nil {
+			return nil, err
+		}
+		switch parent := parent.(type) {
+		case map[string]interface{}:
+			parent[token] = value
+		case []interface{}:
+			i, _ := strconv.Atoi(token)
+			parent[i] = value
+		}
+		return parent, nil
+	})
+}
+
+func test(doc interface{}, path []string, value interface{}) error {
+	actual, err := get(doc, path)
+	if err != nil {
+		return err
+	}
+	if !equal(actual, value) {
+		actualJson, _ := json.Marshal(actual)
+		return fmt.Errorf("test failed, the value is %s", actualJson)
+	}
+	return nil
+}
+
+// equal compares two decoded JSON values, marshalling sorts the keys of the
+// objects.
+func equal(a, b interface{}) bool {
+	aJson, _ := json.Marshal(a)
+	bJson, _ := json.Marshal(b)
+	return bytes.Equal(aJson, bJson)
+}
@@ -0,0 +1,72 @@
+package patchutil
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const original = `{"Metadata":{"Name":"rs","Labels":{"app":"foo","tier":"db"}},"Spec":{"Replicas":3,"Ports":[80,443]}}`
+
+func TestMergePatch(t *testing.T) {
+	patched, err := MergePatch([]byte(original), []byte(`{"Metadata":{"Labels":{"app":"bar","tier":null}},"Spec":{"Ports":[8080]}}`))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"Metadata":{"Name":"rs","Labels":{"app":"bar"}},"Spec":{"Replicas":3,"Ports":[8080]}}`, string(patched))
+
+	_, err = MergePatch([]byte(original), []byte(`{"Metadata":`))
+	assert.NotNil(t, err)
+}
+
+func TestJSONPatch(t *testing.T) {
+	patched, err := JSONPatch([]byte(original), []byte(`[
+		{"op": "test", "path": "/Spec/Replicas", "value": 3},
+		{"op": "replace", "path": "/Spec/Replicas", "value": 5},
+		{"op": "add", "path": "/Metadata/Labels/app.kubernetes.io~1name", "value": "web"},
+		{"op": "remove", "path": "/Metadata/Labels/tier"},
+		{"op": "add", "path": "/Spec/Ports/1", "value": 8080},
+		{"op": "add", "path": "/Spec/Ports/-", "value": 9090},
+		{"op": "copy", "from": "/Metadata/Name", "path": "/Metadata/Labels/name"},
+		{"op": "move", "from": "/Spec/Ports/0", "path": "/Spec/Port"}
+	]`))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"Metadata":{"Name":"rs","Labels":{"app":"foo","app.kubernetes.io/name":"web","name":"rs"}},"Spec":{"Replicas":5,"Ports":[8080,443,9090],"Port":80}}`, string(patched))
+
+	for _, patch := range []string{
+		`[{"op": "test", "path": "/Spec/Replicas", "value": 4}]`,
+		`[{"op": "replace", "path": "/Spec/Missing", "value": 1}]`,
+		`[{"op": "remove", "path": "/Spec/Ports/2"}]`,
+		`[{"op": "add", "path": "Spec", "value": 1}]`,
+		`[{"op": "add", "path": "/Spec/Replicas/x", "value": 1}]`,
+		`[{"op": "move", "from": "/Spec", "path": "/Spec/Inner"}]`,
+		`[{"op": "frobnicate", "path": "/Spec"}]`,
+		`{"op": "add"}`,
+	} {
+		_, err = JSONPatch([]byte(original), []byte(patch))
+		assert.NotNil(t, err, patch)
+	}
+}
+
+func TestOmitEmpty(t *testing.T) {
+	omitted, err := OmitEmpty([]byte(`{"Name":"rs","UID":"","Replicas":0,"Paused":false,"Labels":null,"Spec":{"Selector":{}},"Ports":[{"Port":80,"Name":""}]}`))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"Name":"rs","Ports":[{"Port":80}]}`, string(omitted))
+}
+
+func TestThreeWayMergePatch(t *testing.T) {
+	lastApplied := `{"Metadata":{"Name":"rs","Labels":{"app":"foo","tier":"db"}},"Spec":{"Replicas":3,"Ports":[80,443]}}`
+	current := `{"Metadata":{"Name":"rs","UID":"1234","Labels":{"app":"foo","tier":"db","owner":"hpa"}},"Spec":{"Replicas":5,"Ports":[80,443]}}`
+	modified := `{"Metadata":{"Name":"rs","Labels":{"app":"bar"}},"Spec":{"Replicas":3,"Ports":[8080]}}`
+
+	patch, err := ThreeWayMergePatch([]byte(lastApplied), []byte(modified), []byte(current))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"Metadata":{"Labels":{"app":"bar","tier":null}},"Spec":{"Replicas":3,"Ports":[8080]}}`, string(patch))
+
+	// Never applied, nothing is removed
+	patch, err = ThreeWayMergePatch(nil, []byte(modified), []byte(current))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"Metadata":{"Labels":{"app":"bar"}},"Spec":{"Replicas":3,"Ports":[8080]}}`, string(patch))
+
+	// Unchanged
+	patch, err = ThreeWayMergePatch([]byte(modified), []byte(modified), []byte(modified))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{}`, string(patch))
+}
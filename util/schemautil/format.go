@@ -0,0 +1,54 @@
+package schemautil
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// The formats a string, or a number for FormatPort, may be required to have
+const (
+	// FormatDNS1123Label is a name that can be a label of a host name, e.g.
+	// the name of a container, a service or a namespace.
+	FormatDNS1123Label = "dns1123-label"
+	// FormatDNS1123Subdomain is a name that can be a host name, e.g. the name
+	// of a pod.
+	FormatDNS1123Subdomain = "dns1123-subdomain"
+	// FormatPort is a port number, or the same as a string
+	FormatPort = "port"
+)
+
+const (
+	dns1123LabelMaxLength     = 63
+	dns1123SubdomainMaxLength = 253
+)
+
+var (
+	dns1123Label     = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	dns1123Subdomain = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// checkFormat tells why value is not of format. An unknown format accepts
+// anything, as JSON schema does.
+func checkFormat(format string, value interface{}) error {
+	switch format {
+	case FormatDNS1123Label:
+		if s, ok := value.(string); ok && (len(s) > dns1123LabelMaxLength || !dns1123Label.MatchString(s)) {
+			return fmt.Errorf("must be at most %d lower case alphanumeric characters or '-', starting and ending with an alphanumeric character", dns1123LabelMaxLength)
+		}
+	case FormatDNS1123Subdomain:
+		if s, ok := value.(string); ok && (len(s) > dns1123SubdomainMaxLength || !dns1123Subdomain.MatchString(s)) {
+			return fmt.Errorf("must be at most %d lower case alphanumeric characters, '-' or '.', starting and ending with an alphanumeric character", dns1123SubdomainMaxLength)
+		}
+	case FormatPort:
+		port, ok := value.(float64)
+		if s, isString := value.(string); isString {
+			n, err := strconv.Atoi(s)
+			port, ok = float64(n), err == nil
+		}
+		if !ok || port < 1 || port > 65535 || port != float64(int(port)) {
+			return fmt.Errorf("must be a port number between 1 and 65535")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package schemautil
+
+import (
+	"encoding/json"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Naming is how the fields of a struct are named once encoded
+type Naming int
+
+const (
+	// JSON names the fields as encoding/json does: by their json tag or else
+	// by their Go name, the embedded structs being inlined.
+	JSON Naming = iota
+	// YAML names the fields as yaml.v3 does: by their yaml tag or else by
+	// their Go name in lower case, only the ,inline structs being inlined.
+	YAML
+)
+
+type cacheKey struct {
+	t      reflect.Type
+	naming Naming
+}
+
+var cache sync.Map
+
+// Generate returns the schema of the values of type t, encoded with naming.
+// A struct allows no fields but its own, which are constrained by their
+// schema tag, a comma separated list of
+//
+//	required         the field must be set to a value that is not empty
+//	enum=A|B|C       the field must be one of the values
+//	minimum=N        the field must be at least N, maximum=N at most N
+//	format=F         the field must be of a format of checkFormat
+//
+// e.g. `schema:"required,format=port"`. Since an empty field is the same as
+// a missing one to the api objects, what is validated against the schema
+// should leave out the empty fields. A type that encodes itself, like
+// time.Time, may be anything. The schema is shared, it must not be changed.
+func Generate(t reflect.Type, naming Naming) Schema {
+	key := cacheKey{t: t, naming: naming}
+	if schema, ok := cache.Load(key); ok {
+		return schema.(Schema)
+	}
+	g := &generator{naming: naming, visiting: make(map[reflect.Type]bool)}
+	schema, _ := cache.LoadOrStore(key, g.schemaOf(t))
+	return schema.(Schema)
+}
+
+type generator struct {
+	naming Naming
+	// visiting are the structs being generated, a recursive type may be
+	// anything once it recurs.
+	visiting map[reflect.Type]bool
+}
+
+var (
+	jsonMarshaler   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	yamlMarshaler   = reflect.TypeOf((*yaml.Marshaler)(nil)).Elem()
+	yamlUnmarshaler = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+)
+
+func encodesItself(t reflect.Type) bool {
+	for _, tp := range []reflect.Type{t, reflect.PtrTo(t)} {
+		if tp.Implements(jsonMarshaler) || tp.Implements(jsonUnmarshaler) ||
+			tp.Implements(yamlMarshaler) || tp.Implements(yamlUnmarshaler) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *generator) schemaOf(t reflect.Type) Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if encodesItself(t) {
+		return Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// bytes are encoded as a string
+			return Schema{"type": "string"}
+		}
+		return Schema{"type": "array", "items": g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if g.visiting[t] {
+			return Schema{}
+		}
+		g.visiting[t] = true
+		defer delete(g.visiting, t)
+
+		properties := Schema{}
+		var required []interface{}
+		g.addFields(t, properties, &required)
+		schema := Schema{"type": "object", "properties": properties, "additionalProperties": false}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	}
+	// an interface{} may be anything
+	return Schema{}
+}
+
+// addFields adds the fields of the struct t to properties, and those that
+// are required to required.
+func (g *generator) addFields(t reflect.Type, properties Schema, required *[]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, inline, ok := g.fieldName(field)
+		if !ok {
+			continue
+		}
+		if inline {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			g.addFields(embedded, properties, required)
+			continue
+		}
+		property := g.schemaOf(field.Type)
+		if constrain(property, field.Tag.Get("schema"), field.Type) {
+			*required = append(*required, name)
+		}
+		properties[name] = property
+	}
+}
+
+// fieldName returns the name of field once encoded, or whether its fields
+// are inlined in those of the struct instead. ok is false for a field that
+// is not encoded.
+func (g *generator) fieldName(field reflect.StructField) (name string, inline, ok bool) {
+	if field.PkgPath != "" && !field.Anonymous {
+		return "", false, false
+	}
+	isStruct := field.Type.Kind() == reflect.Struct ||
+		field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct
+
+	if g.naming == JSON {
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			return "", false, false
+		}
+		name = strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" && isStruct {
+			return "", true, true
+		}
+		if field.PkgPath != "" {
+			return "", false, false
+		}
+		if name == "" {
+			name = field.Name
+		}
+		return name, false, true
+	}
+
+	tag := field.Tag.Get("yaml")
+	if tag == "-" || field.PkgPath != "" {
+		return "", false, false
+	}
+	parts := strings.Split(tag, ",")
+	for _, flag := range parts[1:] {
+		if flag == "inline" && isStruct {
+			return "", true, true
+		}
+	}
+	name = parts[0]
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, false, true
+}
+
+// constrain adds the constraints of a schema tag to property, the schema of
+// a field of type t, and tells whether the field is required.
+func constrain(property Schema, tag string, t reflect.Type) (required bool) {
+	if tag == "" {
+		return false
+	}
+	for _, constraint := range strings.Split(tag, ",") {
+		keyword := constraint
+		value := ""
+		if i := strings.Index(constraint, "="); i >= 0 {
+			keyword, value = constraint[:i], constraint[i+1:]
+		}
+		switch keyword {
+		case "required":
+			required = true
+		case "enum":
+			var enum []interface{}
+			for _, option := range strings.Split(value, "|") {
+				enum = append(enum, parseValue(option, t))
+			}
+			property["enum"] = enum
+		case "minimum", "maximum":
+			if n, err := strconv.ParseFloat(value, 64); err == nil {
+				property[keyword] = n
+			}
+		case "format":
+			property["format"] = value
+		}
+	}
+	return required
+}
+
+// parseValue parses a value of an enum as a number for a field of a number
+// type, as encoding/json decodes the field.
+func parseValue(value string, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	}
+	return value
+}
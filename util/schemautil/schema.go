@@ -0,0 +1,252 @@
+package schemautil
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Schema is a JSON schema, as decoded from JSON or YAML. Only the keywords
+// below are supported: type, properties, required, additionalProperties,
+// items, enum, minimum, maximum, minLength, maxLength, pattern and format,
+// the formats being those of checkFormat.
+type Schema = map[string]interface{}
+
+// FieldError is a violation of the schema at a field, e.g. spec.replicas
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// FieldErrors collects all violations of an object
+type FieldErrors []*FieldError
+
+func (errs FieldErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Validate checks value, which must consist of what encoding/json decodes
+// into an interface{}, against schema. It returns all violations at once,
+// or nil if there are none.
+func Validate(schema Schema, value interface{}) FieldErrors {
+	v := &validator{}
+	v.validate(schema, value, "")
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+type validator struct {
+	errs FieldErrors
+}
+
+func (v *validator) addError(field, format string, args ...interface{}) {
+	v.errs = append(v.errs, &FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func join(field, key string) string {
+	if field == "" {
+		return key
+	}
+	return field + "." + key
+}
+
+func (v *validator) validate(schema Schema, value interface{}, field string) {
+	if schema == nil {
+		return
+	}
+	if tp, ok := schema["type"].(string); ok && !hasType(value, tp) {
+		v.addError(field, "must be of type %s", tp)
+		return
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && !contains(enum, value) {
+		v.addError(field, "must be one of %v", enum)
+	}
+	if format, ok := schema["format"].(string); ok {
+		if err := checkFormat(format, value); err != nil {
+			v.addError(field, err.Error())
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(schema, value, field)
+	case []interface{}:
+		if items, ok := schema["items"].(Schema); ok {
+			for i, item := range value {
+				v.validate(items, item, fmt.Sprintf("%s[%d]", field, i))
+			}
+		}
+	case string:
+		if min, ok := number(schema["minLength"]); ok && float64(len(value)) < min {
+			v.addError(field, "must be at least %v characters long", min)
+		}
+		if max, ok := number(schema["maxLength"]); ok && float64(len(value)) > max {
+			v.addError(field, "must be at most %v characters long", max)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if matched, err := regexp.MatchString(pattern, value); err != nil || !matched {
+				v.addError(field, "must match %s", pattern)
+			}
+		}
+	case float64:
+		if min, ok := number(schema["minimum"]); ok && value < min {
+			v.addError(field, "must be at least %v", min)
+		}
+		if max, ok := number(schema["maximum"]); ok && value > max {
+			v.addError(field, "must be at most %v", max)
+		}
+	}
+}
+
+func (v *validator) validateObject(schema Schema, value map[string]interface{}, field string) {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, key := range required {
+			if key, ok := key.(string); ok {
+				if _, exists := value[key]; !exists {
+					v.addError(join(field, key), "is required")
+				}
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(Schema)
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if property, ok := properties[key].(Schema); ok {
+			v.validate(property, value[key], join(field, key))
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.addError(join(field, key), "unknown field")
+			}
+		case Schema:
+			v.validate(additional, value[key], join(field, key))
+		}
+	}
+}
+
+func hasType(value interface{}, tp string) bool {
+	switch tp {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+func number(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func contains(enum []interface{}, value interface{}) bool {
+	for _, candidate := range enum {
+		if n, ok := number(candidate); ok {
+			candidate = n
+		}
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnknownFields returns the fields of value that schema does not allow, all
+// of them at once, or nil if there are none. Unlike Validate, a field matches
+// a property of another case, since encoding/json decodes it all the same.
+func UnknownFields(schema Schema, value interface{}) FieldErrors {
+	v := &validator{}
+	v.unknownFields(schema, value, "")
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func (v *validator) unknownFields(schema Schema, value interface{}, field string) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(Schema)
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if property, ok := lookupProperty(properties, key); ok {
+				v.unknownFields(property, value[key], join(field, key))
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					v.addError(join(field, key), "unknown field")
+				}
+			case Schema:
+				v.unknownFields(additional, value[key], join(field, key))
+			}
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(Schema); ok {
+			for i, item := range value {
+				v.unknownFields(items, item, fmt.Sprintf("%s[%d]", field, i))
+			}
+		}
+	}
+}
+
+// lookupProperty finds the property key, or else one of another case
+func lookupProperty(properties Schema, key string) (Schema, bool) {
+	if property, ok := properties[key].(Schema); ok {
+		return property, true
+	}
+	for name, property := range properties {
+		if property, ok := property.(Schema); ok && strings.EqualFold(name, key) {
+			return property, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,108 @@
+package schemautil
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const flagSchema = `{
+	"type": "object",
+	"required": ["spec"],
+	"properties": {
+		"spec": {
+			"type": "object",
+			"required": ["enabled"],
+			"additionalProperties": false,
+			"properties": {
+				"enabled": {"type": "boolean"},
+				"percent": {"type": "integer", "minimum": 0, "maximum": 100},
+				"stage": {"type": "string", "enum": ["dev", "prod"]},
+				"owners": {"type": "array", "items": {"type": "string", "pattern": "^[a-z]+$"}}
+			}
+		}
+	}
+}`
+
+func parse(t *testing.T, raw string) map[string]interface{} {
+	value := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal([]byte(raw), &value))
+	return value
+}
+
+func TestValidate(t *testing.T) {
+	schema := parse(t, flagSchema)
+
+	valid := parse(t, `{"spec": {"enabled": true, "percent": 20, "stage": "dev", "owners": ["alice"]}}`)
+	assert.Nil(t, Validate(schema, valid))
+
+	invalid := parse(t, `{"spec": {"percent": 120.5, "stage": "qa", "owners": ["Bob"], "color": "red"}}`)
+	errs := Validate(schema, invalid)
+	var fields []string
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	assert.ElementsMatch(t, []string{
+		"spec.enabled",
+		"spec.percent",
+		"spec.stage",
+		"spec.owners[0]",
+		"spec.color",
+	}, fields)
+
+	assert.Equal(t, "spec: is required", Validate(schema, parse(t, `{}`)).Error())
+}
+
+type port struct {
+	Number   int    `yaml:"number" schema:"required,format=port"`
+	Protocol string `yaml:"protocol,omitempty" schema:"enum=TCP|UDP"`
+}
+
+type server struct {
+	Name  string            `yaml:"name" schema:"format=dns1123-label"`
+	Ports []port            `yaml:"ports"`
+	Tags  map[string]string `yaml:"tags"`
+	Next  *server           `yaml:"next"`
+}
+
+func TestGenerate(t *testing.T) {
+	schema := Generate(reflect.TypeOf(server{}), YAML)
+
+	valid := parse(t, `{"name": "web", "ports": [{"number": 80, "protocol": "TCP"}], "tags": {"tier": "front"}}`)
+	assert.Nil(t, Validate(schema, valid))
+
+	invalid := parse(t, `{"name": "Web", "ports": [{"protocol": "ICMP"}, {"number": 70000}], "next": {"name": "db", "size": 1}}`)
+	var fields []string
+	for _, err := range Validate(schema, invalid) {
+		fields = append(fields, err.Field)
+	}
+	assert.ElementsMatch(t, []string{
+		"name",
+		"ports[0].number",
+		"ports[0].protocol",
+		"ports[1].number",
+	}, fields, "a recursive type may be anything once it recurs")
+}
+
+func TestUnknownFields(t *testing.T) {
+	schema := Generate(reflect.TypeOf(server{}), JSON)
+
+	assert.Nil(t, UnknownFields(schema, parse(t, `{"name": "web", "ports": [{"Number": 80}], "tags": {"any": "thing"}}`)))
+
+	errs := UnknownFields(schema, parse(t, `{"Name": "web", "Prots": [], "Ports": [{"Number": 80, "Proto": "TCP"}]}`))
+	assert.Equal(t, "Ports[0].Proto: unknown field; Prots: unknown field", errs.Error())
+}
+
+func TestCheckFormat(t *testing.T) {
+	assert.Nil(t, checkFormat(FormatDNS1123Label, "nginx-1"))
+	assert.NotNil(t, checkFormat(FormatDNS1123Label, "nginx.default"))
+	assert.NotNil(t, checkFormat(FormatDNS1123Label, strings.Repeat("a", 64)))
+	assert.Nil(t, checkFormat(FormatDNS1123Subdomain, "nginx.default"))
+	assert.NotNil(t, checkFormat(FormatDNS1123Subdomain, "-nginx"))
+	assert.Nil(t, checkFormat(FormatPort, "8080"))
+	assert.Nil(t, checkFormat(FormatPort, float64(443)))
+	assert.NotNil(t, checkFormat(FormatPort, "http"))
+	assert.NotNil(t, checkFormat(FormatPort, float64(0)))
+}
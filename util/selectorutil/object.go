@@ -0,0 +1,66 @@
+package selectorutil
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"minik8s/apiObject"
+	"reflect"
+)
+
+// FieldSet is implemented by the objects that choose which of their fields a
+// field selector sees, e.g. the statuses that are not shaped like an api
+// object.
+type FieldSet interface {
+	Fields() map[string]string
+}
+
+// LabelsOf returns the labels of an api object, or the Labels field of a
+// status.
+func LabelsOf(obj interface{}) map[string]string {
+	if object, ok := obj.(apiObject.Object); ok {
+		return object.Meta().Labels
+	}
+	value := reflect.Indirect(reflect.ValueOf(obj))
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	if labels := value.FieldByName("Labels"); labels.IsValid() && labels.Type().ConvertibleTo(reflect.TypeOf(map[string]string{})) {
+		return labels.Convert(reflect.TypeOf(map[string]string{})).Interface().(map[string]string)
+	}
+	return nil
+}
+
+// FieldsOf returns the fields a field selector is matched against. Unless obj
+// is a FieldSet, they are its scalar fields by their yaml path, e.g.
+// metadata.name or spec.replicas.
+func FieldsOf(obj interface{}) map[string]string {
+	if fieldSet, ok := obj.(FieldSet); ok {
+		return fieldSet.Fields()
+	}
+	fields := map[string]string{}
+	raw, err := yaml.Marshal(obj)
+	if err != nil {
+		return fields
+	}
+	var tree interface{}
+	if err = yaml.Unmarshal(raw, &tree); err == nil {
+		flatten("", tree, fields)
+	}
+	return fields
+}
+
+func flatten(prefix string, node interface{}, fields map[string]string) {
+	switch node := node.(type) {
+	case map[string]interface{}:
+		for key, child := range node {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flatten(key, child, fields)
+		}
+	case []interface{}, nil:
+		// Only scalars can be selected on
+	default:
+		fields[prefix] = fmt.Sprint(node)
+	}
+}
@@ -0,0 +1,205 @@
+package selectorutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Operator string
+
+const (
+	Equals       Operator = "="
+	NotEquals    Operator = "!="
+	In           Operator = "in"
+	NotIn        Operator = "notin"
+	Exists       Operator = "exists"
+	DoesNotExist Operator = "!"
+)
+
+// Requirement is a single term of a selector, e.g. tier!=db or
+// env in (dev,test)
+type Requirement struct {
+	Key      string
+	Operator Operator
+	Values   []string
+}
+
+// Matches tells whether set satisfies the requirement. != and notin are
+// satisfied by a set without the key, as in kubernetes.
+func (r Requirement) Matches(set map[string]string) bool {
+	value, ok := set[r.Key]
+	switch r.Operator {
+	case Equals:
+		return ok && value == r.Values[0]
+	case NotEquals:
+		return !ok || value != r.Values[0]
+	case In:
+		return ok && contains(r.Values, value)
+	case NotIn:
+		return !ok || !contains(r.Values, value)
+	case Exists:
+		return ok
+	case DoesNotExist:
+		return !ok
+	}
+	return false
+}
+
+func (r Requirement) String() string {
+	switch r.Operator {
+	case Equals, NotEquals:
+		return r.Key + string(r.Operator) + r.Values[0]
+	case In, NotIn:
+		return fmt.Sprintf("%s %s (%s)", r.Key, r.Operator, strings.Join(r.Values, ","))
+	case DoesNotExist:
+		return "!" + r.Key
+	}
+	return r.Key
+}
+
+// Selector is a conjunction of requirements, the empty selector matches
+// everything.
+type Selector []Requirement
+
+func (s Selector) Matches(set map[string]string) bool {
+	for _, requirement := range s {
+		if !requirement.Matches(set) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Selector) Empty() bool {
+	return len(s) == 0
+}
+
+func (s Selector) String() string {
+	terms := make([]string, len(s))
+	for i, requirement := range s {
+		terms[i] = requirement.String()
+	}
+	return strings.Join(terms, ",")
+}
+
+// FromSet builds the selector that requires every key of set to have its
+// value, e.g. from the nodeSelector of a pod.
+func FromSet(set map[string]string) Selector {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	selector := make(Selector, 0, len(keys))
+	for _, key := range keys {
+		selector = append(selector, Requirement{Key: key, Operator: Equals, Values: []string{set[key]}})
+	}
+	return selector
+}
+
+// Parse reads a comma separated list of requirements, each of which is one of
+// key=value, key==value, key!=value, key in (v1,v2), key notin (v1,v2), key
+// (exists) and !key (does not exist).
+func Parse(s string) (Selector, error) {
+	var selector Selector
+	for _, term := range splitTerms(s) {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		requirement, err := parseRequirement(term)
+		if err != nil {
+			return nil, err
+		}
+		selector = append(selector, requirement)
+	}
+	return selector, nil
+}
+
+// splitTerms splits s on the commas that are not inside a set of values
+func splitTerms(s string) (terms []string) {
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(terms, s[start:])
+}
+
+func parseRequirement(term string) (Requirement, error) {
+	if strings.HasPrefix(term, "!") && !strings.Contains(term, "=") {
+		key := strings.TrimSpace(term[1:])
+		if err := validate(key, term); err != nil {
+			return Requirement{}, err
+		}
+		return Requirement{Key: key, Operator: DoesNotExist}, nil
+	}
+
+	if open := strings.Index(term, "("); open != -1 {
+		fields := strings.Fields(term[:open])
+		if len(fields) != 2 || (fields[1] != string(In) && fields[1] != string(NotIn)) || !strings.HasSuffix(term, ")") {
+			return Requirement{}, fmt.Errorf("invalid selector %q", term)
+		}
+		if err := validate(fields[0], term); err != nil {
+			return Requirement{}, err
+		}
+		var values []string
+		for _, value := range strings.Split(term[open+1:len(term)-1], ",") {
+			value = strings.TrimSpace(value)
+			if err := validate(value, term); err != nil {
+				return Requirement{}, err
+			}
+			values = append(values, value)
+		}
+		return Requirement{Key: fields[0], Operator: Operator(fields[1]), Values: values}, nil
+	}
+
+	for _, op := range []struct {
+		token    string
+		operator Operator
+	}{{"!=", NotEquals}, {"==", Equals}, {"=", Equals}} {
+		if i := strings.Index(term, op.token); i != -1 {
+			key := strings.TrimSpace(term[:i])
+			value := strings.TrimSpace(term[i+len(op.token):])
+			if err := validate(key, term); err != nil {
+				return Requirement{}, err
+			}
+			if strings.ContainsAny(value, " =!()") {
+				return Requirement{}, fmt.Errorf("invalid selector %q", term)
+			}
+			return Requirement{Key: key, Operator: op.operator, Values: []string{value}}, nil
+		}
+	}
+
+	if err := validate(term, term); err != nil {
+		return Requirement{}, err
+	}
+	return Requirement{Key: term, Operator: Exists}, nil
+}
+
+// validate checks a key or a value of a set, which must not be empty
+func validate(token, term string) error {
+	if token == "" || strings.ContainsAny(token, " =!(),") {
+		return fmt.Errorf("invalid selector %q", term)
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
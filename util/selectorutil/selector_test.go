@@ -0,0 +1,79 @@
+package selectorutil
+
+import (
+	"github.com/stretchr/testify/assert"
+	"minik8s/apiObject"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	selector, err := Parse("app=foo, tier!=db,env in (dev, test),release notin (canary),gpu,!legacy")
+	assert.Nil(t, err)
+	assert.Equal(t, Selector{
+		{Key: "app", Operator: Equals, Values: []string{"foo"}},
+		{Key: "tier", Operator: NotEquals, Values: []string{"db"}},
+		{Key: "env", Operator: In, Values: []string{"dev", "test"}},
+		{Key: "release", Operator: NotIn, Values: []string{"canary"}},
+		{Key: "gpu", Operator: Exists},
+		{Key: "legacy", Operator: DoesNotExist},
+	}, selector)
+	assert.Equal(t, "app=foo,tier!=db,env in (dev,test),release notin (canary),gpu,!legacy", selector.String())
+
+	selector, err = Parse("status.phase==Running")
+	assert.Nil(t, err)
+	assert.Equal(t, Selector{{Key: "status.phase", Operator: Equals, Values: []string{"Running"}}}, selector)
+
+	selector, err = Parse("")
+	assert.Nil(t, err)
+	assert.True(t, selector.Empty())
+
+	for _, invalid := range []string{"=foo", "app=f o", "env in dev", "env within (dev)", "env in (dev,)", "!", "a b"} {
+		_, err = Parse(invalid)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	labels := map[string]string{"app": "foo", "env": "dev", "gpu": ""}
+	for s, expected := range map[string]bool{
+		"app=foo":                      true,
+		"app=bar":                      false,
+		"tier!=db":                     true,
+		"app!=foo":                     false,
+		"env in (dev,test)":            true,
+		"env in (prod)":                false,
+		"release notin (canary)":       true,
+		"env notin (dev)":              false,
+		"gpu":                          true,
+		"tier":                         false,
+		"!tier":                        true,
+		"!gpu":                         false,
+		"app=foo,env in (dev),!legacy": true,
+		"app=foo,env=prod":             false,
+	} {
+		selector, err := Parse(s)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, selector.Matches(labels), s)
+	}
+}
+
+func TestFieldsOf(t *testing.T) {
+	rs := &apiObject.ReplicaSet{
+		Base: apiObject.Base{
+			Metadata: apiObject.Metadata{
+				Name:      "rs",
+				Namespace: "default",
+				Labels:    apiObject.Labels{"app": "foo"},
+			},
+		},
+		Spec: apiObject.ReplicaSetSpec{Replicas: 3},
+	}
+	fields := FieldsOf(rs)
+	assert.Equal(t, "rs", fields["metadata.name"])
+	assert.Equal(t, "default", fields["metadata.namespace"])
+	assert.Equal(t, "3", fields["spec.replicas"])
+	assert.Equal(t, map[string]string{"app": "foo"}, LabelsOf(rs))
+
+	status := struct{ Labels apiObject.Labels }{Labels: apiObject.Labels{"app": "bar"}}
+	assert.Equal(t, map[string]string{"app": "bar"}, LabelsOf(&status))
+}
@@ -7,6 +7,7 @@ const podStatusTopic = "PodStatus"
 const nodeStatusTopic = "NodeStatus"
 const replicaSetStatusTopic = "ReplicaSetStatus"
 const replicaSetUpdateTopic = "ReplicaSetUpdate"
+const deploymentUpdateTopic = "DeploymentUpdate"
 const serviceUpdateTopic = "ServiceUpdate"
 const endpointUpdateTopic = "EndpointUpdate"
 const gpuJobUpdateTopic = "GpuJobUpdate"
@@ -41,6 +42,10 @@ func ReplicaSetStatusTopic() string {
 	return replicaSetStatusTopic
 }
 
+func DeploymentUpdateTopic() string {
+	return deploymentUpdateTopic
+}
+
 func HPAUpdateTopic() string {
 	return hpaUpdateTopic
 }